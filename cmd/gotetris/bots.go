@@ -0,0 +1,42 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"gotetris/internal/bot"
+	"gotetris/internal/game"
+	"gotetris/internal/tbp"
+)
+
+// tbpPrefix marks a bot spec that names an external TBP engine, e.g.
+// "tbp:./cold-clear --threads 2".
+const tbpPrefix = "tbp:"
+
+// botUsage documents the bot spec for flag help texts.
+var botUsage = fmt.Sprintf("Bot: one of %v, or tbp:<command> for an external TBP engine", bot.Names())
+
+// newBot creates the bot named by spec. External engines are started as
+// child processes; the caller closes them with closeBot.
+func newBot(spec string) (game.Bot, error) {
+	if !strings.HasPrefix(spec, tbpPrefix) {
+		return bot.New(spec)
+	}
+
+	args := strings.Fields(strings.TrimPrefix(spec, tbpPrefix))
+	if len(args) == 0 {
+		return nil, fmt.Errorf("bot %q: missing command", spec)
+	}
+	c, err := tbp.Start(args[0], args[1:]...)
+	if err != nil {
+		return nil, fmt.Errorf("bot %q: %w", spec, err)
+	}
+	return c, nil
+}
+
+// closeBot stops a bot's process, if it has one.
+func closeBot(b game.Bot) {
+	if c, ok := b.(interface{ Close() error }); ok {
+		c.Close()
+	}
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"gotetris/internal/bot"
+	"gotetris/internal/game"
+)
+
+// runExport implements `gotetris export`: a bot game, a replay, a saved game
+// or the setup of a puzzle file, written out for other tools.
+func runExport(args []string) error {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	asFumen := fs.Bool("fumen", false, "Write a fumen string")
+	puzzleFile := fs.String("puzzle", "", "Export this puzzle file's setup instead of a bot game")
+	replayFile := fs.String("replay", "", "Export the game in this replay instead of a bot game")
+	saveFile := fs.String("save", "", "Export this saved game, as far as it got, instead of a bot game")
+	botName := fs.String("bot", bot.DefaultBot, botUsage)
+	seed := fs.Uint64("seed", uint64(time.Now().UnixNano()), "Seed of the bot game")
+	modeName := fs.String("mode", game.Marathon.Name, "Game mode")
+	pieces := fs.Int("pieces", 50, "Pieces the bot places before the game is written out")
+	fs.Parse(args)
+
+	if !*asFumen {
+		return fmt.Errorf("pick a format: --fumen")
+	}
+
+	switch {
+	case *replayFile != "":
+		code, err := replayFumen(*replayFile)
+		if err != nil {
+			return err
+		}
+		fmt.Println(code)
+		return nil
+	case *saveFile != "":
+		code, err := savedFumen(*saveFile)
+		if err != nil {
+			return err
+		}
+		fmt.Println(code)
+		return nil
+	case *puzzleFile != "":
+		f, err := os.Open(*puzzleFile)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		p, err := game.ParsePuzzle(f)
+		if err != nil {
+			return fmt.Errorf("%s: %w", *puzzleFile, err)
+		}
+
+		g := game.NewGame(nil, nil)
+		g.StartPuzzle(p)
+		fmt.Println(g.FumenBoard())
+		return nil
+	}
+
+	mode, err := game.ModeByName(*modeName)
+	if err != nil {
+		return err
+	}
+	b, err := newBot(*botName)
+	if err != nil {
+		return err
+	}
+	defer closeBot(b)
+
+	g := game.NewGame(nil, nil)
+	g.SetMode(mode)
+	g.SetSeed(*seed)
+	g.SetAutoplay(b, 0)
+
+	locked := 0
+	g.Subscribe(func(e game.Event) {
+		if _, ok := e.(game.LockedEvent); ok {
+			locked++
+		}
+	})
+
+	g.StartGame()
+	for g.State != game.GameOver && locked < *pieces {
+		g.Step()
+	}
+	fmt.Println(g.FumenGame())
+	return nil
+}
+
+// replayFumen plays a replay through and writes the game it records.
+func replayFumen(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	r, err := game.ReadReplay(f)
+	f.Close()
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", path, err)
+	}
+	player, err := game.NewReplayPlayer(r)
+	if err != nil {
+		return "", err
+	}
+	for !player.Done() {
+		player.Step()
+	}
+	return player.FumenGame(), nil
+}
+
+// savedFumen writes a saved game up to where it was saved.
+func savedFumen(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	g := game.NewGame(nil, nil)
+	if err := g.Resume(f); err != nil {
+		return "", fmt.Errorf("%s: %w", path, err)
+	}
+	return g.FumenGame(), nil
+}
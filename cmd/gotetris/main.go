@@ -3,32 +3,304 @@ package main
 import (
 	"flag"
 	"log"
+	"os"
+	"strings"
 
 	"gotetris/internal/audio"
+	"gotetris/internal/bot"
 	"gotetris/internal/game"
+	"gotetris/internal/picture"
+	"gotetris/internal/scores"
+	"gotetris/internal/spectate"
+	"gotetris/puzzles"
 
 	"github.com/rivo/tview"
 )
 
 func main() {
+	// Subcommands come first; plain `gotetris` starts the game
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "sim":
+			if err := runSim(os.Args[2:]); err != nil {
+				log.Fatalf("sim: %v", err)
+			}
+			return
+		case "export":
+			if err := runExport(os.Args[2:]); err != nil {
+				log.Fatalf("export: %v", err)
+			}
+			return
+		case "serve-ssh":
+			if err := runServeSSH(os.Args[2:]); err != nil {
+				log.Fatalf("serve-ssh: %v", err)
+			}
+			return
+		case "render":
+			if err := runRender(os.Args[2:]); err != nil {
+				log.Fatalf("render: %v", err)
+			}
+			return
+		case "replay":
+			if err := runReplay(os.Args[2:]); err != nil {
+				log.Fatalf("replay: %v", err)
+			}
+			return
+		case "watch":
+			if err := runWatch(os.Args[2:]); err != nil {
+				log.Fatalf("watch: %v", err)
+			}
+			return
+		}
+	}
+
 	musicPath := flag.String("music", "assets/music.mp3", "Path to MP3/WAV soundtrack")
 	loopMusic := flag.Bool("loop", true, "Loop background music")
 	noMusic := flag.Bool("no-music", false, "Disable music entirely")
+	autoplay := flag.Bool("autoplay", false, "Let the built-in bot play (demo/screensaver)")
+	autoplayPPS := flag.Float64("autoplay-pps", 2, "Pieces per second the bot may place (0 = unlimited)")
+	botName := flag.String("bot", bot.DefaultBot, botUsage+" (used by --autoplay, and by Versus if external)")
+	modeName := flag.String("mode", game.Marathon.Name, "Game mode: marathon, sprint, ultra, practice, master, fading, invisible or versus")
+	fadeAfter := flag.Duration("fade-after", 0, "Fade locked blocks out this long after they lock, in any mode (e.g. 3s)")
+	scoringName := flag.String("scoring", "", "Scoring rules: guideline, nes or tgm (default: the mode's own; other rules are not ranked)")
+	scoreLog := flag.String("score-log", "", "Append a breakdown of every award to this file")
+	invisible := flag.Bool("invisible", false, "Hide locked blocks as soon as they lock, in any mode")
+	puzzleDir := flag.String("puzzles", "", "Directory with extra puzzle files (*.txt) for the puzzle browser")
+	fumenCode := flag.String("fumen", "", "Start on a board shared as a fumen string (free play)")
+	fumenPage := flag.Int("fumen-page", 1, "Page of --fumen to start on")
+	exportFile := flag.String("export-file", "", "Also append fumen exports (F/G keys) to this file")
+	saveFile := flag.String("save", defaultSavePath(), "Where a game in progress is saved on quit (empty = never save)")
+	autosave := flag.Duration("autosave", 0, "Also save every so often while playing, e.g. 30s (0 = only on quit)")
+	spectateListen := flag.String("spectate-listen", "", "Stream the game to gotetris watch viewers on this address, e.g. :7777")
+	spectateName := flag.String("spectate-name", defaultPlayerName(), "Name viewers see the game under")
+	castFile := flag.String("record-cast", "", "Record the terminal to this asciinema cast file while playing")
+	replayFile := flag.String("record-replay", "", "Record each game to this file, for gotetris replay (the last game is kept)")
+	mouse := flag.Bool("mouse", true, "Click through the menus and pause with the mouse (overrides the settings)")
+	clickToDrop := flag.Bool("click-to-drop", false, "Casual controls: click a column to drop the piece there, right click to rotate (overrides the settings)")
+	settingsFile := flag.String("settings", defaultSettingsPath(), "Where the Settings page keeps its settings (empty = defaults every time)")
+	scoreFile := flag.String("scores", defaultScoresPath(), "High score table finished games go to (empty = no leaderboard)")
+	replayDir := flag.String("replays", defaultReplayDir(), "Directory every finished game is kept in for the Replays page (empty = keep none)")
+	screenshotDir := flag.String("screenshots", defaultScreenshotDir(), "Directory the S key saves screenshots to (empty = no screenshots)")
+	paletteName := flag.String("palette", "", "Piece colors: standard, deuteranopia, protanopia or tritanopia (overrides the settings)")
+	patterns := flag.Bool("patterns", false, "Mark each piece with its own glyphs, to tell them apart without color (overrides the settings)")
+	announce := flag.Bool("announce", false, "Describe the game in words on a line under the board, for screen readers (overrides the settings)")
+	announceFile := flag.String("announce-file", "", "Also write every announcement to this file or named pipe, a line each")
+	audioCues := flag.Bool("audio-cues", false, "Play tones panned to the piece's column (overrides the settings)")
+	difficultyName := flag.String("difficulty", "", "Versus bot: easy, normal, hard or expert (overrides the settings)")
+	bestOf := flag.Int("best-of", 0, "Rounds in a versus match, the winner takes more than half (overrides the settings)")
+	screenshotFormat := flag.String("screenshot-format", string(picture.PNG), "Screenshot format: png, svg or ansi")
 	flag.Parse()
 
+	mode, err := game.ModeByName(*modeName)
+	if err != nil {
+		log.Fatal(err)
+	}
+	shotFormat, err := picture.ParseFormat(*screenshotFormat)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *fadeAfter > 0 {
+		mode.Stack.FadeAfter = *fadeAfter
+	}
+	if *invisible {
+		mode.Stack.Invisible = true
+	}
+	if *scoringName != "" {
+		if mode.Scorer, err = game.ScorerByName(*scoringName); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	settings := game.DefaultSettings
+	if *settingsFile != "" {
+		if settings, err = game.LoadSettings(*settingsFile); err != nil {
+			log.Fatalf("Cannot load settings: %v", err)
+		}
+	}
+	// Mouse, color, accessibility and versus flags given on the command line
+	// win over the settings
+	flag.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "mouse":
+			settings.Controls.Mouse = *mouse
+		case "click-to-drop":
+			settings.Controls.ClickToDrop = *clickToDrop
+		case "palette":
+			settings.Visuals.Palette = *paletteName
+		case "patterns":
+			settings.Visuals.Patterns = *patterns
+		case "announce":
+			settings.Access.Announce = *announce
+		case "audio-cues":
+			settings.Access.Cues = *audioCues
+		case "difficulty":
+			settings.Versus.Difficulty = *difficultyName
+		case "best-of":
+			settings.Versus.BestOf = *bestOf
+		}
+	})
+	if _, err := game.DifficultyByName(settings.Versus.Difficulty); err != nil {
+		log.Fatal(err)
+	}
+	if settings.Versus.BestOf < 1 {
+		log.Fatalf("A versus match needs at least one round, not %d", settings.Versus.BestOf)
+	}
+	if _, err := game.PaletteByName(settings.Visuals.Palette); err != nil {
+		log.Fatal(err)
+	}
+
 	var mgr *audio.AudioManager
 	if !*noMusic {
 		mgr = audio.NewManager(*musicPath)
 		if mgr != nil {
 			mgr.SetLooping(*loopMusic)
 			mgr.Play()
+			mgr.SetPaused(!settings.Audio.Music)
 		}
 	}
 
 	app := tview.NewApplication()
 	g := game.NewGame(app, mgr)
+	g.SetMode(mode)
+	g.SetSettings(settings)
+	g.SetSettingsFile(*settingsFile)
+	g.SetCues(audio.NewCues(mgr))
+	if *announceFile != "" {
+		// A named pipe blocks here until something reads it
+		f, err := os.OpenFile(*announceFile, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+		if err != nil {
+			log.Fatalf("Cannot open announce file: %v", err)
+		}
+		defer f.Close()
+		g.SetAnnouncements(f)
+	}
+
+	// Built-in puzzles first, then the player's own
+	puzzleList, err := game.LoadPuzzles(puzzles.Files)
+	if err != nil {
+		log.Fatalf("Built-in puzzles: %v", err)
+	}
+	if *puzzleDir != "" {
+		extra, err := game.LoadPuzzles(os.DirFS(*puzzleDir))
+		if err != nil {
+			log.Fatalf("Cannot load puzzles: %v", err)
+		}
+		puzzleList = append(puzzleList, extra...)
+	}
+
+	// A shared board goes first in the browser and starts right away
+	var shared *game.Puzzle
+	if *fumenCode != "" {
+		p, err := game.PuzzleFromFumen(*fumenCode, *fumenPage-1)
+		if err != nil {
+			log.Fatalf("Cannot load fumen: %v", err)
+		}
+		p.Name, p.Description = "Fumen", "The board from --fumen"
+		p.Goal = game.Goal{Kind: game.GoalFree}
+		puzzleList = append([]game.Puzzle{p}, puzzleList...)
+		shared = &p
+	}
+	g.SetPuzzles(puzzleList)
+	g.SetExportFile(*exportFile)
+	g.SetReplayFile(*replayFile)
+	if *screenshotDir != "" {
+		g.SetCamera(camera{dir: *screenshotDir, format: shotFormat})
+	}
+
+	// Bot games are never saved, ranked or kept, and must not replace the
+	// player's save
+	if !*autoplay {
+		g.SetSaveFile(*saveFile)
+		g.SetAutosave(*autosave)
+		g.SetReplayDir(*replayDir)
+		if *scoreFile != "" {
+			g.SetLeaderboard(scores.Open(*scoreFile), defaultPlayerName())
+		}
+	}
+
+	// The built-in evaluator backs the practice-mode hints
+	if hintBot, err := bot.New(bot.DefaultBot); err == nil {
+		g.SetHintBot(hintBot)
+	}
+	// Versus battles the built-in bot, or the external engine --bot names,
+	// started once and shared by every round; an engine plays at the
+	// difficulty's speed but its own strength
+	battleBot := func(d game.Difficulty) game.Bot { return bot.NewBattler(d) }
+	if strings.HasPrefix(*botName, tbpPrefix) {
+		engine, err := newBot(*botName)
+		if err != nil {
+			log.Fatalf("Cannot start the versus bot: %v", err)
+		}
+		defer closeBot(engine)
+		battleBot = func(game.Difficulty) game.Bot { return engine }
+	}
+	g.SetBattleBot(battleBot)
+
+	if *autoplay {
+		b, err := newBot(*botName)
+		if err != nil {
+			log.Fatalf("Cannot start autoplay: %v", err)
+		}
+		defer closeBot(b)
+		g.SetAutoplay(b, *autoplayPPS)
+	}
+
+	if *scoreLog != "" {
+		f, err := os.OpenFile(*scoreLog, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+		if err != nil {
+			log.Fatalf("Cannot open score log: %v", err)
+		}
+		defer f.Close()
+		g.Subscribe(scoreLogger(f, g))
+	}
+
+	// Let the soundtrack follow the game: hold it while paused, stop at the
+	// end, and keep quiet while the settings turn it off
+	if mgr != nil {
+		g.Subscribe(func(e game.Event) {
+			music := g.Settings().Audio.Music
+			switch ev := e.(type) {
+			case game.PausedEvent:
+				mgr.SetPaused(ev.Paused || !music)
+			case game.GameOverEvent:
+				mgr.SetPaused(true)
+			case game.PieceSpawnedEvent:
+				mgr.SetPaused(!music)
+			case game.SettingsChangedEvent:
+				mgr.SetPaused(!music)
+			}
+		})
+	}
 
-	if err := g.Run(); err != nil {
+	if *spectateListen != "" {
+		srv, err := spectate.Listen(*spectateListen, *spectateName)
+		if err != nil {
+			log.Fatalf("Cannot stream to spectators: %v", err)
+		}
+		defer srv.Close()
+		g.SetSpectators(srv)
+	}
+
+	if shared != nil {
+		g.StartPuzzle(*shared)
+	}
+
+	// Recording takes over the terminal, so it starts last
+	var finishCast func() error
+	if *castFile != "" {
+		finishCast, err = recordCast(app, *castFile)
+		if err != nil {
+			log.Fatalf("Cannot record the terminal: %v", err)
+		}
+	}
+
+	err = g.Run()
+	if finishCast != nil {
+		if cerr := finishCast(); cerr != nil {
+			log.Printf("Cast not saved: %v", cerr)
+		}
+	}
+	if err != nil {
 		log.Fatalf("Game crashed: %v", err)
 	}
 }
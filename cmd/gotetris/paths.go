@@ -0,0 +1,75 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+// dataDir is where gotetris keeps its files: $XDG_DATA_HOME/gotetris
+// (~/.local/share/gotetris) on Linux and the usual per-user application
+// folder elsewhere. It is empty if there is no home directory to put it in.
+func dataDir() string {
+	var base string
+	switch runtime.GOOS {
+	case "windows":
+		base = os.Getenv("LocalAppData")
+	case "darwin":
+		base, _ = os.UserConfigDir() // ~/Library/Application Support
+	default:
+		if base = os.Getenv("XDG_DATA_HOME"); base == "" {
+			if home, err := os.UserHomeDir(); err == nil {
+				base = filepath.Join(home, ".local", "share")
+			}
+		}
+	}
+	if base == "" {
+		return ""
+	}
+	return filepath.Join(base, "gotetris")
+}
+
+// defaultSavePath is the save file in the data dir.
+func defaultSavePath() string {
+	dir := dataDir()
+	if dir == "" {
+		return ""
+	}
+	return filepath.Join(dir, "save.json")
+}
+
+// defaultScoresPath is the high score table in the data dir.
+func defaultScoresPath() string {
+	dir := dataDir()
+	if dir == "" {
+		return ""
+	}
+	return filepath.Join(dir, "scores.json")
+}
+
+// defaultScreenshotDir is where screenshots go in the data dir.
+func defaultScreenshotDir() string {
+	dir := dataDir()
+	if dir == "" {
+		return ""
+	}
+	return filepath.Join(dir, "screenshots")
+}
+
+// defaultSettingsPath is the settings file in the data dir.
+func defaultSettingsPath() string {
+	dir := dataDir()
+	if dir == "" {
+		return ""
+	}
+	return filepath.Join(dir, "settings.json")
+}
+
+// defaultReplayDir is where finished games are kept in the data dir.
+func defaultReplayDir() string {
+	dir := dataDir()
+	if dir == "" {
+		return ""
+	}
+	return filepath.Join(dir, "replays")
+}
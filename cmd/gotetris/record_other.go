@@ -0,0 +1,14 @@
+//go:build !(aix || darwin || dragonfly || freebsd || linux || netbsd || openbsd || solaris || zos)
+
+package main
+
+import (
+	"errors"
+
+	"github.com/rivo/tview"
+)
+
+// recordCast needs a Unix terminal to sit between tcell and the screen.
+func recordCast(app *tview.Application, path string) (func() error, error) {
+	return nil, errors.New("recording casts is not supported on this system")
+}
@@ -0,0 +1,49 @@
+//go:build aix || darwin || dragonfly || freebsd || linux || netbsd || openbsd || solaris || zos
+
+package main
+
+import (
+	"errors"
+	"os"
+
+	"gotetris/internal/cast"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// recordCast gives app a screen that also writes everything it shows to an
+// asciinema cast at path. The returned function finishes the file once the
+// app has stopped.
+func recordCast(app *tview.Application, path string) (func() error, error) {
+	tty, err := tcell.NewDevTty()
+	if err != nil {
+		return nil, err
+	}
+	size, err := tty.WindowSize()
+	if err != nil {
+		tty.Close()
+		return nil, err
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		tty.Close()
+		return nil, err
+	}
+	cw, err := cast.NewWriter(f, size.Width, size.Height)
+	if err != nil {
+		f.Close()
+		tty.Close()
+		return nil, err
+	}
+	screen, err := tcell.NewTerminfoScreenFromTty(cast.Record(tty, cw))
+	if err != nil {
+		f.Close()
+		tty.Close()
+		return nil, err
+	}
+	app.SetScreen(screen)
+	return func() error {
+		return errors.Join(cw.Err(), f.Close())
+	}, nil
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"gotetris/internal/game"
+	"gotetris/internal/picture"
+)
+
+// runRender implements `gotetris render`: a picture of a position from a
+// fumen, a puzzle file, a replay or the saved game.
+func runRender(args []string) error {
+	fs := flag.NewFlagSet("render", flag.ExitOnError)
+	out := fs.String("o", "", "Write the picture to this file (default: ANSI text on stdout)")
+	formatName := fs.String("format", "", "png, svg or ansi (default: from the -o extension)")
+	fumenCode := fs.String("fumen", "", "Render this fumen")
+	fumenPage := fs.Int("fumen-page", 1, "Page of --fumen to render")
+	puzzleFile := fs.String("puzzle", "", "Render this puzzle file's setup")
+	replayFile := fs.String("replay", "", "Render this replay")
+	at := fs.Duration("at", 0, "How far into --replay to render, in play time (default: the end)")
+	saveFile := fs.String("save", "", "Render this saved game")
+	paletteName := fs.String("palette", "", "Piece colors: standard, deuteranopia, protanopia or tritanopia")
+	patterns := fs.Bool("patterns", false, "Mark each piece with its own glyphs (ANSI only)")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: gotetris render (--fumen code | --puzzle file | --replay file | --save file) [-o out.png]")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	format := picture.FormatFor(*out)
+	if *formatName != "" {
+		var err error
+		if format, err = picture.ParseFormat(*formatName); err != nil {
+			return err
+		}
+	}
+
+	var (
+		snap *game.Snapshot
+		err  error
+	)
+	switch {
+	case *fumenCode != "":
+		snap, err = fumenPosition(*fumenCode, *fumenPage-1)
+	case *puzzleFile != "":
+		snap, err = puzzlePosition(*puzzleFile)
+	case *replayFile != "":
+		snap, err = replayPosition(*replayFile, *at)
+	case *saveFile != "":
+		snap, err = savedPosition(*saveFile)
+	default:
+		fs.Usage()
+		return errors.New("nothing to render")
+	}
+	if err != nil {
+		return err
+	}
+	palette, err := game.PaletteByName(*paletteName)
+	if err != nil {
+		return err
+	}
+	snap.Palette, snap.Patterns = &palette, *patterns
+
+	if *out == "" {
+		return picture.Write(os.Stdout, snap, format)
+	}
+	return writePicture(*out, snap, format)
+}
+
+func fumenPosition(code string, page int) (*game.Snapshot, error) {
+	p, err := game.PuzzleFromFumen(code, page)
+	if err != nil {
+		return nil, err
+	}
+	p.Goal = game.Goal{Kind: game.GoalFree}
+	g := game.NewGame(nil, nil)
+	g.StartPuzzle(p)
+	return g.Capture(), nil
+}
+
+func puzzlePosition(path string) (*game.Snapshot, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	p, err := game.ParsePuzzle(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	g := game.NewGame(nil, nil)
+	g.StartPuzzle(p)
+	return g.Capture(), nil
+}
+
+func replayPosition(path string, at time.Duration) (*game.Snapshot, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	r, err := game.ReadReplay(f)
+	f.Close()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	player, err := game.NewReplayPlayer(r)
+	if err != nil {
+		return nil, err
+	}
+	for !player.Done() && (at == 0 || player.Snapshot().Elapsed < at) {
+		player.Step()
+	}
+	return player.Snapshot(), nil
+}
+
+func savedPosition(path string) (*game.Snapshot, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	g := game.NewGame(nil, nil)
+	if err := g.Resume(f); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return g.Capture(), nil
+}
+
+// writePicture writes a picture to a file of its own.
+func writePicture(path string, snap *game.Snapshot, format picture.Format) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	err = picture.Write(f, snap, format)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// camera keeps screenshots in a directory, named after when they were taken.
+type camera struct {
+	dir    string
+	format picture.Format
+}
+
+func (c camera) Save(s *game.Snapshot) (string, error) {
+	if err := os.MkdirAll(c.dir, 0o755); err != nil {
+		return "", err
+	}
+	path := filepath.Join(c.dir, "gotetris-"+time.Now().Format("20060102-150405.000")+c.format.Ext())
+	return path, writePicture(path, s, c.format)
+}
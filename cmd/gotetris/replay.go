@@ -0,0 +1,136 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"gotetris/internal/cast"
+	"gotetris/internal/game"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// Size of the terminal replays are rendered for: the board and a margin.
+const (
+	castWidth  = 62
+	castHeight = 34
+)
+
+// runReplay implements `gotetris replay`: plays a game recorded with
+// --record-replay back on the terminal, or renders it to a cast.
+func runReplay(args []string) error {
+	fs := flag.NewFlagSet("replay", flag.ExitOnError)
+	toCast := fs.String("to-cast", "", "Render the replay to this asciinema cast file instead of playing it")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: gotetris replay [--to-cast out.cast] file")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return errors.New("need one replay file")
+	}
+
+	f, err := os.Open(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	r, err := game.ReadReplay(f)
+	f.Close()
+	if err != nil {
+		return fmt.Errorf("%s: %w", fs.Arg(0), err)
+	}
+	player, err := game.NewReplayPlayer(r)
+	if err != nil {
+		return err
+	}
+
+	if *toCast != "" {
+		return renderCast(player, *toCast)
+	}
+	return playReplay(player)
+}
+
+// playReplay shows the game at the speed it was played, until it is over
+// and a key is pressed.
+func playReplay(player *game.ReplayPlayer) error {
+	app := tview.NewApplication()
+	app.SetInputCapture(func(ev *tcell.EventKey) *tcell.EventKey {
+		if player.Done() || ev.Key() == tcell.KeyEscape || ev.Key() == tcell.KeyCtrlC || ev.Rune() == 'q' || ev.Rune() == 'Q' {
+			app.Stop()
+		}
+		return nil
+	})
+
+	// The player is only stepped here; drawing reads its snapshots
+	go func() {
+		ticker := time.NewTicker(game.TickRate)
+		defer ticker.Stop()
+		for range ticker.C {
+			if player.Done() {
+				return
+			}
+			player.Step()
+			app.QueueUpdateDraw(func() {})
+		}
+	}()
+
+	return app.SetRoot(board(player), true).Run()
+}
+
+// renderCast draws the game frame by frame on a simulation screen, as fast
+// as it goes, and writes the frames to a cast timed as they were played.
+func renderCast(player *game.ReplayPlayer, path string) error {
+	screen := tcell.NewSimulationScreen("UTF-8")
+	if err := screen.Init(); err != nil {
+		return err
+	}
+	defer screen.Fini()
+	screen.SetSize(castWidth, castHeight)
+	root := board(player)
+	root.SetRect(0, 0, castWidth, castHeight)
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	cw, err := cast.NewWriter(f, castWidth, castHeight)
+	if err != nil {
+		f.Close()
+		return err
+	}
+
+	// Half the engine's frame rate is plenty for a recording
+	const framesPerShot = 2
+	var painter cast.Painter
+	shoot := func(frame int) {
+		screen.Clear()
+		root.Draw(screen)
+		screen.Show()
+		cw.Output(time.Duration(frame)*game.TickRate, painter.Paint(screen))
+	}
+	frame := 0
+	shoot(frame)
+	for !player.Done() {
+		player.Step()
+		frame++
+		if frame%framesPerShot == 0 || player.Done() {
+			shoot(frame)
+		}
+	}
+
+	// Leave the final board up a moment before the cast ends
+	cw.Output(time.Duration(frame)*game.TickRate+2*time.Second, []byte("\x1b[?25h"))
+	return errors.Join(cw.Err(), f.Close())
+}
+
+// board lays a replay out like the game: the board with a margin.
+func board(src game.Source) tview.Primitive {
+	return tview.NewFlex().
+		AddItem(tview.NewBox(), 2, 0, false).
+		AddItem(game.NewBoard(src), 0, 1, false)
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"gotetris/internal/game"
+)
+
+// scoreLogger writes a line to w for every lock of g that scores, with the
+// awards that add up to it, e.g.
+//
+//	1:42 marathon level 3: T-spin double 1200 × level 3 = 3600; Hard drop, 14 rows 28 → 48210
+//
+// It runs as a subscriber, on the game's goroutine.
+func scoreLogger(w io.Writer, g *game.Game) game.EventHandler {
+	return func(e game.Event) {
+		switch ev := e.(type) {
+		case game.ScoredEvent:
+			awards := make([]string, len(ev.Awards))
+			for i, a := range ev.Awards {
+				awards[i] = a.String()
+			}
+			elapsed := g.Elapsed()
+			fmt.Fprintf(w, "%d:%02d %s level %d: %s → %d\n", int(elapsed.Minutes()), int(elapsed.Seconds())%60,
+				g.Mode.Name, g.Level, strings.Join(awards, "; "), ev.Score)
+		case game.GameOverEvent:
+			fmt.Fprintf(w, "Game over (%s): %d points, %d lines\n", ev.Reason, ev.Score, ev.Lines)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"path/filepath"
+
+	"gotetris/internal/bot"
+	"gotetris/internal/game"
+	"gotetris/internal/scores"
+	"gotetris/internal/sshserver"
+	"gotetris/puzzles"
+
+	"github.com/rivo/tview"
+)
+
+// runServeSSH implements `gotetris serve-ssh`: a game for everyone who logs
+// in, each in their own terminal, with the results going to one high score
+// table.
+func runServeSSH(args []string) error {
+	fs := flag.NewFlagSet("serve-ssh", flag.ExitOnError)
+	addr := fs.String("addr", ":2222", "Address to listen on")
+	hostKey := fs.String("hostkey", filepath.Join(dataDir(), "ssh_host_ed25519_key"), "Host key file, made on first start if missing")
+	scoreFile := fs.String("scores", defaultScoresPath(), "High score table the players' games go to")
+	modeName := fs.String("mode", game.Marathon.Name, "Game mode the Play menu starts on")
+	fs.Parse(args)
+
+	mode, err := game.ModeByName(*modeName)
+	if err != nil {
+		return err
+	}
+	puzzleList, err := game.LoadPuzzles(puzzles.Files)
+	if err != nil {
+		return fmt.Errorf("built-in puzzles: %w", err)
+	}
+	key, err := sshserver.LoadHostKey(*hostKey)
+	if err != nil {
+		return fmt.Errorf("host key: %w", err)
+	}
+	var table *scores.Table
+	if *scoreFile != "" {
+		table = scores.Open(*scoreFile)
+	}
+
+	srv, err := sshserver.Listen(*addr, key, func(s *sshserver.Session) error {
+		return playSession(s, mode, puzzleList, table)
+	})
+	if err != nil {
+		return err
+	}
+	log.Printf("Serving gotetris over SSH on %s", srv.Addr())
+	return srv.Serve()
+}
+
+// playSession runs one player's game on their SSH terminal.
+func playSession(s *sshserver.Session, mode game.Mode, puzzleList []game.Puzzle, table *scores.Table) error {
+	screen, err := s.Screen()
+	if err != nil {
+		return err
+	}
+	app := tview.NewApplication().SetScreen(screen)
+
+	// No music and no save file: the server's are not the player's
+	g := game.NewGame(app, nil)
+	g.SetMode(mode)
+	g.SetPuzzles(puzzleList)
+	settings := game.DefaultSettings
+	settings.Controls.Mouse = false
+	g.SetSettings(settings)
+	if hintBot, err := bot.New(bot.DefaultBot); err == nil {
+		g.SetHintBot(hintBot)
+	}
+	g.SetBattleBot(func(d game.Difficulty) game.Bot { return bot.NewBattler(d) })
+	if table != nil {
+		g.SetLeaderboard(table, s.User)
+	}
+	return g.Run()
+}
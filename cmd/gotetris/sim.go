@@ -0,0 +1,91 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"sort"
+	"time"
+
+	"gotetris/internal/bot"
+	"gotetris/internal/game"
+	"gotetris/internal/sim"
+)
+
+// runSim implements `gotetris sim`: headless batch games with a bot.
+func runSim(args []string) error {
+	fs := flag.NewFlagSet("sim", flag.ExitOnError)
+	games := fs.Int("games", 100, "Number of games to play")
+	botName := fs.String("bot", bot.DefaultBot, botUsage)
+	seed := fs.Uint64("seed", uint64(time.Now().UnixNano()), "Seed of the first game (game i uses seed+i)")
+	modeName := fs.String("mode", game.Marathon.Name, "Game mode")
+	scoringName := fs.String("scoring", "", "Scoring rules: guideline, nes or tgm (default: the mode's own)")
+	workers := fs.Int("workers", runtime.NumCPU(), "Games played in parallel")
+	pps := fs.Float64("pps", 0, "Bot pieces per second in simulated time (0 = unlimited)")
+	maxPieces := fs.Int("max-pieces", 10000, "Stop endless games after this many pieces (0 = never)")
+	format := fs.String("format", "text", "Output format: text or json")
+	fs.Parse(args)
+
+	mode, err := game.ModeByName(*modeName)
+	if err != nil {
+		return err
+	}
+	if *scoringName != "" {
+		if mode.Scorer, err = game.ScorerByName(*scoringName); err != nil {
+			return err
+		}
+	}
+	// Fail early on a bad name instead of once per game
+	b, err := newBot(*botName)
+	if err != nil {
+		return err
+	}
+	closeBot(b)
+
+	rep, err := sim.Run(sim.Config{
+		Games:     *games,
+		Workers:   *workers,
+		NewBot:    func() (game.Bot, error) { return newBot(*botName) },
+		Seed:      *seed,
+		Mode:      mode,
+		PPS:       *pps,
+		MaxPieces: *maxPieces,
+	})
+	if err != nil {
+		return err
+	}
+
+	switch *format {
+	case "json":
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(rep)
+	case "text":
+		printReport(os.Stdout, rep, *botName, *seed)
+		return nil
+	default:
+		return fmt.Errorf("unknown format %q (text or json)", *format)
+	}
+}
+
+// printReport writes the human readable summary.
+func printReport(w io.Writer, rep sim.Report, botName string, seed uint64) {
+	fmt.Fprintf(w, "%d %s games with bot %q (seeds %d..%d) in %s\n",
+		rep.Games, rep.Mode, botName, seed, seed+uint64(rep.Games)-1, rep.WallTime.Round(time.Millisecond))
+	fmt.Fprintf(w, "  lines  mean %10.1f  median %10.1f\n", rep.MeanLines, rep.MedianLines)
+	fmt.Fprintf(w, "  score  mean %10.1f  median %10.1f\n", rep.MeanScore, rep.MedianScore)
+	fmt.Fprintf(w, "  pps    mean %10.2f\n", rep.MeanPPS)
+
+	fmt.Fprintln(w, "  game ended by:")
+	causes := make([]string, 0, len(rep.Causes))
+	for c := range rep.Causes {
+		causes = append(causes, c)
+	}
+	sort.Strings(causes)
+	for _, c := range causes {
+		fmt.Fprintf(w, "    %-14s %d\n", c, rep.Causes[c])
+	}
+}
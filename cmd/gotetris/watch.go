@@ -0,0 +1,93 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"math"
+	"os"
+	"os/user"
+	"time"
+
+	"gotetris/internal/game"
+	"gotetris/internal/spectate"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// runWatch implements `gotetris watch`: live, read-only views of games
+// streamed with --spectate-listen, several of them side by side in a grid.
+func runWatch(args []string) error {
+	fs := flag.NewFlagSet("watch", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: gotetris watch host:port [host:port...]")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return fmt.Errorf("no game to watch")
+	}
+
+	// One board per game, in a grid as square as it gets
+	feeds := make([]*spectate.Feed, fs.NArg())
+	cols := int(math.Ceil(math.Sqrt(float64(len(feeds)))))
+	grid := tview.NewGrid()
+	grid.SetColumns(columns(cols)...)
+	for i, addr := range fs.Args() {
+		feeds[i] = spectate.Watch(addr)
+		defer feeds[i].Close()
+		grid.AddItem(game.NewBoard(feeds[i]), i/cols, i%cols, 1, 1, 0, 0, false)
+	}
+
+	app := tview.NewApplication()
+	app.SetInputCapture(func(ev *tcell.EventKey) *tcell.EventKey {
+		if ev.Key() == tcell.KeyEscape || ev.Key() == tcell.KeyCtrlC || ev.Rune() == 'q' || ev.Rune() == 'Q' {
+			app.Stop()
+			return nil
+		}
+		return ev
+	})
+
+	// Redraw at most once a frame, whichever boards changed
+	go func() {
+		ticker := time.NewTicker(game.TickRate)
+		defer ticker.Stop()
+		for range ticker.C {
+			changed := false
+			for _, f := range feeds {
+				select {
+				case <-f.Updates():
+					changed = true
+				default:
+				}
+			}
+			if changed {
+				app.QueueUpdateDraw(func() {})
+			}
+		}
+	}()
+
+	return app.SetRoot(grid, true).Run()
+}
+
+// columns sizes the grid: boards are 60 wide, with a gap of 2 after each.
+func columns(n int) []int {
+	widths := make([]int, n)
+	for i := range widths {
+		widths[i] = 62
+	}
+	return widths
+}
+
+// defaultPlayerName is the name a streamed game goes by: the login name, or
+// the machine's if there is none.
+func defaultPlayerName() string {
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		return u.Username
+	}
+	if host, err := os.Hostname(); err == nil {
+		return host
+	}
+	return "player"
+}
@@ -0,0 +1,31 @@
+// Command tbpstub is a tiny Tetris Bot Protocol bot built on the built-in
+// heuristic. It needs nothing but this repository, so the TBP code path can
+// be exercised offline:
+//
+//	gotetris sim --games 10 --bot tbp:./bin/tbpstub
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"gotetris/internal/bot"
+	"gotetris/internal/tbp"
+)
+
+func main() {
+	botName := flag.String("bot", bot.DefaultBot, fmt.Sprintf("Built-in bot to serve %v", bot.Names()))
+	flag.Parse()
+
+	b, err := bot.New(*botName)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	info := tbp.Info{Name: "gotetris-stub", Version: "1.0", Author: "gotetris"}
+	if err := tbp.Serve(os.Stdin, os.Stdout, info, b); err != nil {
+		log.Fatalf("tbpstub: %v", err)
+	}
+}
@@ -0,0 +1,69 @@
+package audio
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"github.com/faiface/beep"
+	"github.com/faiface/beep/effects"
+	"github.com/faiface/beep/speaker"
+)
+
+// cueRate is the sample rate of cues played without music to share the
+// speaker with.
+const cueRate = beep.SampleRate(44100)
+
+// cueVolume is the loudest a cue gets, well under the music.
+const cueVolume = 0.25
+
+// Cues plays short tones panned between the left and the right speaker, so
+// players can follow by ear where something happens.
+type Cues struct {
+	music    *AudioManager
+	once     sync.Once
+	rate     beep.SampleRate
+	disabled bool
+}
+
+// NewCues gets cues ready to play over the music of m. Without music, m nil
+// or disabled, the speaker is set up for the cues when the first one plays.
+func NewCues(m *AudioManager) *Cues {
+	return &Cues{music: m}
+}
+
+// ready sets the speaker up, once, and reports whether cues can play.
+func (c *Cues) ready() bool {
+	c.once.Do(func() {
+		if m := c.music; m != nil && !m.disabled {
+			c.rate = m.format.SampleRate
+			return
+		}
+		c.rate = cueRate
+		c.disabled = speaker.Init(cueRate, cueRate.N(time.Second/20)) != nil
+	})
+	return !c.disabled
+}
+
+// Tone plays a tone of freq Hz for d, panned from -1 (left) to 1 (right).
+// It fades out as it plays, so it ends without a click, and returns at once.
+func (c *Cues) Tone(freq, pan float64, d time.Duration) {
+	if c == nil || !c.ready() {
+		return
+	}
+	n := c.rate.N(d)
+	i := 0
+	tone := beep.StreamerFunc(func(samples [][2]float64) (int, bool) {
+		if i >= n {
+			return 0, false
+		}
+		k := 0
+		for ; k < len(samples) && i < n; k, i = k+1, i+1 {
+			fade := 1 - float64(i)/float64(n)
+			v := cueVolume * fade * math.Sin(2*math.Pi*freq*float64(i)/float64(c.rate))
+			samples[k] = [2]float64{v, v}
+		}
+		return k, true
+	})
+	speaker.Play(&effects.Pan{Streamer: tone, Pan: max(-1, min(1, pan))})
+}
@@ -15,6 +15,7 @@ import (
 type AudioManager struct {
     mu        sync.Mutex
     streamer  beep.StreamSeekCloser
+    ctrl      *beep.Ctrl
     format    beep.Format
     loop      bool
     disabled  bool
@@ -71,11 +72,27 @@ func (m *AudioManager) Play() {
     if m.loop {
         seq = beep.Loop(-1, m.streamer)
     }
-    speaker.Play(beep.Seq(seq, beep.Callback(func() {
+    m.ctrl = &beep.Ctrl{Streamer: seq}
+    speaker.Play(beep.Seq(m.ctrl, beep.Callback(func() {
         close(m.done)
     })))
 }
 
+// SetPaused pauses or resumes playback without losing the position.
+func (m *AudioManager) SetPaused(paused bool) {
+    if m.disabled {
+        return
+    }
+    m.mu.Lock()
+    defer m.mu.Unlock()
+    if m.ctrl == nil {
+        return
+    }
+    speaker.Lock()
+    m.ctrl.Paused = paused
+    speaker.Unlock()
+}
+
 // Stop stops playback immediately.
 func (m *AudioManager) Stop() {
     if m.disabled {
@@ -0,0 +1,124 @@
+package bot
+
+import (
+	"math"
+	"math/rand/v2"
+	"sort"
+	"sync"
+
+	"gotetris/internal/game"
+)
+
+// --- Battle Bot ---------------------------------------------------------------
+
+// Tuning of the battle bot.
+const (
+	attackWeight   = 4.0 // Worth of each line of garbage a placement sends
+	beamWidth      = 6   // Best placements looked further into at each piece
+	topOutPenalty  = 1e6 // Cost of a line of play that tops out
+	maxMistakeRank = 4   // Worst runner-up a careless placement picks
+)
+
+// Battler is the bot Versus plays against. On top of the heuristic it values
+// the garbage a placement sends, looks Depth pieces into the queue, and now
+// and then picks one of its runners-up instead of its best placement. It is
+// safe to ask for placements from several goroutines at once.
+type Battler struct {
+	Weights  Weights
+	Depth    int     // Pieces looked at, the one in play included
+	Mistakes float64 // Chance of a careless placement
+
+	mu  sync.Mutex // Guards rng
+	rng *rand.Rand
+}
+
+// NewBattler returns a battle bot that plays at difficulty d.
+func NewBattler(d game.Difficulty) *Battler {
+	return &Battler{
+		Weights:  Presets[DefaultBot],
+		Depth:    max(1, d.Depth),
+		Mistakes: d.Mistakes,
+		rng:      rand.New(rand.NewPCG(rand.Uint64(), rand.Uint64())),
+	}
+}
+
+// option is a placement with the board it leaves and what it is worth.
+type option struct {
+	p     game.Placement
+	board game.Field
+	score float64
+	queue []game.PieceID // Pieces that follow it
+}
+
+// Suggest implements game.Bot.
+func (b *Battler) Suggest(pos game.Position) (game.Placement, bool) {
+	options := b.options(&pos.Playfield, pos.Current, pos.Queue)
+	if alt, ok := pos.Alternative(); ok && alt != pos.Current.Piece {
+		// Holding into an empty slot takes the next piece off the queue
+		queue := pos.Queue
+		if pos.Hold == 0 && len(queue) > 0 {
+			queue = queue[1:]
+		}
+		options = append(options, b.options(&pos.Playfield, game.Placement{Piece: alt, Position: game.SpawnPoint()}, queue)...)
+	}
+	if len(options) == 0 {
+		return game.Placement{}, false
+	}
+
+	best(options)
+	if b.Depth > 1 {
+		options = options[:min(beamWidth, len(options))]
+		for i := range options {
+			options[i].score += b.lookahead(&options[i].board, options[i].queue, b.Depth-1)
+		}
+		best(options)
+	}
+
+	return options[b.pick(len(options))].p, true
+}
+
+// pick is which of n options, best first, to play: the best, or now and
+// then a runner-up.
+func (b *Battler) pick(n int) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if n > 1 && b.rng.Float64() < b.Mistakes {
+		return 1 + b.rng.IntN(min(maxMistakeRank, n-1))
+	}
+	return 0
+}
+
+// lookahead is the most the next depth pieces of queue can add to board.
+func (b *Battler) lookahead(board *game.Field, queue []game.PieceID, depth int) float64 {
+	if depth <= 0 || len(queue) == 0 {
+		return 0
+	}
+	options := b.options(board, game.Placement{Piece: queue[0], Position: game.SpawnPoint()}, queue[1:])
+	if len(options) == 0 {
+		return -topOutPenalty // The piece has nowhere to go
+	}
+	best(options)
+	top := math.Inf(-1)
+	for _, o := range options[:min(beamWidth, len(options))] {
+		top = max(top, o.score+b.lookahead(&o.board, o.queue, depth-1))
+	}
+	return top
+}
+
+// options scores every placement reachable from start, with queue to follow.
+func (b *Battler) options(f *game.Field, start game.Placement, queue []game.PieceID) []option {
+	var out []option
+	for _, p := range game.ReachablePlacements(f, start) {
+		feat := Measure(f, p)
+		o := option{p: p, board: *f, queue: queue}
+		game.PlacePiece(&o.board, p)
+		o.score = b.Weights.Score(feat) + attackWeight*float64(game.GuidelineAttack.Lines(game.Lock{Lines: feat.Lines}))
+		out = append(out, o)
+	}
+	return out
+}
+
+// best sorts options best first.
+func best(options []option) {
+	sort.SliceStable(options, func(i, j int) bool { return options[i].score > options[j].score })
+}
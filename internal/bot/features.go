@@ -0,0 +1,99 @@
+package bot
+
+import "gotetris/internal/game"
+
+// --- Board Features -----------------------------------------------------------
+
+// Features are the board measurements the heuristic weighs against each other.
+type Features struct {
+	AggregateHeight int     // Sum of all column heights
+	Holes           int     // Empty cells with a block somewhere above them
+	Bumpiness       int     // Sum of height differences between neighbors
+	Wells           int     // Cumulative well depth (1+2+...+depth per well)
+	Lines           int     // Rows cleared by the placement
+	LandingHeight   float64 // Height of the piece's center where it locked
+	RowTransitions  int     // Filled/empty changes along each row
+	ColTransitions  int     // Filled/empty changes along each column
+}
+
+// Measure places p on a copy of f and measures the result.
+func Measure(f *game.Field, p game.Placement) Features {
+	board := *f
+	var feat Features
+
+	// Landing height is taken before any rows disappear
+	lo, hi := game.TotalHeight, -1
+	for _, c := range p.Cells() {
+		lo, hi = min(lo, c.Y), max(hi, c.Y)
+	}
+	feat.LandingHeight = float64(lo+hi)/2 + 1
+
+	feat.Lines = game.PlacePiece(&board, p)
+	measureBoard(&board, &feat)
+	return feat
+}
+
+// measureBoard fills in everything that only depends on the board itself.
+func measureBoard(f *game.Field, feat *Features) {
+	var heights [game.PlayWidth]int
+	maxHeight := 0
+	for x := 0; x < game.PlayWidth; x++ {
+		for y := game.TotalHeight - 1; y >= 0; y-- {
+			if f[x][y] != 0 {
+				heights[x] = y + 1
+				break
+			}
+		}
+		maxHeight = max(maxHeight, heights[x])
+	}
+
+	filled := func(x, y int) bool {
+		// Walls and the floor count as filled
+		if x < 0 || x >= game.PlayWidth || y < 0 {
+			return true
+		}
+		return f[x][y] != 0
+	}
+
+	for x := 0; x < game.PlayWidth; x++ {
+		feat.AggregateHeight += heights[x]
+		if x > 0 {
+			feat.Bumpiness += abs(heights[x] - heights[x-1])
+		}
+
+		depth := 0
+		for y := 0; y < heights[x]; y++ {
+			if !filled(x, y) {
+				feat.Holes++
+			}
+			if filled(x, y) != filled(x, y-1) {
+				feat.ColTransitions++
+			}
+		}
+
+		// Wells: open cells walled in on both sides, counted from the top down
+		for y := maxHeight - 1; y >= 0; y-- {
+			if !filled(x, y) && filled(x-1, y) && filled(x+1, y) {
+				depth++
+				feat.Wells += depth
+			} else if filled(x, y) {
+				depth = 0
+			}
+		}
+	}
+
+	for y := 0; y < maxHeight; y++ {
+		for x := 0; x <= game.PlayWidth; x++ {
+			if filled(x, y) != filled(x-1, y) {
+				feat.RowTransitions++
+			}
+		}
+	}
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
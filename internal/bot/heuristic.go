@@ -0,0 +1,106 @@
+package bot
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"gotetris/internal/game"
+)
+
+// --- Heuristic Bot ------------------------------------------------------------
+
+// Weights score each board feature. Negative weights punish a feature.
+type Weights struct {
+	AggregateHeight float64
+	Holes           float64
+	Bumpiness       float64
+	Wells           float64
+	Lines           float64
+	LandingHeight   float64
+	RowTransitions  float64
+	ColTransitions  float64
+}
+
+// Score combines the features of a placement into a single number.
+func (w Weights) Score(f Features) float64 {
+	return w.AggregateHeight*float64(f.AggregateHeight) +
+		w.Holes*float64(f.Holes) +
+		w.Bumpiness*float64(f.Bumpiness) +
+		w.Wells*float64(f.Wells) +
+		w.Lines*float64(f.Lines) +
+		w.LandingHeight*f.LandingHeight +
+		w.RowTransitions*float64(f.RowTransitions) +
+		w.ColTransitions*float64(f.ColTransitions)
+}
+
+// Presets are the built-in weight sets, selectable by name.
+var Presets = map[string]Weights{
+	// El-Tetris: Pierre Dellacherie's features with tuned weights
+	"eltetris": {
+		LandingHeight:  -4.500158825082766,
+		Lines:          3.4181268101392694,
+		RowTransitions: -3.2178882868487753,
+		ColTransitions: -9.348695305445199,
+		Holes:          -7.899265427351652,
+		Wells:          -3.3855972247263626,
+	},
+	// The classic four-feature weights, plus a small penalty on wells
+	"simple": {
+		AggregateHeight: -0.510066,
+		Lines:           0.760666,
+		Holes:           -0.35663,
+		Bumpiness:       -0.184483,
+		Wells:           -0.05,
+	},
+}
+
+// DefaultBot is the preset used when no bot is named.
+const DefaultBot = "eltetris"
+
+// Heuristic is a one-piece lookahead bot: it tries every reachable placement
+// of the current piece (and of the piece it could hold instead) and keeps the
+// one with the best score.
+type Heuristic struct {
+	Weights Weights
+}
+
+// Suggest implements game.Bot.
+func (h *Heuristic) Suggest(pos game.Position) (game.Placement, bool) {
+	starts := []game.Placement{pos.Current}
+	if alt, ok := pos.Alternative(); ok && alt != pos.Current.Piece {
+		starts = append(starts, game.Placement{Piece: alt, Position: game.SpawnPoint()})
+	}
+
+	best, bestScore := game.Placement{}, math.Inf(-1)
+	for _, start := range starts {
+		for _, p := range game.ReachablePlacements(&pos.Playfield, start) {
+			if score := h.Weights.Score(Measure(&pos.Playfield, p)); score > bestScore {
+				best, bestScore = p, score
+			}
+		}
+	}
+	return best, !math.IsInf(bestScore, -1)
+}
+
+// New returns the built-in bot with the given name.
+func New(name string) (game.Bot, error) {
+	if name == "" {
+		name = DefaultBot
+	}
+	w, ok := Presets[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown bot %q (available: %v)", name, Names())
+	}
+	return &Heuristic{Weights: w}, nil
+}
+
+// Names lists the built-in bots in alphabetical order.
+func Names() []string {
+	names := make([]string, 0, len(Presets))
+	for name := range Presets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
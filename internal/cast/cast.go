@@ -0,0 +1,103 @@
+// Package cast writes terminal sessions as asciinema casts (format v2), so a
+// game can be watched again with asciinema play or shared on a web page.
+//
+// A live game is recorded by putting Record between tcell and the terminal.
+// Frames drawn without a terminal, on a tcell simulation screen, are turned
+// into terminal output by a Painter.
+package cast
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// header is the first line of a cast.
+type header struct {
+	Version   int               `json:"version"`
+	Width     int               `json:"width"`
+	Height    int               `json:"height"`
+	Timestamp int64             `json:"timestamp"`
+	Env       map[string]string `json:"env,omitempty"`
+}
+
+// Writer writes the events of a cast. It may be used from several
+// goroutines; the first error stops it and is kept for Err.
+type Writer struct {
+	mu      sync.Mutex
+	w       io.Writer
+	pending []byte // Start of a character the next output finishes
+	err     error
+}
+
+// NewWriter starts a cast for a terminal of the given size.
+func NewWriter(w io.Writer, width, height int) (*Writer, error) {
+	line, err := json.Marshal(header{
+		Version:   2,
+		Width:     width,
+		Height:    height,
+		Timestamp: time.Now().Unix(),
+		Env:       map[string]string{"TERM": os.Getenv("TERM"), "SHELL": os.Getenv("SHELL")},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(append(line, '\n')); err != nil {
+		return nil, err
+	}
+	return &Writer{w: w}, nil
+}
+
+// Output records what was written to the terminal at the given time into
+// the session. A character split across two writes goes out with the second.
+func (c *Writer) Output(at time.Duration, p []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	data := append(c.pending, p...)
+	cut := len(data)
+	for i := len(data) - 1; i >= 0 && i >= len(data)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(data[i]) {
+			if !utf8.FullRune(data[i:]) {
+				cut = i
+			}
+			break
+		}
+	}
+	c.pending = append([]byte(nil), data[cut:]...)
+	if cut > 0 {
+		c.event(at, "o", string(data[:cut]))
+	}
+}
+
+// Resize records the terminal changing size.
+func (c *Writer) Resize(at time.Duration, width, height int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.event(at, "r", fmt.Sprintf("%dx%d", width, height))
+}
+
+// Err returns the first error writing the cast, if any.
+func (c *Writer) Err() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.err
+}
+
+// event writes one event line. The caller holds c.mu.
+func (c *Writer) event(at time.Duration, kind, data string) {
+	if c.err != nil {
+		return
+	}
+	seconds := math.Round(at.Seconds()*1e6) / 1e6
+	line, err := json.Marshal([]any{seconds, kind, data})
+	if err == nil {
+		_, err = c.w.Write(append(line, '\n'))
+	}
+	c.err = err
+}
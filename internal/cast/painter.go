@@ -0,0 +1,121 @@
+package cast
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/mattn/go-runewidth"
+)
+
+// --- Offline Frames ----------------------------------------------------------
+
+// Painter turns what is on a simulation screen into the output a terminal
+// needs to show it, sending only the cells that changed since the last
+// frame.
+type Painter struct {
+	width, height int
+	last          []tcell.SimCell // Screen as of the last frame, nil before the first
+}
+
+// Paint returns the output that brings the terminal from the last frame to
+// the screen's current contents, nothing if the screen did not change.
+func (p *Painter) Paint(s tcell.SimulationScreen) []byte {
+	cells, w, h := s.GetContents()
+
+	var b strings.Builder
+	if p.last == nil || w != p.width || h != p.height {
+		// Start from a blank terminal with the cursor out of sight
+		b.WriteString("\x1b[0m\x1b[?25l\x1b[H\x1b[2J")
+		p.width, p.height, p.last = w, h, make([]tcell.SimCell, len(cells))
+		for i := range p.last {
+			p.last[i].Runes = []rune{' '}
+		}
+	}
+
+	curX, curY := -1, -1
+	style := tcell.StyleDefault
+	changed := false
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			i := y*w + x
+			c := cells[i]
+			if sameCell(c, p.last[i]) {
+				continue
+			}
+			// GetContents hands out the screen's own cells; keep copies
+			p.last[i] = tcell.SimCell{Style: c.Style, Runes: append([]rune(nil), c.Runes...)}
+			if !changed {
+				b.WriteString("\x1b[0m")
+				changed = true
+			}
+
+			text := string(c.Runes)
+			if len(c.Runes) == 0 || c.Runes[0] == 0 {
+				text = " "
+			}
+			width := runewidth.StringWidth(text)
+			if width == 0 || x+width > w {
+				// A stray combining mark, or a wide rune off the edge
+				text, width = " ", 1
+			}
+
+			if x != curX || y != curY {
+				fmt.Fprintf(&b, "\x1b[%d;%dH", y+1, x+1)
+			}
+			if c.Style != style {
+				b.WriteString(sgr(c.Style))
+				style = c.Style
+			}
+			b.WriteString(text)
+			curX, curY = x+width, y
+			if width > 1 {
+				x += width - 1
+			}
+		}
+	}
+	if changed {
+		b.WriteString("\x1b[0m")
+	}
+	return []byte(b.String())
+}
+
+func sameCell(a, b tcell.SimCell) bool {
+	if a.Style != b.Style || len(a.Runes) != len(b.Runes) {
+		return false
+	}
+	for i := range a.Runes {
+		if a.Runes[i] != b.Runes[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// sgr is the escape sequence that selects a style from scratch.
+func sgr(st tcell.Style) string {
+	fg, bg, attrs := st.Decompose()
+	codes := []string{"0"}
+	for _, a := range []struct {
+		mask tcell.AttrMask
+		code string
+	}{
+		{tcell.AttrBold, "1"}, {tcell.AttrDim, "2"}, {tcell.AttrItalic, "3"},
+		{tcell.AttrUnderline, "4"}, {tcell.AttrBlink, "5"}, {tcell.AttrReverse, "7"},
+		{tcell.AttrStrikeThrough, "9"},
+	} {
+		if attrs&a.mask != 0 {
+			codes = append(codes, a.code)
+		}
+	}
+	if fg != tcell.ColorDefault && fg.Valid() {
+		r, g, b := fg.RGB()
+		codes = append(codes, "38;2", strconv.Itoa(int(r)), strconv.Itoa(int(g)), strconv.Itoa(int(b)))
+	}
+	if bg != tcell.ColorDefault && bg.Valid() {
+		r, g, b := bg.RGB()
+		codes = append(codes, "48;2", strconv.Itoa(int(r)), strconv.Itoa(int(g)), strconv.Itoa(int(b)))
+	}
+	return "\x1b[" + strings.Join(codes, ";") + "m"
+}
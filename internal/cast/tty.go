@@ -0,0 +1,44 @@
+package cast
+
+import (
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// --- Live Recording ----------------------------------------------------------
+
+// recordingTty passes everything through to the terminal, keeping a copy of
+// what is written to it.
+type recordingTty struct {
+	tcell.Tty
+	cast  *Writer
+	start time.Time
+}
+
+// Record wraps tty so that everything tcell writes to it, and every change
+// of its size, also goes into the cast. Times count from now.
+func Record(tty tcell.Tty, c *Writer) tcell.Tty {
+	return &recordingTty{Tty: tty, cast: c, start: time.Now()}
+}
+
+func (t *recordingTty) Write(p []byte) (int, error) {
+	n, err := t.Tty.Write(p)
+	if n > 0 {
+		t.cast.Output(time.Since(t.start), p[:n])
+	}
+	return n, err
+}
+
+func (t *recordingTty) NotifyResize(cb func()) {
+	if cb == nil {
+		t.Tty.NotifyResize(nil)
+		return
+	}
+	t.Tty.NotifyResize(func() {
+		if ws, err := t.Tty.WindowSize(); err == nil {
+			t.cast.Resize(time.Since(t.start), ws.Width, ws.Height)
+		}
+		cb()
+	})
+}
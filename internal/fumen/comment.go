@@ -0,0 +1,209 @@
+package fumen
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+// --- Comments -----------------------------------------------------------------
+//
+// Comments are escaped the way JavaScript's escape() does it, then packed
+// four printable ASCII characters into five base64 digits.
+
+// maxComment is the longest escaped comment fumen can store.
+const maxComment = 4095
+
+// commentBase is the number of printable ASCII characters, space to tilde,
+// plus one.
+const commentBase = 96
+
+func (r *reader) comment() (string, error) {
+	n, err := r.poll(2)
+	if err != nil {
+		return "", err
+	}
+	b := make([]byte, 0, n)
+	for len(b) < n {
+		v, err := r.poll(5)
+		if err != nil {
+			return "", err
+		}
+		for i := 0; i < 4 && len(b) < n; i++ {
+			b = append(b, byte(v%commentBase+' '))
+			v /= commentBase
+		}
+	}
+	return unescape(string(b)), nil
+}
+
+func (w *writer) comment(text string) {
+	s := escape(text)
+	if len(s) > maxComment {
+		s = s[:maxComment]
+	}
+	w.push(len(s), 2)
+	for i := 0; i < len(s); i += 4 {
+		v, scale := 0, 1
+		for j := i; j < i+4 && j < len(s); j++ {
+			v += int(s[j]-' ') * scale
+			scale *= commentBase
+		}
+		w.push(v, 5)
+	}
+}
+
+// escape mirrors JavaScript's escape(): letters, digits and @*_+-./ stay,
+// everything else becomes %XX or, beyond Latin-1, %uXXXX.
+func escape(s string) string {
+	var b strings.Builder
+	for _, u := range utf16.Encode([]rune(s)) {
+		switch {
+		case u < 0x80 && (isAlnum(byte(u)) || strings.IndexByte("@*_+-./", byte(u)) >= 0):
+			b.WriteByte(byte(u))
+		case u < 0x100:
+			fmt.Fprintf(&b, "%%%02X", u)
+		default:
+			fmt.Fprintf(&b, "%%u%04X", u)
+		}
+	}
+	return b.String()
+}
+
+// unescape reverses escape. Malformed sequences are kept as they are.
+func unescape(s string) string {
+	var units []uint16
+	for i := 0; i < len(s); i++ {
+		if s[i] == '%' {
+			if i+6 <= len(s) && s[i+1] == 'u' {
+				if v, err := strconv.ParseUint(s[i+2:i+6], 16, 16); err == nil {
+					units = append(units, uint16(v))
+					i += 5
+					continue
+				}
+			}
+			if i+3 <= len(s) {
+				if v, err := strconv.ParseUint(s[i+1:i+3], 16, 8); err == nil {
+					units = append(units, uint16(v))
+					i += 2
+					continue
+				}
+			}
+		}
+		units = append(units, uint16(s[i]))
+	}
+	return string(utf16.Decode(units))
+}
+
+func isAlnum(c byte) bool {
+	return c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+// --- Quizzes ------------------------------------------------------------------
+
+// Quiz is the piece sequence of a quiz page, written in its comment as
+// "#Q=[hold](current)next", e.g. "#Q=[](T)SZLJOI".
+type Quiz struct {
+	Hold    Piece
+	Current Piece
+	Next    []Piece
+}
+
+// ParseQuiz reads the quiz in a page comment.
+func ParseQuiz(comment string) (Quiz, bool) {
+	rest, ok := strings.CutPrefix(comment, "#Q=")
+	if !ok {
+		return Quiz{}, false
+	}
+
+	var q Quiz
+	for _, slot := range []struct {
+		open, close byte
+		piece       *Piece
+	}{{'[', ']', &q.Hold}, {'(', ')', &q.Current}} {
+		if len(rest) == 0 || rest[0] != slot.open {
+			return Quiz{}, false
+		}
+		end := strings.IndexByte(rest, slot.close)
+		switch end {
+		case 1:
+		case 2:
+			if *slot.piece, ok = PieceFromLetter(rest[1:2]); !ok {
+				return Quiz{}, false
+			}
+		default:
+			return Quiz{}, false
+		}
+		rest = rest[end+1:]
+	}
+
+	// The queue runs up to the first character that is not a piece
+	for _, c := range rest {
+		p, ok := PieceFromLetter(string(c))
+		if !ok {
+			break
+		}
+		q.Next = append(q.Next, p)
+	}
+	return q, true
+}
+
+// String writes the quiz as a comment.
+func (q Quiz) String() string {
+	var b strings.Builder
+	b.WriteString("#Q=[")
+	if q.Hold != Empty {
+		b.WriteString(q.Hold.String())
+	}
+	b.WriteString("](")
+	if q.Current != Empty {
+		b.WriteString(q.Current.String())
+	}
+	b.WriteString(")")
+	for _, p := range q.Next {
+		b.WriteString(p.String())
+	}
+	return b.String()
+}
+
+// Use plays p from the quiz, holding if that is the only way to get it.
+func (q Quiz) Use(p Piece) (Quiz, bool) {
+	next := append([]Piece(nil), q.Next...)
+	pop := func() Piece {
+		if len(next) == 0 {
+			return Empty
+		}
+		first := next[0]
+		next = next[1:]
+		return first
+	}
+
+	current := q.Current
+	if current == Empty {
+		current = pop()
+	}
+	switch {
+	case current == p:
+		return Quiz{Hold: q.Hold, Current: pop(), Next: next}, true
+	case q.Hold == p:
+		return Quiz{Hold: current, Current: pop(), Next: next}, true
+	case q.Hold == Empty && len(next) > 0 && next[0] == p:
+		pop()
+		return Quiz{Hold: current, Current: pop(), Next: next}, true
+	}
+	return q, false
+}
+
+// nextComment is the comment the page after page shows unless it brings its
+// own: the same text, with a quiz moved on by the piece page locks.
+func nextComment(page Page) string {
+	q, ok := ParseQuiz(page.Comment)
+	if !ok || !page.Lock || page.Operation == nil {
+		return page.Comment
+	}
+	if next, ok := q.Use(page.Operation.Piece); ok {
+		return next.String()
+	}
+	return page.Comment
+}
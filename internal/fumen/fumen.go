@@ -0,0 +1,486 @@
+// Package fumen reads and writes fumen strings, the format the community
+// uses to paste boards into chat and into tools like the fumen editor: a list
+// of pages, each a board with an optional piece on it and a comment.
+//
+// Only version 1.15 ("v115@...") is supported, which is what every current
+// tool writes.
+package fumen
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Board size. Fumen keeps one more row under the floor that Rise pushes up.
+const (
+	Width  = 10
+	Height = 23
+)
+
+// Piece is a cell or piece kind, numbered the way fumen numbers them.
+type Piece int
+
+const (
+	Empty Piece = iota
+	I
+	L
+	O
+	Z
+	T
+	J
+	S
+	Gray
+)
+
+const pieceLetters = "_ILOZTJSX"
+
+// String returns the piece letter, "_" for an empty cell and "X" for gray.
+func (p Piece) String() string {
+	if p < Empty || p > Gray {
+		return "?"
+	}
+	return pieceLetters[p : p+1]
+}
+
+// PieceFromLetter parses a tetromino letter.
+func PieceFromLetter(letter string) (Piece, bool) {
+	if i := strings.Index(pieceLetters, strings.ToUpper(letter)); len(letter) == 1 && i >= int(I) && i <= int(S) {
+		return Piece(i), true
+	}
+	return Empty, false
+}
+
+// Orientations in guideline order, turning clockwise from spawn.
+const (
+	North = iota
+	East
+	South
+	West
+)
+
+// Field is a board indexed [x][y] with y=0 at the floor, like the game's.
+type Field [Width][Height]Piece
+
+// Operation is a piece on the board, located by its orientation and the cell
+// it rotates around in guideline (SRS) terms, the same way TBP bots do.
+type Operation struct {
+	Piece       Piece
+	Orientation int
+	X, Y        int
+}
+
+// minos are the cells of each piece facing north, relative to its rotation
+// center, with y pointing up.
+var minos = map[Piece][4][2]int{
+	I: {{-1, 0}, {0, 0}, {1, 0}, {2, 0}},
+	L: {{-1, 0}, {0, 0}, {1, 0}, {1, 1}},
+	O: {{0, 0}, {1, 0}, {0, 1}, {1, 1}},
+	Z: {{-1, 1}, {0, 1}, {0, 0}, {1, 0}},
+	T: {{-1, 0}, {0, 0}, {1, 0}, {0, 1}},
+	J: {{-1, 0}, {0, 0}, {1, 0}, {-1, 1}},
+	S: {{-1, 0}, {0, 0}, {0, 1}, {1, 1}},
+}
+
+// Cells returns the board cells the piece covers as x, y pairs.
+func (op Operation) Cells() [4][2]int {
+	var cells [4][2]int
+	for i, m := range minos[op.Piece] {
+		// Rotate clockwise orientation times: (x, y) -> (y, -x)
+		for r := 0; r < op.Orientation%4; r++ {
+			m = [2]int{m[1], -m[0]}
+		}
+		cells[i] = [2]int{op.X + m[0], op.Y + m[1]}
+	}
+	return cells
+}
+
+// Page is one frame of a fumen.
+type Page struct {
+	Field     Field
+	Garbage   [Width]Piece // Row under the floor
+	Operation *Operation   // Piece on the board, nil for none
+	Comment   string
+
+	Lock     bool // Place the piece and clear rows before the next page
+	Rise     bool // ... then push the garbage row up into the board
+	Mirror   bool // ... then mirror the board
+	Colorize bool // Guideline colors, set on the first page
+}
+
+// --- Decoding -----------------------------------------------------------------
+
+// Decode parses a fumen string. Anything before the version tag is ignored,
+// so whole URLs like https://fumen.zui.jp/?v115@... work too.
+func Decode(s string) ([]Page, error) {
+	s = strings.Join(strings.Fields(s), "")
+	at := strings.Index(s, "115@")
+	if at < 1 || !strings.ContainsRune("vmd", rune(s[at-1])) {
+		if strings.Contains(s, "110@") {
+			return nil, fmt.Errorf("fumen v110 is not supported, re-save it as v115")
+		}
+		return nil, fmt.Errorf("not a v115 fumen")
+	}
+	r, err := newReader(strings.ReplaceAll(s[at+4:], "?", ""))
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		pages   []Page
+		prev    board
+		repeat  int
+		comment string
+	)
+	for !r.done() {
+		cur := prev
+		if repeat > 0 {
+			repeat--
+		} else if repeat, err = r.field(&prev, &cur); err != nil {
+			return nil, err
+		}
+
+		page := Page{}
+		cur.unpack(&page)
+
+		v, err := r.poll(3)
+		if err != nil {
+			return nil, err
+		}
+		withComment, err := decodeAction(v, &page)
+		if err != nil {
+			return nil, err
+		}
+		if withComment {
+			if comment, err = r.comment(); err != nil {
+				return nil, err
+			}
+		}
+		page.Comment = comment
+		pages = append(pages, page)
+
+		prev = cur.after(page)
+		comment = nextComment(page)
+	}
+	if len(pages) == 0 {
+		return nil, fmt.Errorf("fumen has no pages")
+	}
+	return pages, nil
+}
+
+// decodeAction unpacks the piece and flags of a page and reports whether a
+// new comment follows.
+func decodeAction(v int, page *Page) (withComment bool, err error) {
+	piece := Piece(v % 8)
+	rotation := v / 8 % 4
+	pos := v / 32 % cells
+	flags := v / (32 * cells)
+
+	page.Rise = flags&1 != 0
+	page.Mirror = flags&2 != 0
+	page.Colorize = flags&4 != 0
+	page.Lock = flags&16 == 0
+	withComment = flags&8 != 0
+
+	if piece == Empty {
+		return withComment, nil
+	}
+	if piece == Gray {
+		return false, fmt.Errorf("gray piece on the board")
+	}
+	op := &Operation{
+		Piece:       piece,
+		Orientation: fromRotation[rotation],
+		X:           pos % Width,
+		Y:           Height - pos/Width - 1,
+	}
+	d := centerShift(op.Piece, op.Orientation)
+	op.X, op.Y = op.X-d[0], op.Y-d[1]
+
+	for _, c := range op.Cells() {
+		if c[0] < 0 || c[0] >= Width || c[1] < 0 || c[1] >= Height {
+			return false, fmt.Errorf("%v piece sticks out of the board", op.Piece)
+		}
+	}
+	page.Operation = op
+	return withComment, nil
+}
+
+// --- Encoding -----------------------------------------------------------------
+
+// Encode writes pages as a v115 fumen string.
+func Encode(pages []Page) string {
+	var (
+		w          writer
+		prev       board
+		comment    string
+		repeatSlot = -1 // Where the count of unchanged pages goes, if open
+	)
+	for _, page := range pages {
+		cur := pack(page)
+		if runs := diffRuns(&prev, &cur); len(runs) > 1 || runs[0] != unchanged {
+			for _, run := range runs {
+				w.push(run, 2)
+			}
+			repeatSlot = -1
+		} else if repeatSlot < 0 || w[repeatSlot] == len(alphabet)-1 {
+			w.push(unchanged, 2)
+			repeatSlot = len(w)
+			w.push(0, 1)
+		} else {
+			w[repeatSlot]++
+		}
+
+		withComment := page.Comment != comment
+		w.push(encodeAction(page, withComment), 3)
+		if withComment {
+			w.comment(page.Comment)
+		}
+
+		prev = cur.after(page)
+		comment = nextComment(page)
+	}
+
+	// Editors split long strings with a ? every 47 characters after the
+	// first 42
+	data := w.String()
+	var out strings.Builder
+	out.WriteString("v115@")
+	for i := 0; i < len(data); {
+		n := 47
+		if i == 0 {
+			n = 42
+		} else {
+			out.WriteByte('?')
+		}
+		out.WriteString(data[i:min(i+n, len(data))])
+		i += n
+	}
+	return out.String()
+}
+
+// encodeAction packs the piece and flags of a page.
+func encodeAction(page Page, withComment bool) int {
+	piece, rotation, pos := 0, 0, 0
+	if op := page.Operation; op != nil && op.Piece >= I && op.Piece <= S {
+		d := centerShift(op.Piece, op.Orientation)
+		piece = int(op.Piece)
+		rotation = toRotation[op.Orientation%4]
+		pos = (Height-(op.Y+d[1])-1)*Width + op.X + d[0]
+	}
+
+	flags := 0
+	for bit, on := range []bool{page.Rise, page.Mirror, page.Colorize, withComment, !page.Lock} {
+		if on {
+			flags |= 1 << bit
+		}
+	}
+	return ((flags*cells+pos)*4+rotation)*8 + piece
+}
+
+// --- Boards -------------------------------------------------------------------
+
+// cells is the size of a packed board: the playfield plus the garbage row.
+const cells = (Height + 1) * Width
+
+// unchanged is the run that says "same board as the page before".
+const unchanged = 8*cells + cells - 1
+
+// board is a page's cells in fumen order: top row first, garbage row last.
+type board [cells]Piece
+
+func pack(page Page) board {
+	var b board
+	for x := 0; x < Width; x++ {
+		for y := 0; y < Height; y++ {
+			b[(Height-1-y)*Width+x] = page.Field[x][y]
+		}
+		b[Height*Width+x] = page.Garbage[x]
+	}
+	return b
+}
+
+func (b *board) unpack(page *Page) {
+	for x := 0; x < Width; x++ {
+		for y := 0; y < Height; y++ {
+			page.Field[x][y] = b[(Height-1-y)*Width+x]
+		}
+		page.Garbage[x] = b[Height*Width+x]
+	}
+}
+
+// after is the board the next page starts from: the piece placed and full
+// rows cleared if the page locks, otherwise the same board.
+func (b board) after(page Page) board {
+	if !page.Lock {
+		return b
+	}
+
+	var next Page
+	b.unpack(&next)
+	if op := page.Operation; op != nil {
+		for _, c := range op.Cells() {
+			if c[0] >= 0 && c[0] < Width && c[1] >= 0 && c[1] < Height {
+				next.Field[c[0]][c[1]] = op.Piece
+			}
+		}
+	}
+
+	// Clear full rows, dropping everything above
+	y := 0
+	for row := 0; row < Height; row++ {
+		full := true
+		for x := 0; x < Width; x++ {
+			full = full && next.Field[x][row] != Empty
+		}
+		if full {
+			continue
+		}
+		for x := 0; x < Width; x++ {
+			next.Field[x][y] = next.Field[x][row]
+		}
+		y++
+	}
+	for ; y < Height; y++ {
+		for x := 0; x < Width; x++ {
+			next.Field[x][y] = Empty
+		}
+	}
+
+	if page.Rise {
+		for x := 0; x < Width; x++ {
+			copy(next.Field[x][1:], next.Field[x][:Height-1])
+			next.Field[x][0], next.Garbage[x] = next.Garbage[x], Empty
+		}
+	}
+	if page.Mirror {
+		for x := 0; x < Width/2; x++ {
+			next.Field[x], next.Field[Width-1-x] = next.Field[Width-1-x], next.Field[x]
+		}
+	}
+	return pack(next)
+}
+
+// diffRuns run-length encodes the change from prev to cur. Each run is
+// (difference+8)*cells + length-1.
+func diffRuns(prev, cur *board) []int {
+	var runs []int
+	start := 0
+	for i := 1; i <= cells; i++ {
+		if i < cells && cur[i]-prev[i] == cur[start]-prev[start] {
+			continue
+		}
+		runs = append(runs, int(cur[start]-prev[start]+8)*cells+i-start-1)
+		start = i
+	}
+	return runs
+}
+
+// --- Pieces -------------------------------------------------------------------
+
+// Fumen numbers rotations reverse, right, spawn, left.
+var (
+	fromRotation = [4]int{South, East, North, West}
+	toRotation   = [4]int{North: 2, East: 1, South: 0, West: 3}
+)
+
+// centerShift is where fumen puts the center of a piece relative to the
+// guideline one. Fumen keeps one center cell for pieces that look the same
+// in two orientations (O, I, S, Z) while SRS moves it around as they turn.
+func centerShift(p Piece, orientation int) [2]int {
+	switch {
+	case p == O && orientation == North, p == S && orientation == North, p == Z && orientation == North:
+		return [2]int{0, 1}
+	case p == O && orientation == South, p == I && orientation == South, p == Z && orientation == West:
+		return [2]int{-1, 0}
+	case p == O && orientation == West:
+		return [2]int{-1, 1}
+	case p == I && orientation == West:
+		return [2]int{0, 1}
+	case p == S && orientation == East:
+		return [2]int{1, 0}
+	}
+	return [2]int{0, 0}
+}
+
+// --- Base64 -------------------------------------------------------------------
+
+const alphabet = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789+/"
+
+// reader hands out the numbers packed in a fumen, least significant digit
+// first.
+type reader struct {
+	digits []int
+	pos    int
+}
+
+func newReader(s string) (*reader, error) {
+	r := &reader{digits: make([]int, len(s))}
+	for i, c := range s {
+		d := strings.IndexRune(alphabet, c)
+		if d < 0 {
+			return nil, fmt.Errorf("bad character %q in fumen", c)
+		}
+		r.digits[i] = d
+	}
+	return r, nil
+}
+
+func (r *reader) done() bool {
+	return r.pos >= len(r.digits)
+}
+
+func (r *reader) poll(n int) (int, error) {
+	if r.pos+n > len(r.digits) {
+		return 0, fmt.Errorf("fumen ends too early")
+	}
+	v := 0
+	for i := n - 1; i >= 0; i-- {
+		v = v*len(alphabet) + r.digits[r.pos+i]
+	}
+	r.pos += n
+	return v, nil
+}
+
+// field reads the runs that turn prev into cur. A board identical to prev is
+// followed by how many more pages repeat it.
+func (r *reader) field(prev, cur *board) (repeat int, err error) {
+	for i := 0; i < cells; {
+		v, err := r.poll(2)
+		if err != nil {
+			return 0, err
+		}
+		diff, n := v/cells-8, v%cells+1
+		if v == unchanged {
+			return r.poll(1)
+		}
+		if i+n > cells {
+			return 0, fmt.Errorf("board data overflows")
+		}
+		for ; n > 0; n, i = n-1, i+1 {
+			c := prev[i] + Piece(diff)
+			if c < Empty || c > Gray {
+				return 0, fmt.Errorf("bad cell value %d", c)
+			}
+			cur[i] = c
+		}
+	}
+	return 0, nil
+}
+
+// writer collects base64 digits.
+type writer []int
+
+func (w *writer) push(v, n int) {
+	for i := 0; i < n; i++ {
+		*w = append(*w, v%len(alphabet))
+		v /= len(alphabet)
+	}
+}
+
+func (w writer) String() string {
+	b := make([]byte, len(w))
+	for i, d := range w {
+		b[i] = alphabet[d]
+	}
+	return string(b)
+}
@@ -0,0 +1,207 @@
+package game
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"time"
+
+	"gotetris/internal/audio"
+)
+
+// --- Accessibility ------------------------------------------------------------
+//
+// With announcements on, the game describes what happens in plain words: the
+// piece that spawned, the columns it covers and the row it would land on as
+// it moves and turns, where it landed, the lines it cleared and a warning
+// when the stack gets close to the top. The latest line sits on a line of
+// its own under the board with the cursor at its end, where terminal screen
+// readers look; every line can also go to a file or a named pipe. Audio cues
+// follow the piece by ear instead, with tones panned to its column. Rows and
+// columns are counted from 1, rows from the bottom.
+
+// dangerRows is how close to the top of the visible field the stack gets
+// before the announcements warn about it.
+const dangerRows = 4
+
+// announceBacklog is how many lines may wait for a slow file or pipe before
+// new ones are dropped, so a reader that stalls never stalls the game.
+const announceBacklog = 64
+
+// Facing of each rotation state, as announced.
+var facings = [4]string{"up", "right", "down", "left"}
+
+// SetAnnouncements also writes every announcement to w, a line at a time,
+// whether or not they are shown under the board. Call it before Run.
+func (g *Game) SetAnnouncements(w io.Writer) {
+	lines := make(chan string, announceBacklog)
+	g.announceOut = lines
+	go func() {
+		bw := bufio.NewWriter(w)
+		for line := range lines {
+			bw.WriteString(line + "\n")
+			bw.Flush()
+		}
+	}()
+}
+
+// SetCues plays the audio cues, when the settings ask for them, on c.
+// Call it before Run.
+func (g *Game) SetCues(c *audio.Cues) {
+	g.cues = c
+}
+
+// announce shows line under the board and writes it out.
+func (g *Game) announce(line string) {
+	if g.settings.Access.Announce {
+		g.announcement = line
+	}
+	if g.announceOut != nil {
+		select {
+		case g.announceOut <- line:
+		default:
+		}
+	}
+}
+
+// announcing reports whether anyone is listening to announcements.
+func (g *Game) announcing() bool {
+	return g.settings.Access.Announce || g.announceOut != nil
+}
+
+// cue plays a tone panned to where cells are, if cues are on.
+func (g *Game) cue(freq float64, cells [4]Point, d time.Duration) {
+	if !g.settings.Access.Cues || g.cues == nil {
+		return
+	}
+	sum := 0
+	for _, c := range cells {
+		sum += c.X
+	}
+	center := float64(PlayWidth-1) / 2
+	g.cues.Tone(freq, (float64(sum)/4-center)/center, d)
+}
+
+// centered is a cell in the middle of the field, for cues about the whole
+// board.
+var centered = [4]Point{{4, 0}, {5, 0}, {4, 0}, {5, 0}}
+
+// trackAccess announces the game and plays its cues.
+func (g *Game) trackAccess(e Event) {
+	if !g.announcing() && !g.settings.Access.Cues {
+		return
+	}
+	switch ev := e.(type) {
+	case PieceSpawnedEvent:
+		line := fmt.Sprintf("%s piece, %s", PieceLetter(ev.Piece), g.whereCurrent())
+		if warning := g.checkDanger(); warning != "" {
+			line = warning + ". " + line
+			g.cue(880, centered, 200*time.Millisecond)
+		}
+		g.announce(line)
+		g.cue(660, g.Current.placement().Cells(), 60*time.Millisecond)
+	case MovedEvent:
+		if ev.DX == 0 || g.Current == nil {
+			return
+		}
+		g.announce(capitalize(g.whereCurrent()))
+		g.cue(440, g.Current.placement().Cells(), 40*time.Millisecond)
+	case RotatedEvent:
+		g.announce(fmt.Sprintf("Facing %s, %s", facings[ev.To], g.whereCurrent()))
+		g.cue(550, g.Current.placement().Cells(), 40*time.Millisecond)
+	case HeldEvent:
+		if g.Current != nil {
+			g.announce(fmt.Sprintf("Held %s, %s piece, %s", PieceLetter(ev.Piece), PieceLetter(g.Current.ID), g.whereCurrent()))
+		}
+	case LockedEvent:
+		low := ev.Blocks[0].Y
+		for _, b := range ev.Blocks {
+			low = min(low, b.Y)
+		}
+		g.announce(fmt.Sprintf("Landed on row %d", low+1))
+		g.cue(220, ev.Blocks, 80*time.Millisecond)
+	case LinesClearedEvent:
+		if ev.Count == 0 {
+			return
+		}
+		g.announce(clearAnnouncement(ev))
+		g.cue(440+110*float64(ev.Count), centered, 150*time.Millisecond)
+	case GarbageRisenEvent:
+		g.announce(fmt.Sprintf("%d garbage rows rose", ev.Lines))
+		g.cue(165, centered, 150*time.Millisecond)
+	case LevelUpEvent:
+		g.announce(fmt.Sprintf("Level %d", ev.Level))
+	case PausedEvent:
+		if ev.Paused {
+			g.announce("Paused")
+		} else {
+			g.announce("Resumed")
+		}
+	case GameOverEvent:
+		g.dangerous = false
+		g.announce(fmt.Sprintf("Game over, %s. Score %d, %d lines", ev.Reason, ev.Score, ev.Lines))
+	}
+}
+
+// whereCurrent describes where the piece in play is and would land.
+func (g *Game) whereCurrent() string {
+	p := g.Current.placement()
+	cells := p.Cells()
+	left, right := cells[0].X, cells[0].X
+	for _, c := range cells {
+		left, right = min(left, c.X), max(right, c.X)
+	}
+	landing := Landing(&g.Playfield, p).Cells()
+	low := landing[0].Y
+	for _, c := range landing {
+		low = min(low, c.Y)
+	}
+	cols := fmt.Sprintf("columns %d to %d", left+1, right+1)
+	if left == right {
+		cols = fmt.Sprintf("column %d", left+1)
+	}
+	return fmt.Sprintf("%s, lands on row %d", cols, low+1)
+}
+
+// checkDanger warns when the stack comes within dangerRows of the top, or
+// grows while there, and gives the all clear once it is back down.
+func (g *Game) checkDanger() string {
+	_, height := boardShape(&g.Playfield)
+	was := g.dangerous
+	g.dangerous = height > VisibleHeight-dangerRows
+	switch {
+	case g.dangerous && (!was || height > g.dangerHeight):
+		g.dangerHeight = height
+		return fmt.Sprintf("Danger, stack at row %d", height)
+	case was && !g.dangerous:
+		return fmt.Sprintf("Out of danger, stack at row %d", height)
+	}
+	if g.dangerous {
+		g.dangerHeight = height
+	}
+	return ""
+}
+
+// clearAnnouncement is how a line clear is announced, e.g. "T-spin double".
+func clearAnnouncement(ev LinesClearedEvent) string {
+	spin := NoTSpin
+	switch {
+	case ev.Mini:
+		spin = MiniTSpin
+	case ev.TSpin:
+		spin = FullTSpin
+	}
+	name := clearName(ev.Count, spin)
+	if ev.PerfectClear {
+		name += ", perfect clear"
+	}
+	return name
+}
+
+// capitalize upper-cases the first letter of an ASCII line.
+func capitalize(s string) string {
+	if s == "" || s[0] < 'a' || s[0] > 'z' {
+		return s
+	}
+	return string(s[0]-'a'+'A') + s[1:]
+}
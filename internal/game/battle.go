@@ -0,0 +1,266 @@
+package game
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// --- Versus Battles -----------------------------------------------------------
+//
+// Versus pits the player against a bot. The bot plays a game of its own,
+// without a screen, stepped by the player's loop right after the player's
+// frame, so pausing stops both and nothing needs a lock. Both games get the
+// same pieces. Clears send garbage to the other side under the guideline
+// attack table; the first to top out loses the round, and the first to win
+// more than half of the rounds wins the match. The player's replay records
+// the garbage that came in, so it plays back without the bot.
+
+// Difficulty is how well the bot plays.
+type Difficulty struct {
+	Name        string
+	Description string
+	PPS         float64 // Pieces it places a second
+	Depth       int     // Pieces it looks at before it picks, the one in play included
+	Mistakes    float64 // Chance it makes a careless placement instead of its best
+}
+
+// Difficulties lists the bot's difficulties, easiest first.
+var Difficulties = []Difficulty{
+	{Name: "easy", Description: "Slow, short-sighted and careless", PPS: 0.8, Depth: 1, Mistakes: 0.3},
+	{Name: "normal", Description: "Steady, with a slip now and then", PPS: 1.4, Depth: 1, Mistakes: 0.1},
+	{Name: "hard", Description: "Quick, and plans a piece ahead", PPS: 2.2, Depth: 2, Mistakes: 0.03},
+	{Name: "expert", Description: "Fast, and plans two pieces ahead", PPS: 3.5, Depth: 3},
+}
+
+// DifficultyByName looks up a difficulty, ignoring case.
+func DifficultyByName(name string) (Difficulty, error) {
+	for _, d := range Difficulties {
+		if strings.EqualFold(d.Name, name) {
+			return d, nil
+		}
+	}
+	names := make([]string, len(Difficulties))
+	for i, d := range Difficulties {
+		names[i] = d.Name
+	}
+	return Difficulty{}, fmt.Errorf("unknown difficulty %q (available: %s)", name, strings.Join(names, ", "))
+}
+
+// BestOfChoices are the match lengths offered.
+var BestOfChoices = []int{1, 3, 5, 7}
+
+// ReasonOpponentOut is reported by GameOverEvent when the player won the
+// round.
+const ReasonOpponentOut = "opponent topped out"
+
+// SetBattleBot makes Versus available, with bots made by newBot for each
+// round. Call it before Run.
+func (g *Game) SetBattleBot(newBot func(Difficulty) Bot) {
+	g.battleBot = newBot
+}
+
+// battleRun is a versus match in progress.
+type battleRun struct {
+	difficulty Difficulty
+	bestOf     int
+	round      int   // Round being played, from 1
+	wins       int   // Rounds the player won
+	losses     int   // Rounds the bot won
+	won        bool  // The player won the last round to end
+	opponent   *Game // The bot's game this round
+}
+
+// decided reports whether either side has won the match.
+func (b *battleRun) decided() bool {
+	return b.wins > b.bestOf/2 || b.losses > b.bestOf/2
+}
+
+// startBattle starts the next round of the match, or a new match when the
+// last one is decided. It runs before the player's game draws anything
+// from the randomizer, so the bot's game can start from the same state.
+func (g *Game) startBattle() {
+	if !g.Mode.Versus || g.battleBot == nil || g.puzzle != nil {
+		g.battle = nil
+		return
+	}
+	if g.rng == nil {
+		g.SetSeed(rand.Uint64())
+	}
+	b := g.battle
+	if b == nil || b.decided() {
+		d, err := DifficultyByName(g.settings.Versus.Difficulty)
+		if err != nil {
+			d = Difficulties[1]
+		}
+		b = &battleRun{difficulty: d, bestOf: max(1, g.settings.Versus.BestOf)}
+		g.battle = b
+	}
+	b.round++
+
+	// The bot's game: the player's pieces and colors, none of the extras
+	opp := NewGame(nil, nil)
+	opp.SetMode(g.Mode)
+	opp.settings.Visuals = g.settings.Visuals
+	opp.settings.Controls.Mouse = false
+	opp.SetSeed(g.seed)
+	if state, err := g.pcg.MarshalBinary(); err == nil {
+		opp.pcg.UnmarshalBinary(state)
+	}
+	opp.SetAutoplay(g.battleBot(b.difficulty), b.difficulty.PPS)
+	opp.autoplay.async = g.app != nil
+	opp.Subscribe(func(e Event) { g.trackOpponent(opp, e) })
+	b.opponent = opp
+	opp.StartGame()
+}
+
+// stepBattle plays the bot's frame after the player's.
+func (g *Game) stepBattle() {
+	if b := g.battle; b != nil && g.State == Playing && b.opponent.State == Playing {
+		b.opponent.step()
+	}
+}
+
+// trackBattle sends the player's attacks to the bot and keeps the match
+// score.
+func (g *Game) trackBattle(e Event) {
+	b := g.battle
+	if b == nil {
+		return
+	}
+	switch ev := e.(type) {
+	case AttackEvent:
+		b.opponent.ReceiveGarbage(ev.Sent)
+	case GameOverEvent:
+		b.won = ev.Reason == ReasonOpponentOut
+		if b.won {
+			b.wins++
+		} else {
+			b.losses++
+		}
+	}
+}
+
+// trackOpponent sends the bot's attacks to the player and ends the round
+// when the bot tops out.
+func (g *Game) trackOpponent(opp *Game, e Event) {
+	if g.battle == nil || g.battle.opponent != opp {
+		return // A round that is over
+	}
+	switch ev := e.(type) {
+	case AttackEvent:
+		g.ReceiveGarbage(ev.Sent)
+	case GameOverEvent:
+		g.endGame(ReasonOpponentOut)
+	}
+}
+
+// BattleStatus is how a versus match stands.
+type BattleStatus struct {
+	Difficulty string
+	BestOf     int
+	Round      int  // Round being played, from 1
+	Wins       int  // Rounds the player won
+	Losses     int  // Rounds the bot won
+	Won        bool // The player won the last round to end
+	Opponent   *Snapshot
+}
+
+// Decided reports whether either side has won the match.
+func (s *BattleStatus) Decided() bool {
+	return s.Wins > s.BestOf/2 || s.Losses > s.BestOf/2
+}
+
+// battleStatus is the readout of the match, nil outside one.
+func (g *Game) battleStatus() *BattleStatus {
+	b := g.battle
+	if b == nil {
+		return nil
+	}
+	opp := b.opponent.snapshot()
+	opp.Player = "Bot, " + b.difficulty.Name
+	return &BattleStatus{
+		Difficulty: b.difficulty.Name,
+		BestOf:     b.bestOf,
+		Round:      b.round,
+		Wins:       b.wins,
+		Losses:     b.losses,
+		Won:        b.won,
+		Opponent:   opp,
+	}
+}
+
+// opponentSource is the bot's game as a Source, out of the player's
+// snapshots.
+type opponentSource struct {
+	src Source
+}
+
+// Snapshot returns the bot's game as of the player's last snapshot.
+func (o opponentSource) Snapshot() *Snapshot {
+	if view := o.src.Snapshot(); view != nil && view.Battle != nil {
+		return view.Battle.Opponent
+	}
+	return nil
+}
+
+// --- Versus Page --------------------------------------------------------------
+
+// addVersus adds the page a versus match is set up on, if there is a bot to
+// play against.
+func (ui *screens) addVersus() {
+	g := ui.g
+	if g.battleBot == nil {
+		return
+	}
+	s := &ui.settings
+	ready := false // Building the drop-downs reports their first choice
+	changed := func() {
+		if ready {
+			s := *s
+			g.request(func() { g.changeSettings(s) })
+		}
+	}
+
+	form := tview.NewForm().SetItemPadding(1)
+	about := tview.NewTextView().SetTextColor(tcell.ColorGray)
+	var names []string
+	difficulty := 0
+	for i, d := range Difficulties {
+		names = append(names, d.Name)
+		if strings.EqualFold(d.Name, s.Versus.Difficulty) {
+			difficulty = i
+		}
+	}
+	form.AddDropDown("Bot", names, difficulty, func(_ string, i int) {
+		d := Difficulties[i]
+		about.SetText(fmt.Sprintf("%s: %.1f pieces a second", d.Description, d.PPS))
+		s.Versus.Difficulty = d.Name
+		changed()
+	})
+	var lengths []string
+	bestOf := 0
+	for i, n := range BestOfChoices {
+		lengths = append(lengths, fmt.Sprint(n))
+		if n == s.Versus.BestOf {
+			bestOf = i
+		}
+	}
+	form.AddDropDown("Best of", lengths, bestOf, func(_ string, i int) {
+		s.Versus.BestOf = BestOfChoices[i]
+		changed()
+	})
+	form.AddButton("Play", func() { g.request(func() { g.startMode(Versus) }) })
+	form.AddButton("Back", ui.back)
+	form.SetCancelFunc(ui.back)
+	form.SetFieldBackgroundColor(tcell.ColorNavy).SetButtonBackgroundColor(tcell.ColorNavy)
+	ready = true
+
+	body := tview.NewFlex().SetDirection(tview.FlexRow)
+	body.AddItem(form, 0, 1, true)
+	body.AddItem(about, 2, 0, false)
+	ui.add(pageVersus, "VERSUS", body, form, "TAB move • ENTER/SPACE change • ESC back")
+}
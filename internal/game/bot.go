@@ -0,0 +1,212 @@
+package game
+
+import "time"
+
+// --- Bots ---------------------------------------------------------------------
+
+// Position is everything a bot gets to see when it picks a placement.
+type Position struct {
+	Playfield Field
+	Current   Placement
+	Queue     []PieceID
+	Hold      PieceID // 0 when the hold slot is empty
+	CanHold   bool    // Whether the current piece may be swapped out
+	Combo     int
+	B2B       bool
+	Level     int
+}
+
+// Alternative is the piece a bot gets by holding: the held one, or the next
+// in the queue when the slot is empty. ok is false when holding is not an
+// option.
+func (p Position) Alternative() (id PieceID, ok bool) {
+	switch {
+	case !p.CanHold:
+		return 0, false
+	case p.Hold != 0:
+		return p.Hold, true
+	case len(p.Queue) > 0:
+		return p.Queue[0], true
+	}
+	return 0, false
+}
+
+// Bot picks where the active piece should lock. A placement of the
+// Alternative piece means "hold first". Suggest may take a while
+// (external engines think for a bit), so a game on screen never calls it on
+// the loop goroutine. ok is false when the bot has no idea, in which case the piece is
+// simply hard dropped.
+type Bot interface {
+	Suggest(pos Position) (p Placement, ok bool)
+}
+
+// position captures the current game for a bot.
+func (g *Game) position() Position {
+	pos := Position{
+		Playfield: g.Playfield,
+		Queue:     append([]PieceID(nil), g.NextQueue...),
+		Hold:      g.Hold,
+		CanHold:   g.CanHold(),
+		Combo:     g.Combo,
+		B2B:       g.B2B,
+		Level:     g.Level,
+	}
+	if g.Current != nil {
+		pos.Current = g.Current.placement()
+	}
+	return pos
+}
+
+// placement describes the piece as a Placement for the board helpers.
+func (p *Piece) placement() Placement {
+	return Placement{Piece: p.ID, Rotation: p.RotationState, Position: p.Position}
+}
+
+// --- Autoplay -----------------------------------------------------------------
+
+// autoplayRestartFrames is how long the final board stays up before a demo
+// game starts over (3 seconds at 60 Hz).
+const autoplayRestartFrames = 180
+
+// suggestion is a bot answer for one specific piece.
+type suggestion struct {
+	piece     *Piece
+	placement Placement
+	ok        bool
+}
+
+// autoplayer drives pieces with a Bot through the same actions the keyboard
+// produces. It is stepped once per tick from the game loop.
+type autoplayer struct {
+	bot            Bot
+	framesPerPiece int  // Throttle; 0 means as fast as possible
+	async          bool // Ask the bot in the background; games stepped by hand wait for it
+
+	piece   *Piece     // Piece the current plan is for
+	target  *Placement // Where the bot wants it, nil while thinking
+	plan    []Action   // Remaining moves towards target
+	expect  Placement  // Where the piece should be if the plan holds
+	dropAt  int        // Earliest frame the piece may lock, by the throttle
+	nextAt  int        // Earliest frame for the next action
+	overAt  int        // Frame the last game ended on
+	results chan suggestion
+}
+
+// SetAutoplay hands the pieces to bot, which places at most pps pieces per
+// second (pps <= 0 removes the limit). Call it before Run. The game starts on
+// its own and restarts after a game over, for demos and screensavers.
+func (g *Game) SetAutoplay(bot Bot, pps float64) {
+	if bot == nil {
+		g.autoplay = nil
+		return
+	}
+
+	frames := 0
+	if pps > 0 {
+		frames = int(float64(time.Second/TickRate) / pps)
+	}
+	g.autoplay = &autoplayer{
+		bot:            bot,
+		framesPerPiece: frames,
+		async:          g.app != nil,
+		overAt:         -1,
+		results:        make(chan suggestion, 1),
+	}
+}
+
+// step advances the autoplayer by one frame.
+func (a *autoplayer) step(g *Game) {
+	switch g.State {
+	case MainMenu:
+		g.StartGame()
+		return
+	case GameOver:
+		if a.overAt < 0 {
+			a.overAt = g.frame
+		}
+		if g.frame-a.overAt >= autoplayRestartFrames {
+			a.overAt = -1
+			g.StartGame()
+		}
+		return
+	case Playing:
+	default:
+		return
+	}
+	if g.Current == nil {
+		return
+	}
+
+	// A new piece: ask the bot in the background so the loop keeps running
+	if g.Current != a.piece {
+		a.piece = g.Current
+		a.target = nil
+		a.plan = nil
+		a.dropAt = a.piece.spawnedAt + a.framesPerPiece
+		a.nextAt = g.frame
+
+		piece, pos, bot, results := a.piece, g.position(), a.bot, a.results
+		if !a.async {
+			// Headless games are stepped by hand, so wait for the answer
+			p, ok := bot.Suggest(pos)
+			results <- suggestion{piece: piece, placement: p, ok: ok}
+		} else {
+			go func() {
+				p, ok := bot.Suggest(pos)
+				results <- suggestion{piece: piece, placement: p, ok: ok}
+			}()
+		}
+	}
+
+	if a.target == nil {
+		select {
+		case s := <-a.results:
+			if s.piece != a.piece {
+				return // Answer for a piece that is already gone
+			}
+			if !s.ok {
+				s.placement = Landing(&g.Playfield, a.piece.placement())
+			}
+			a.target = &s.placement
+		default:
+			return
+		}
+	}
+
+	// Walk towards the target. If the piece is not where the plan expects it
+	// (gravity keeps pulling it down meanwhile), plan again from where it is.
+	for g.Current == a.piece && g.State == Playing && g.frame >= a.nextAt {
+		// The bot picked the other piece: swap it in and walk that one
+		if a.target.Piece != a.piece.ID && g.CanHold() {
+			g.act(ActionHold)
+			a.piece, a.plan = g.Current, nil
+			continue
+		}
+
+		if len(a.plan) == 0 || a.piece.placement() != a.expect {
+			path, ok := PathTo(&g.Playfield, a.piece.placement(), *a.target)
+			if !ok {
+				path = []Action{ActionHardDrop}
+			}
+			a.plan = path
+		}
+
+		// The drop ends the plan, and however few moves come before it, it
+		// waits out the piece's time from spawn
+		if len(a.plan) == 1 && g.frame < a.dropAt {
+			a.nextAt = a.dropAt
+			break
+		}
+
+		act := a.plan[0]
+		a.plan = a.plan[1:]
+		g.act(act)
+		a.expect = a.piece.placement()
+
+		if a.framesPerPiece > 0 && len(a.plan) > 0 {
+			// Spread the remaining moves so the drop lands on the deadline
+			left := a.dropAt - g.frame
+			a.nextAt = g.frame + max(1, left/len(a.plan))
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package game
+
+import "testing"
+
+// dropBot wants every piece right below where it spawns, so its plans are a
+// lone hard drop.
+type dropBot struct{}
+
+// Suggest implements Bot.
+func (dropBot) Suggest(pos Position) (Placement, bool) {
+	return Landing(&pos.Playfield, pos.Current), true
+}
+
+func TestAutoplayThrottle(t *testing.T) {
+	const pps = 2
+	perPiece := int(60 / pps)
+
+	for name, bot := range map[string]Bot{"drop": dropBot{}, "flat": flatBot{}} {
+		g := NewGame(nil, nil)
+		g.SetMode(Marathon)
+		g.SetSeed(5)
+		g.SetAutoplay(bot, pps)
+		var spawned, locked []int
+		g.Subscribe(func(e Event) {
+			switch e.(type) {
+			case PieceSpawnedEvent:
+				spawned = append(spawned, g.frame)
+			case LockedEvent:
+				locked = append(locked, g.frame)
+			}
+		})
+		for len(locked) < 10 && g.State != GameOver && g.frame < 60*60 {
+			g.Step()
+		}
+		if len(locked) < 10 {
+			t.Fatalf("%s: %d pieces in a minute", name, len(locked))
+		}
+
+		// Every piece gets its full share of time from spawn, no more
+		for i, at := range locked {
+			if d := at - spawned[i]; d != perPiece {
+				t.Errorf("%s: piece %d locked %d frames after it spawned, want %d", name, i+1, d, perPiece)
+			}
+		}
+	}
+}
+
+func TestHardDropResting(t *testing.T) {
+	// At 20G the piece is on the floor the frame it spawns
+	g := newTimedGame(MaxGravity, Field{}, O, O)
+	g.step(1)
+	var moved []MovedEvent
+	g.Subscribe(func(e Event) {
+		if ev, ok := e.(MovedEvent); ok {
+			moved = append(moved, ev)
+		}
+	})
+	score := g.Score
+
+	// The drop locks it where it is, without moving or scoring
+	g.applyAction(ActionHardDrop)
+	if len(g.locked) != 1 || g.locked[0] != 1 {
+		t.Fatalf("locked on frames %v, want 1", g.locked)
+	}
+	if len(moved) != 0 || g.Score != score {
+		t.Fatalf("a drop of no rows moved %v and scored %d", moved, g.Score-score)
+	}
+}
@@ -0,0 +1,58 @@
+package game
+
+import "time"
+
+// --- Clock --------------------------------------------------------------------
+//
+// The engine runs in fixed steps of one frame. On screen a Clock says when the
+// next frame is due; everything that happens over time (gravity, delays, the
+// game timer, bots) counts those frames, never the wall clock.
+
+// Clock delivers one tick per frame.
+type Clock interface {
+	Ticks() <-chan time.Time
+	Stop()
+}
+
+// realtimeClock ticks at TickRate.
+type realtimeClock struct {
+	ticker *time.Ticker
+}
+
+// NewRealtimeClock returns a clock that ticks 60 times a second.
+func NewRealtimeClock() Clock {
+	return realtimeClock{ticker: time.NewTicker(TickRate)}
+}
+
+func (c realtimeClock) Ticks() <-chan time.Time { return c.ticker.C }
+func (c realtimeClock) Stop()                   { c.ticker.Stop() }
+
+// ManualClock ticks only when told to, so a game on a (simulated) screen can
+// be stepped frame by frame.
+type ManualClock struct {
+	ticks chan time.Time
+	now   time.Time
+}
+
+// NewManualClock returns a clock that stands still until Advance.
+func NewManualClock() *ManualClock {
+	return &ManualClock{ticks: make(chan time.Time)}
+}
+
+// Advance runs frames frames. It returns once the game has taken the last
+// tick; the frame is done once Snapshot().Frame has caught up with it.
+func (c *ManualClock) Advance(frames int) {
+	for i := 0; i < frames; i++ {
+		c.now = c.now.Add(TickRate)
+		c.ticks <- c.now
+	}
+}
+
+func (c *ManualClock) Ticks() <-chan time.Time { return c.ticks }
+func (c *ManualClock) Stop()                   {}
+
+// SetClock paces the game on screen with c instead of the wall clock. Call
+// it before Run.
+func (g *Game) SetClock(c Clock) {
+	g.clock = c
+}
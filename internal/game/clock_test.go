@@ -0,0 +1,207 @@
+package game
+
+import (
+	"math"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+func TestGravityForLevel(t *testing.T) {
+	// A row a second at level 1, 0.793 seconds at level 2
+	if g := GravityForLevel(1); math.Abs(g-1.0/60) > 1e-12 {
+		t.Errorf("level 1: %v G, want 1/60", g)
+	}
+	if g := GravityForLevel(2); math.Abs(g-1/(0.793*60)) > 1e-12 {
+		t.Errorf("level 2: %v G, want %v", g, 1/(0.793*60))
+	}
+
+	// Faster every level, up to 20G from level 19 on
+	for level := 2; level <= 30; level++ {
+		if GravityForLevel(level) < GravityForLevel(level-1) {
+			t.Errorf("level %d is slower than level %d", level, level-1)
+		}
+	}
+	if g := GravityForLevel(18); g >= MaxGravity {
+		t.Errorf("level 18 is already 20G")
+	}
+	for _, level := range []int{19, 20, 50, 200} {
+		if g := GravityForLevel(level); g != MaxGravity {
+			t.Errorf("level %d: %v G, want 20G", level, g)
+		}
+	}
+}
+
+// timedGame steps a game frame by frame, noting the frame each event came
+// in on.
+type timedGame struct {
+	*Game
+	frame   int
+	spawned []int // Frames pieces appeared on
+	locked  []int // Frames pieces locked on
+	dropped []int // Frames gravity pulled the piece down on
+	cleared []int // Frames cleared rows collapsed on
+}
+
+// newTimedGame starts a puzzle with queue on field, in a mode with gravity
+// fixed at g and the default delays.
+func newTimedGame(gravity float64, field Field, queue ...PieceID) *timedGame {
+	g := &timedGame{Game: NewGame(nil, nil)}
+	g.SetMode(Mode{Name: "timed", Delays: DefaultDelays, Speed: []SpeedStep{{Level: 1, Gravity: gravity}}})
+	g.Subscribe(func(e Event) {
+		switch ev := e.(type) {
+		case PieceSpawnedEvent:
+			g.spawned = append(g.spawned, g.frame)
+		case LockedEvent:
+			g.locked = append(g.locked, g.frame)
+		case MovedEvent:
+			if ev.Drop == GravityDrop {
+				g.dropped = append(g.dropped, g.frame)
+			}
+		case LinesClearedEvent:
+			g.cleared = append(g.cleared, g.frame)
+		}
+	})
+	g.StartPuzzle(Puzzle{Playfield: field, Queue: queue, Goal: Goal{Kind: GoalPerfectClear, Pieces: len(queue)}})
+	return g
+}
+
+// step runs frames frames.
+func (g *timedGame) step(frames int) {
+	for i := 0; i < frames; i++ {
+		g.frame++
+		g.Step()
+	}
+}
+
+// filled is a field with the rows under height filled in columns from..to.
+func filled(height, from, to int) Field {
+	var f Field
+	for x := from; x <= to; x++ {
+		for y := 0; y < height; y++ {
+			f[x][y] = int(Garbage)
+		}
+	}
+	return f
+}
+
+func TestGravityLevelOne(t *testing.T) {
+	g := newTimedGame(GravityForLevel(1), Field{}, O, O)
+	g.step(600)
+	if len(g.dropped) != 10 {
+		t.Fatalf("fell %d rows in 10 seconds, want 10", len(g.dropped))
+	}
+	for i := 1; i < len(g.dropped); i++ {
+		if d := g.dropped[i] - g.dropped[i-1]; d != 60 {
+			t.Errorf("row %d fell %d frames after the last, want 60", i+1, d)
+		}
+	}
+}
+
+func TestTwentyG(t *testing.T) {
+	g := newTimedGame(MaxGravity, Field{}, O, O)
+	g.step(1)
+	if len(g.dropped) != 1 || !g.resting() {
+		t.Fatal("the piece did not reach the floor the frame it appeared")
+	}
+	for _, c := range g.Current.placement().Cells() {
+		if c.Y > 1 {
+			t.Fatalf("the piece stopped at row %d", c.Y)
+		}
+	}
+}
+
+func TestLockDelay(t *testing.T) {
+	lock := DefaultDelays.Lock
+
+	// Resting from the first frame, it locks on frame lock; sliding along
+	// the floor buys no time
+	g := newTimedGame(MaxGravity, Field{}, O, O)
+	g.step(10)
+	g.applyAction(ActionMoveRight)
+	g.step(lock)
+	if len(g.locked) != 1 || g.locked[0] != lock {
+		t.Fatalf("locked on frames %v, want %d", g.locked, lock)
+	}
+
+	// Stepping down off a ledge starts the count over
+	g = newTimedGame(MaxGravity, filled(2, 0, 5), O, O)
+	g.step(20)
+	for _, c := range g.Current.placement().Cells() {
+		if c.Y != 2 && c.Y != 3 {
+			t.Fatalf("the piece is not on the ledge: row %d", c.Y)
+		}
+	}
+	for i := 0; i < 3; i++ {
+		g.applyAction(ActionMoveRight)
+	}
+	g.step(lock + 1)
+	if len(g.locked) != 1 || g.locked[0] != 21+lock-1 {
+		t.Fatalf("locked on frames %v, want %d", g.locked, 21+lock-1)
+	}
+}
+
+func TestPhaseLengths(t *testing.T) {
+	d := DefaultDelays
+
+	// Without a clear the next piece comes after the entry delay
+	g := newTimedGame(MaxGravity, Field{}, O, O)
+	g.applyAction(ActionHardDrop)
+	g.step(d.Entry)
+	if len(g.spawned) != 2 || g.spawned[1] != d.Entry {
+		t.Fatalf("pieces appeared on frames %v, want 0 and %d", g.spawned, d.Entry)
+	}
+
+	// Cleared rows flash first, staying on the board until they collapse.
+	// A block left over keeps it from being the perfect clear that ends the
+	// puzzle.
+	field := filled(2, 2, 9)
+	field[9][2] = int(Garbage)
+	g = newTimedGame(MaxGravity, field, O, O)
+	for i := 0; i < 4; i++ {
+		g.applyAction(ActionMoveLeft)
+	}
+	g.applyAction(ActionHardDrop)
+	if g.Phase != PhaseLineClear || len(g.clearing) != 2 {
+		t.Fatalf("phase %v clearing %v after the clear, want the line clear delay", g.Phase, g.clearing)
+	}
+	g.step(d.LineClear - 1)
+	if g.Phase != PhaseLineClear || g.Playfield[9][1] == 0 {
+		t.Fatal("the rows collapsed before the line clear delay ran out")
+	}
+	g.step(1)
+	if g.Phase != PhaseEntry || g.Playfield[9][1] != 0 || g.Playfield[9][0] == 0 {
+		t.Fatalf("phase %v after the line clear delay, want the entry delay", g.Phase)
+	}
+	if len(g.cleared) != 1 || g.cleared[0] != d.LineClear {
+		t.Fatalf("rows cleared on frames %v, want %d", g.cleared, d.LineClear)
+	}
+	g.step(d.Entry)
+	if len(g.spawned) != 2 || g.spawned[1] != d.LineClear+d.Entry {
+		t.Fatalf("pieces appeared on frames %v, want 0 and %d", g.spawned, d.LineClear+d.Entry)
+	}
+}
+
+func TestManualClockPacesPlay(t *testing.T) {
+	s := runOnScreen(t, 100, 45)
+	s.g.request(func() { s.g.startMode(Marathon) })
+	start := s.waitFor("the game", func(v *Snapshot) bool { return v.State == Playing }, "")
+
+	// Nothing moves until the clock ticks, then a second of play is 60 of
+	// them, with the piece a row lower
+	if v := s.g.Snapshot(); v.Played != start.Played {
+		t.Fatal("the game ran without the clock")
+	}
+	v := s.advance(60)
+	if v.Played != start.Played+60 || v.Elapsed != start.Elapsed+60*TickRate {
+		t.Fatalf("played %d frames (%v) in 60 ticks", v.Played-start.Played, v.Elapsed-start.Elapsed)
+	}
+	if v.Current == nil || start.Current == nil || v.Current.Position.Y != start.Current.Position.Y-1 {
+		t.Fatal("the piece did not fall a row in a second")
+	}
+
+	s.key(tcell.KeyCtrlC, 0)
+	if err := <-s.done; err != nil {
+		t.Fatal(err)
+	}
+}
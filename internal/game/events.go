@@ -0,0 +1,215 @@
+package game
+
+import (
+	"sync"
+	"time"
+)
+
+// --- Game Events --------------------------------------------------------------
+
+// Event is something that happened inside the engine. Subscribers receive the
+// concrete event types below and pick out the ones they care about with a type
+// switch, so audio, stats, effects and external hooks never need to touch the
+// physics code.
+type Event interface {
+	isEvent()
+}
+
+// DropKind tells a MovedEvent apart by what caused the vertical movement.
+type DropKind int
+
+const (
+	NoDrop      DropKind = iota // Horizontal shift
+	SoftDrop                    // Player pressed down
+	HardDrop                    // Player slammed the piece down
+	GravityDrop                 // Gravity pulled the piece down
+)
+
+// PieceSpawnedEvent fires when a new piece enters the playfield.
+type PieceSpawnedEvent struct {
+	Piece    PieceID
+	Position Point
+}
+
+// MovedEvent fires when the active piece moved successfully.
+type MovedEvent struct {
+	Piece  PieceID
+	DX, DY int
+	Drop   DropKind
+}
+
+// RotatedEvent fires when the active piece rotated successfully.
+// Kick is the index of the offset test that made the rotation fit, 0 meaning
+// the piece rotated in place.
+type RotatedEvent struct {
+	Piece    PieceID
+	From, To int
+	Kick     int
+}
+
+// HeldEvent fires when the active piece went into the hold slot.
+type HeldEvent struct {
+	Piece PieceID
+}
+
+// LockedEvent fires when the active piece is painted into the playfield.
+type LockedEvent struct {
+	Piece    PieceID
+	Rotation int
+	Position Point
+	Blocks   [4]Point // Absolute playfield coordinates of the locked cells
+}
+
+// LinesClearedEvent fires after a lock that completed at least one row.
+type LinesClearedEvent struct {
+	Count        int
+	TSpin        bool
+	Mini         bool // The T-spin was a mini one
+	PerfectClear bool
+}
+
+// ComboChangedEvent fires whenever the combo counter changes, including when
+// it drops back to zero.
+type ComboChangedEvent struct {
+	Combo int
+}
+
+// B2BEvent fires when the back-to-back chain starts or breaks.
+type B2BEvent struct {
+	Active bool
+}
+
+// LevelUpEvent fires when enough lines were cleared to reach a new level.
+type LevelUpEvent struct {
+	Level int
+}
+
+// GameOverEvent fires once when the game ends.
+type GameOverEvent struct {
+	Mode    string // Mode played, "puzzle" for puzzles
+	Score   int
+	Lines   int
+	Level   int
+	Grade   string        // Master mode grade, empty in other modes
+	Elapsed time.Duration // Time played
+	Reason  string        // What ended the game, e.g. "block out"
+}
+
+// ScoredEvent fires after a lock that earned points, drop points included,
+// with what each award was for.
+type ScoredEvent struct {
+	Awards []Award
+	Score  int // Score after the awards
+}
+
+// AttackEvent fires after a clear that attacks, in a mode with garbage.
+// Canceled lines went against incoming garbage; Sent are left for the
+// opponent.
+type AttackEvent struct {
+	Lines    int
+	Canceled int
+	Sent     int
+}
+
+// GarbageReceivedEvent fires when an opponent's garbage is queued.
+type GarbageReceivedEvent struct {
+	Lines   int
+	Waiting int // Lines waiting to rise, these included
+}
+
+// GarbageRisenEvent fires when garbage rows rose from the bottom.
+type GarbageRisenEvent struct {
+	Lines int
+}
+
+// PausedEvent fires when the game is paused or resumed.
+type PausedEvent struct {
+	Paused bool
+}
+
+// SettingsChangedEvent fires when the player changes the settings.
+type SettingsChangedEvent struct {
+	Settings Settings
+}
+
+func (PieceSpawnedEvent) isEvent()    {}
+func (MovedEvent) isEvent()           {}
+func (RotatedEvent) isEvent()         {}
+func (HeldEvent) isEvent()            {}
+func (LockedEvent) isEvent()          {}
+func (LinesClearedEvent) isEvent()    {}
+func (ComboChangedEvent) isEvent()    {}
+func (B2BEvent) isEvent()             {}
+func (LevelUpEvent) isEvent()         {}
+func (GameOverEvent) isEvent()        {}
+func (ScoredEvent) isEvent()          {}
+func (AttackEvent) isEvent()          {}
+func (GarbageReceivedEvent) isEvent() {}
+func (GarbageRisenEvent) isEvent()    {}
+func (PausedEvent) isEvent()          {}
+func (SettingsChangedEvent) isEvent() {}
+
+// Reasons reported by GameOverEvent.
+const (
+	ReasonBlockOut = "block out" // The next piece could not spawn
+	ReasonLockOut  = "lock out"  // A piece ended up outside the playfield
+)
+
+// --- Subscriptions ------------------------------------------------------------
+
+// EventHandler receives engine events. Handlers run synchronously on the game
+// loop goroutine, so they must return quickly and must not block.
+type EventHandler func(Event)
+
+// eventBus keeps the list of subscribers. It is safe to subscribe and
+// unsubscribe from any goroutine.
+type eventBus struct {
+	mu       sync.Mutex
+	nextID   int
+	handlers map[int]EventHandler
+	order    []int // Subscription order so handlers run predictably
+}
+
+// Subscribe registers h for every future event and returns a function that
+// removes it again.
+func (g *Game) Subscribe(h EventHandler) (unsubscribe func()) {
+	b := &g.events
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.handlers == nil {
+		b.handlers = make(map[int]EventHandler)
+	}
+	id := b.nextID
+	b.nextID++
+	b.handlers[id] = h
+	b.order = append(b.order, id)
+
+	return func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		delete(b.handlers, id)
+		for i, oid := range b.order {
+			if oid == id {
+				b.order = append(b.order[:i], b.order[i+1:]...)
+				break
+			}
+		}
+	}
+}
+
+// emit delivers e to every subscriber in subscription order.
+func (g *Game) emit(e Event) {
+	b := &g.events
+	b.mu.Lock()
+	handlers := make([]EventHandler, 0, len(b.order))
+	for _, id := range b.order {
+		handlers = append(handlers, b.handlers[id])
+	}
+	b.mu.Unlock()
+
+	// Call outside the lock so handlers may subscribe or unsubscribe
+	for _, h := range handlers {
+		h(e)
+	}
+}
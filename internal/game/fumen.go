@@ -0,0 +1,239 @@
+package game
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/gdamore/tcell/v2"
+
+	"gotetris/internal/fumen"
+)
+
+// --- Fumen --------------------------------------------------------------------
+//
+// Players share boards as fumen strings. A page becomes a puzzle setup (its
+// board plus a queue), and the game goes out either as the board on screen or
+// as one page per piece locked so far.
+
+// PuzzleFromFumen sets up a puzzle from page index (counting from 0) of a
+// fumen. The queue comes from the page's quiz comment if it has one, or else
+// from the pieces placed on that page and the ones after it. The goal is left
+// for the caller.
+func PuzzleFromFumen(code string, index int) (Puzzle, error) {
+	pages, err := fumen.Decode(code)
+	if err != nil {
+		return Puzzle{}, err
+	}
+	if index < 0 || index >= len(pages) {
+		return Puzzle{}, fmt.Errorf("fumen has %d pages, no page %d", len(pages), index+1)
+	}
+
+	page := pages[index]
+	p := Puzzle{Playfield: fromFumenField(&page.Field)}
+	if q, ok := fumen.ParseQuiz(page.Comment); ok {
+		p.Hold = fromFumenPiece(q.Hold)
+		for _, id := range append([]fumen.Piece{q.Current}, q.Next...) {
+			if id != fumen.Empty {
+				p.Queue = append(p.Queue, fromFumenPiece(id))
+			}
+		}
+		return p, nil
+	}
+
+	for _, next := range pages[index:] {
+		if next.Operation != nil {
+			p.Queue = append(p.Queue, fromFumenPiece(next.Operation.Piece))
+			if !next.Lock {
+				break
+			}
+		}
+	}
+	return p, nil
+}
+
+// FumenBoard writes the position on screen as a one page fumen: the board,
+// the piece in play and a quiz comment with the hold and the queue.
+func (g *Game) FumenBoard() string {
+	page := g.fumenPage()
+	page.Colorize = true
+	return fumen.Encode([]fumen.Page{page})
+}
+
+// FumenGame writes the game so far: one page per locked piece, starting from
+// the board the game began on, then the position on screen. Garbage that
+// rose after a piece is on the board of the page after it.
+func (g *Game) FumenGame() string {
+	var pages []fumen.Page
+	f := g.startField
+	for _, step := range g.history {
+		pages = append(pages, fumen.Page{
+			Field:     toFumenField(&f),
+			Operation: toFumenOperation(step.Placement),
+			Lock:      true,
+		})
+		PlacePiece(&f, step.Placement)
+		InsertGarbage(&f, step.Garbage)
+	}
+	pages = append(pages, g.fumenPage())
+	pages[0].Colorize = true
+	return fumen.Encode(pages)
+}
+
+// fumenPage is the position on screen as a page.
+func (g *Game) fumenPage() fumen.Page {
+	q := fumen.Quiz{Hold: toFumenPiece(g.Hold)}
+	for _, id := range g.NextQueue {
+		q.Next = append(q.Next, toFumenPiece(id))
+	}
+
+	page := fumen.Page{Field: toFumenField(&g.Playfield)}
+	if g.Current != nil {
+		q.Current = toFumenPiece(g.Current.ID)
+		page.Operation = toFumenOperation(g.Current.placement())
+	}
+	page.Comment = q.String()
+	return page
+}
+
+// historyStep is a locked piece and the garbage that rose after it, holes
+// as InsertGarbage takes them.
+type historyStep struct {
+	Placement
+	Garbage []int `json:",omitempty"`
+}
+
+// trackHistory records every lock so the whole game can be exported.
+func (g *Game) trackHistory(e Event) {
+	if ev, ok := e.(LockedEvent); ok {
+		g.history = append(g.history, historyStep{Placement: Placement{Piece: ev.Piece, Rotation: ev.Rotation, Position: ev.Position}})
+	}
+}
+
+// --- Sharing ------------------------------------------------------------------
+
+// noticeFrames is how long a notice stays in the side panel (3 seconds).
+const noticeFrames = 180
+
+// SetExportFile makes fumen exports also append to path, for terminals that
+// do not let programs set the clipboard.
+func (g *Game) SetExportFile(path string) {
+	g.exportPath = path
+}
+
+// handleExportInput exports the board on F and the whole game on G, and
+// takes a screenshot on S, while there is a board to show. It reports
+// whether it used the key.
+func (g *Game) handleExportInput(ev *tcell.EventKey) bool {
+	if ev.Key() != tcell.KeyRune || (g.State != Playing && g.State != Paused && g.State != GameOver) {
+		return false
+	}
+	switch ev.Rune() {
+	case 'f', 'F':
+		g.share("Board", g.FumenBoard())
+	case 'g', 'G':
+		g.share("Game", g.FumenGame())
+	case 's', 'S':
+		g.screenshot()
+	default:
+		return false
+	}
+	return true
+}
+
+// share puts a fumen on the clipboard and in the export file, if there is
+// one, and says so in the side panel.
+func (g *Game) share(what, code string) {
+	g.setNotice(what + " copied as fumen")
+	defer func() {
+		if g.app != nil {
+			g.app.QueueUpdateDraw(func() { g.clipboard = []byte(code) })
+		}
+	}()
+
+	if g.exportPath == "" {
+		return
+	}
+	f, err := os.OpenFile(g.exportPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err == nil {
+		_, err = fmt.Fprintln(f, code)
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+	}
+	if err != nil {
+		g.setNotice("Export failed: " + err.Error())
+	}
+}
+
+// setNotice shows a short message in the side panel for a few seconds.
+func (g *Game) setNotice(text string) {
+	g.notice, g.noticeUntil = text, g.frame+noticeFrames
+}
+
+// Notify shows text in the side panel for a moment. Like everything that
+// changes the game it must run on the game's goroutine, e.g. in a
+// subscriber.
+func (g *Game) Notify(text string) {
+	g.setNotice(text)
+}
+
+// --- Conversions --------------------------------------------------------------
+
+// toFumenPiece maps a cell to fumen's numbering; anything that is not a
+// tetromino turns gray.
+func toFumenPiece(id PieceID) fumen.Piece {
+	if p, ok := fumen.PieceFromLetter(PieceLetter(id)); ok {
+		return p
+	}
+	if id != 0 {
+		return fumen.Gray
+	}
+	return fumen.Empty
+}
+
+func fromFumenPiece(p fumen.Piece) PieceID {
+	if id, ok := PieceFromLetter(p.String()); ok {
+		return id
+	}
+	if p == fumen.Gray {
+		return Garbage
+	}
+	return 0
+}
+
+// toFumenField copies the bottom rows of the board; fumen boards are only 23
+// rows tall.
+func toFumenField(f *Field) fumen.Field {
+	var out fumen.Field
+	for x := 0; x < PlayWidth; x++ {
+		for y := 0; y < fumen.Height; y++ {
+			out[x][y] = toFumenPiece(PieceID(f[x][y]))
+		}
+	}
+	return out
+}
+
+func fromFumenField(f *fumen.Field) Field {
+	var out Field
+	for x := 0; x < PlayWidth; x++ {
+		for y := 0; y < fumen.Height; y++ {
+			out[x][y] = int(fromFumenPiece(f[x][y]))
+		}
+	}
+	return out
+}
+
+// toFumenOperation locates a placement the guideline way. It is nil for a
+// piece fumen cannot show, one that sticks out above its board.
+func toFumenOperation(p Placement) *fumen.Operation {
+	o, center, ok := ToSRS(p)
+	if !ok {
+		return nil
+	}
+	for _, c := range p.Cells() {
+		if c.Y >= fumen.Height {
+			return nil
+		}
+	}
+	return &fumen.Operation{Piece: toFumenPiece(p.Piece), Orientation: o, X: center.X, Y: center.Y}
+}
@@ -0,0 +1,154 @@
+package game
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gotetris/internal/fumen"
+)
+
+// flatBot keeps the stack as low and as flat as it can, which is all the
+// tests need from a bot.
+type flatBot struct{}
+
+// Suggest implements Bot.
+func (flatBot) Suggest(pos Position) (Placement, bool) {
+	best, bestCost, found := Placement{}, 0, false
+	for _, p := range ReachablePlacements(&pos.Playfield, pos.Current) {
+		f := pos.Playfield
+		PlacePiece(&f, p)
+		cost := 0
+		for x := 0; x < PlayWidth; x++ {
+			top := 0
+			for y := TotalHeight - 1; y >= 0; y-- {
+				if f[x][y] != 0 {
+					top = y + 1
+					break
+				}
+			}
+			cost += top * top
+			for y := 0; y < top; y++ {
+				if f[x][y] == 0 {
+					cost += 50 // A hole
+				}
+			}
+		}
+		if !found || cost < bestCost {
+			best, bestCost, found = p, cost, true
+		}
+	}
+	return best, found
+}
+
+// garbageGame plays a seeded bot game that takes a line of garbage every
+// two seconds, until it has locked pieces or ended. setup runs before the
+// game starts.
+func garbageGame(t *testing.T, pieces int, setup ...func(*Game)) *Game {
+	t.Helper()
+	m := Marathon
+	rules := GuidelineGarbage
+	m.Garbage = &rules
+	g := NewGame(nil, nil)
+	g.SetMode(m)
+	g.SetSeed(9)
+	g.SetAutoplay(flatBot{}, 0)
+	for _, f := range setup {
+		f(g)
+	}
+	g.StartGame()
+	for i := 0; len(g.history) < pieces && g.State == Playing && i < 60*600; i++ {
+		if i%120 == 60 {
+			g.ReceiveGarbage(1)
+		}
+		g.Step()
+	}
+	if g.garbage.Received == 0 {
+		t.Fatal("no garbage rose")
+	}
+	return g
+}
+
+func TestFumenGameWithGarbage(t *testing.T) {
+	g := garbageGame(t, 60)
+
+	// The history rebuilds the board on screen
+	f := g.startField
+	for _, step := range g.history {
+		PlacePiece(&f, step.Placement)
+		InsertGarbage(&f, step.Garbage)
+	}
+	if g.Phase == PhaseFalling && f != g.Playfield {
+		t.Fatal("history does not rebuild the board")
+	}
+
+	pages, err := fumen.Decode(g.FumenGame())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pages) != len(g.history)+1 {
+		t.Fatalf("%d pages for %d pieces", len(pages), len(g.history))
+	}
+	for i, page := range pages[:len(pages)-1] {
+		op := page.Operation
+		if op == nil {
+			t.Fatalf("page %d: no piece", i+1)
+		}
+		resting := false
+		for _, c := range op.Cells() {
+			x, y := c[0], c[1]
+			if x < 0 || x >= fumen.Width || y < 0 || y >= fumen.Height {
+				t.Fatalf("page %d: piece off the board at %d,%d", i+1, x, y)
+			}
+			if page.Field[x][y] != fumen.Empty {
+				t.Fatalf("page %d: piece overlaps the field at %d,%d", i+1, x, y)
+			}
+			resting = resting || y == 0 || page.Field[x][y-1] != fumen.Empty
+		}
+		if !resting {
+			t.Fatalf("page %d: piece floats", i+1)
+		}
+	}
+}
+
+func TestFumenFromSaveAndReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "game.replay")
+	g := garbageGame(t, 40, func(g *Game) { g.SetReplayFile(path) })
+	want := g.FumenGame()
+
+	// A saved game exports what was played up to the save
+	var saved bytes.Buffer
+	if err := g.Save(&saved); err != nil {
+		t.Fatal(err)
+	}
+	resumed := NewGame(nil, nil)
+	if err := resumed.Resume(&saved); err != nil {
+		t.Fatal(err)
+	}
+	if got := resumed.FumenGame(); got != want {
+		t.Errorf("resumed game exports\n%s\nwant\n%s", got, want)
+	}
+
+	// A replay plays the same game back, garbage and all
+	g.endGame(ReasonTopOut)
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	rp, err := ReadReplay(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	player, err := NewReplayPlayer(rp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for !player.Done() {
+		player.Step()
+	}
+	if got := player.FumenGame(); got != want {
+		t.Errorf("replay exports\n%s\nwant\n%s", got, want)
+	}
+}
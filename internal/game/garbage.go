@@ -0,0 +1,259 @@
+package game
+
+import "math/rand/v2"
+
+// --- Garbage ------------------------------------------------------------------
+//
+// Modes with garbage rules attack: clears send lines by the mode's attack
+// table, and whoever is on the other end (a versus opponent, a survival
+// timer, a bot) hands them to ReceiveGarbage. Received lines wait in a queue
+// for the garbage delay. A clear cancels waiting lines before it sends
+// anything; a piece that clears nothing lets the lines that are due rise
+// from the bottom, pushing the stack up through the hidden buffer, and a
+// stack pushed out of the top tops the game out. Holes come from a
+// randomizer of their own, so the pieces come in the same order with or
+// without garbage.
+
+// AttackTable is how many lines each kind of clear sends.
+type AttackTable struct {
+	Clears     []int // By lines cleared
+	MiniTSpins []int // By lines cleared with a mini T-spin
+	TSpins     []int // By lines cleared with a T-spin
+	B2B        int   // Extra for a clear back to back
+	Combos     []int // Extra by combo step, the last repeating from there on
+	Perfect    int   // Extra for a perfect clear
+}
+
+// GuidelineAttack is the guideline's attack table.
+var GuidelineAttack = AttackTable{
+	Clears:     []int{0, 0, 1, 2, 4},
+	MiniTSpins: []int{0, 0, 1},
+	TSpins:     []int{0, 2, 4, 6},
+	B2B:        1,
+	Combos:     []int{0, 1, 1, 2, 2, 3, 3, 4},
+	Perfect:    10,
+}
+
+// Lines is how many lines a lock sends.
+func (t AttackTable) Lines(l Lock) int {
+	if l.Lines == 0 {
+		return 0
+	}
+	table := t.Clears
+	switch l.TSpin {
+	case MiniTSpin:
+		table = t.MiniTSpins
+	case FullTSpin:
+		table = t.TSpins
+	}
+	lines := 0
+	if len(table) > 0 {
+		lines = table[min(l.Lines, len(table)-1)]
+	}
+	if l.B2B {
+		lines += t.B2B
+	}
+	if steps := l.Combo - 1; steps > 0 && len(t.Combos) > 0 {
+		lines += t.Combos[min(steps, len(t.Combos)-1)]
+	}
+	if l.Perfect {
+		lines += t.Perfect
+	}
+	return lines
+}
+
+// HoleRule is where the holes in garbage rows go.
+type HoleRule int
+
+const (
+	HoleClean HoleRule = iota // Every row of an attack has its hole in the same column
+	HoleMessy                 // Every row has its hole somewhere else
+)
+
+// GarbageRules say how a mode attacks and takes garbage. A new hole is never
+// in the column of the one before it.
+type GarbageRules struct {
+	Attack AttackTable
+	Delay  int // Frames received lines wait before they can rise
+	Cap    int // Most lines that rise after one piece; 0 for no limit
+	Holes  HoleRule
+}
+
+// GuidelineGarbage are the garbage rules of guideline versus games.
+var GuidelineGarbage = GarbageRules{Attack: GuidelineAttack, Delay: 20, Holes: HoleClean}
+
+// ReasonTopOut is reported by GameOverEvent when garbage pushed the stack
+// out of the top of the playfield.
+const ReasonTopOut = "top out"
+
+// incomingGarbage is one attack waiting to rise.
+type incomingGarbage struct {
+	Lines int `json:"lines"`
+	Hole  int `json:"hole"` // Column of the hole, for clean garbage
+	Due   int `json:"due"`  // Frame of play it can rise from
+}
+
+// garbageRun is the garbage state of a game with garbage rules.
+type garbageRun struct {
+	Incoming []incomingGarbage `json:"incoming,omitempty"`
+	LastHole int               `json:"last_hole"`     // -1 before the first hole
+	Sent     int               `json:"sent"`          // Lines sent, cancelled ones not counted
+	Received int               `json:"received"`      // Lines that rose
+	RNG      []byte            `json:"rng,omitempty"` // Hole randomizer state, kept up to date by Save
+
+	pcg *rand.PCG
+	rng *rand.Rand
+}
+
+// startGarbage sets garbage up for a new game in a mode that has it. Its
+// randomizer is seeded from the game's seed without drawing from the piece
+// randomizer, so replays and seeded games get the same holes and the same
+// pieces as they would without garbage.
+func (g *Game) startGarbage() {
+	g.garbage = nil
+	if g.Mode.Garbage == nil || g.puzzle != nil {
+		return
+	}
+	if g.rng == nil {
+		g.SetSeed(rand.Uint64())
+	}
+	g.garbage = &garbageRun{LastHole: -1}
+	g.garbage.seed(g.seed ^ garbageSeed)
+}
+
+// garbageSeed is mixed into the game's seed to seed the hole randomizer, so
+// holes do not follow the pieces.
+const garbageSeed = 0x6a09e667f3bcc908
+
+// seed restarts the hole randomizer.
+func (r *garbageRun) seed(seed uint64) {
+	r.pcg = rand.NewPCG(seed, seed^0x9e3779b97f4a7c15)
+	r.rng = rand.New(r.pcg)
+}
+
+// pickHole picks the column of the next hole, never the last one's.
+func (r *garbageRun) pickHole() int {
+	hole := r.rng.IntN(PlayWidth)
+	if r.LastHole >= 0 {
+		if hole = r.rng.IntN(PlayWidth - 1); hole >= r.LastHole {
+			hole++
+		}
+	}
+	r.LastHole = hole
+	return hole
+}
+
+// waiting is how many received lines have not risen yet.
+func (r *garbageRun) waiting() int {
+	n := 0
+	for _, in := range r.Incoming {
+		n += in.Lines
+	}
+	return n
+}
+
+// cancel takes up to lines lines off the incoming garbage, the oldest
+// first, and returns how many it took.
+func (r *garbageRun) cancel(lines int) int {
+	canceled := 0
+	for lines > 0 && len(r.Incoming) > 0 {
+		n := min(lines, r.Incoming[0].Lines)
+		r.Incoming[0].Lines -= n
+		lines, canceled = lines-n, canceled+n
+		if r.Incoming[0].Lines == 0 {
+			r.Incoming = r.Incoming[1:]
+		}
+	}
+	return canceled
+}
+
+// ReceiveGarbage queues lines of garbage sent by an opponent. Games without
+// garbage rules ignore it. Only the goroutine that runs the game may call
+// it.
+func (g *Game) ReceiveGarbage(lines int) {
+	if g.garbage == nil || lines <= 0 || g.State == GameOver {
+		return
+	}
+	if g.replay != nil {
+		g.replay.Inputs = append(g.replay.Inputs, ReplayInput{Frame: g.playFrames, Garbage: lines})
+	}
+	g.garbage.Incoming = append(g.garbage.Incoming, incomingGarbage{
+		Lines: lines, Hole: g.garbage.pickHole(), Due: g.playFrames + g.Mode.Garbage.Delay,
+	})
+	g.emit(GarbageReceivedEvent{Lines: lines, Waiting: g.garbage.waiting()})
+}
+
+// exchangeGarbage attacks with a lock that cleared lines, cancelling
+// incoming garbage first, or lets the garbage that is due rise after one
+// that cleared none.
+func (g *Game) exchangeGarbage(l Lock) {
+	if l.Lines == 0 {
+		g.riseGarbage()
+		return
+	}
+	attack := g.Mode.Garbage.Attack.Lines(l)
+	if attack == 0 {
+		return
+	}
+	canceled := g.garbage.cancel(attack)
+	g.garbage.Sent += attack - canceled
+	g.emit(AttackEvent{Lines: attack, Canceled: canceled, Sent: attack - canceled})
+}
+
+// riseGarbage pushes up the incoming lines that are due, up to the cap.
+func (g *Game) riseGarbage() {
+	run, rules := g.garbage, g.Mode.Garbage
+	var holes []int
+	for len(run.Incoming) > 0 && run.Incoming[0].Due <= g.playFrames && (rules.Cap == 0 || len(holes) < rules.Cap) {
+		in := &run.Incoming[0]
+		n := in.Lines
+		if rules.Cap > 0 {
+			n = min(n, rules.Cap-len(holes))
+		}
+		for i := 0; i < n; i++ {
+			if rules.Holes == HoleMessy {
+				holes = append(holes, run.pickHole())
+			} else {
+				holes = append(holes, in.Hole)
+			}
+		}
+		if in.Lines -= n; in.Lines == 0 {
+			run.Incoming = run.Incoming[1:]
+		}
+	}
+	if len(holes) == 0 {
+		return
+	}
+
+	over := InsertGarbage(&g.Playfield, holes)
+	if n := len(g.history); n > 0 {
+		// Rises follow a lock, so exports put them after its piece
+		g.history[n-1].Garbage = append(g.history[n-1].Garbage, holes...)
+	}
+	raiseRows(&g.lockedAt, len(holes))
+	for x := 0; x < PlayWidth; x++ {
+		for y := 0; y < min(len(holes), TotalHeight); y++ {
+			g.lockedAt[x][y] = g.playFrames
+		}
+	}
+	run.Received += len(holes)
+	g.emit(GarbageRisenEvent{Lines: len(holes)})
+	if over {
+		g.endGame(ReasonTopOut)
+	}
+}
+
+// GarbageStatus is the garbage readout of a game with garbage rules.
+type GarbageStatus struct {
+	Sent     int // Lines sent so far
+	Received int // Lines that rose so far
+	Incoming int // Lines waiting to rise
+}
+
+// garbageStatus is the readout of the game's garbage, nil without garbage.
+func (g *Game) garbageStatus() *GarbageStatus {
+	if g.garbage == nil {
+		return nil
+	}
+	return &GarbageStatus{Sent: g.garbage.Sent, Received: g.garbage.Received, Incoming: g.garbage.waiting()}
+}
@@ -0,0 +1,44 @@
+package game
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestGarbageKeepsPieceOrder(t *testing.T) {
+	// The pieces a seed deals, with garbage rules or without, taking
+	// garbage every few pieces or not
+	deal := func(rules *GarbageRules, attack bool) []PieceID {
+		m := Marathon
+		m.Garbage = rules
+		g := NewGame(nil, nil)
+		g.SetMode(m)
+		g.SetSeed(42)
+		g.SetAutoplay(flatBot{}, 0)
+		var pieces []PieceID
+		g.Subscribe(func(e Event) {
+			if ev, ok := e.(PieceSpawnedEvent); ok {
+				pieces = append(pieces, ev.Piece)
+				if attack && len(pieces)%5 == 0 {
+					g.ReceiveGarbage(1)
+				}
+			}
+		})
+		g.StartGame()
+		for len(pieces) < 30 && g.State == Playing {
+			g.Step()
+		}
+		return pieces
+	}
+
+	want := deal(nil, false)
+	if len(want) < 30 {
+		t.Fatalf("only %d pieces without garbage", len(want))
+	}
+	for name, attack := range map[string]bool{"rules": false, "attacked": true} {
+		rules := GuidelineGarbage
+		if got := deal(&rules, attack); !slices.Equal(got, want) {
+			t.Errorf("%s: dealt %v, want %v", name, got, want)
+		}
+	}
+}
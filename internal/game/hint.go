@@ -0,0 +1,149 @@
+package game
+
+import "fmt"
+
+// --- Practice Hints -------------------------------------------------------------
+
+// Hint is the evaluator's pick for the current piece, plus why it likes it.
+type Hint struct {
+	Placement   Placement
+	HolesDelta  int  // Change in covered empty cells
+	HeightDelta int  // Change in stack height
+	Lines       int  // Rows the placement clears
+	Hold        bool // The placement is for the piece you get by holding
+}
+
+// Why explains the hint in a few words for the side panel.
+func (h Hint) Why() string {
+	why := fmt.Sprintf("holes %+d height %+d", h.HolesDelta, h.HeightDelta)
+	if h.Hold {
+		why = "hold, " + why
+	}
+	return why
+}
+
+// hinter asks the hint bot about every new piece while hints are on.
+type hinter struct {
+	bot     Bot
+	enabled bool
+	piece   *Piece // Piece the hint is for
+	current *Hint  // Nil while the bot is thinking
+	results chan suggestion
+}
+
+// SetHintBot sets the evaluator behind the practice hints.
+func (g *Game) SetHintBot(b Bot) {
+	g.hints = &hinter{bot: b, results: make(chan suggestion, 1)}
+}
+
+// HintsAvailable reports whether the current mode allows hints at all.
+func (g *Game) HintsAvailable() bool {
+	return g.fixedSpeed() && g.hints != nil
+}
+
+// ToggleHints switches the hint overlay on or off.
+func (g *Game) ToggleHints() {
+	if !g.HintsAvailable() {
+		return
+	}
+	g.hints.enabled = !g.hints.enabled
+	g.hints.piece, g.hints.current = nil, nil
+}
+
+// CurrentHint returns the hint for the piece in play, if there is one.
+func (g *Game) CurrentHint() (Hint, bool) {
+	if !g.HintsAvailable() || !g.hints.enabled || g.hints.current == nil || g.hints.piece != g.Current {
+		return Hint{}, false
+	}
+	return *g.hints.current, true
+}
+
+// step picks up finished hints and asks about new pieces. It runs once per
+// tick on the loop goroutine; the bot itself thinks in the background.
+func (h *hinter) step(g *Game) {
+	if !h.enabled || !g.HintsAvailable() || g.State != Playing || g.Current == nil {
+		return
+	}
+
+	if g.Current != h.piece {
+		h.piece, h.current = g.Current, nil
+		piece, pos, bot, results := g.Current, g.position(), h.bot, h.results
+
+		// Always ask from the spawn point so the hint does not depend on
+		// how far the piece has already fallen
+		pos.Current = Placement{Piece: piece.ID, Position: SpawnPoint()}
+		go func() {
+			p, ok := bot.Suggest(pos)
+			results <- suggestion{piece: piece, placement: p, ok: ok}
+		}()
+	}
+
+	select {
+	case s := <-h.results:
+		if s.piece == h.piece && s.ok {
+			hint := explainHint(&g.Playfield, s.placement)
+			hint.Hold = s.placement.Piece != h.piece.ID
+			h.current = &hint
+		}
+	default:
+	}
+}
+
+// explainHint measures what a placement does to the board.
+func explainHint(f *Field, p Placement) Hint {
+	holesBefore, heightBefore := boardShape(f)
+	after := *f
+	lines := PlacePiece(&after, p)
+	holesAfter, heightAfter := boardShape(&after)
+
+	return Hint{
+		Placement:   p,
+		HolesDelta:  holesAfter - holesBefore,
+		HeightDelta: heightAfter - heightBefore,
+		Lines:       lines,
+	}
+}
+
+// boardShape counts covered empty cells and the height of the tallest column.
+func boardShape(f *Field) (holes, height int) {
+	for x := 0; x < PlayWidth; x++ {
+		covered := false
+		for y := TotalHeight - 1; y >= 0; y-- {
+			if f[x][y] != 0 {
+				if !covered {
+					height = max(height, y+1)
+				}
+				covered = true
+			} else if covered {
+				holes++
+			}
+		}
+	}
+	return holes, height
+}
+
+// --- Stats ------------------------------------------------------------------------
+
+// Stats are running counters for the current game.
+type Stats struct {
+	Pieces      int // Pieces locked
+	Hinted      int // Locked pieces that had a hint on screen
+	HintMatches int // ... and landed exactly where the hint said
+}
+
+// trackStats keeps Stats up to date from the event stream.
+func (g *Game) trackStats(e Event) {
+	ev, ok := e.(LockedEvent)
+	if !ok {
+		return
+	}
+	g.Stats.Pieces++
+
+	if g.hints == nil || !g.hints.enabled || g.hints.current == nil || g.hints.piece != g.Current {
+		return
+	}
+	g.Stats.Hinted++
+	if cellsKey(ev.Blocks) == cellsKey(g.hints.current.Placement.Cells()) {
+		g.Stats.HintMatches++
+	}
+}
@@ -0,0 +1,140 @@
+package game
+
+import "github.com/gdamore/tcell/v2"
+
+// --- Player Actions -------------------------------------------------------------
+
+// Action is a single gameplay input. Keys are translated into actions, and
+// bots issue the very same actions, so both go through one code path.
+type Action int
+
+const (
+	ActionNone Action = iota
+	ActionMoveLeft
+	ActionMoveRight
+	ActionRotate // Clockwise
+	ActionSoftDrop
+	ActionHardDrop
+	ActionRotateCCW
+	ActionHold
+)
+
+// String returns a short name for logs and debugging.
+func (a Action) String() string {
+	switch a {
+	case ActionMoveLeft:
+		return "left"
+	case ActionMoveRight:
+		return "right"
+	case ActionRotate:
+		return "rotate"
+	case ActionSoftDrop:
+		return "soft drop"
+	case ActionHardDrop:
+		return "hard drop"
+	case ActionRotateCCW:
+		return "rotate ccw"
+	case ActionHold:
+		return "hold"
+	default:
+		return "none"
+	}
+}
+
+// keyAction maps a key press to the gameplay action it triggers.
+func (g *Game) keyAction(ev *tcell.EventKey) Action {
+	switch ev.Key() {
+	case tcell.KeyLeft:
+		return ActionMoveLeft
+	case tcell.KeyRight:
+		return ActionMoveRight
+	case tcell.KeyDown:
+		return ActionSoftDrop
+	case tcell.KeyUp:
+		if g.settings.Controls.UpHardDrops {
+			return ActionHardDrop
+		}
+		return ActionRotate
+	case tcell.KeyRune:
+		switch ev.Rune() {
+		case ' ':
+			return ActionHardDrop
+		case 'x', 'X':
+			return ActionRotate
+		case 'z', 'Z':
+			return ActionRotateCCW
+		case 'c', 'C':
+			return ActionHold
+		}
+	}
+	return ActionNone
+}
+
+// isConfirm reports whether the key accepts, like ENTER.
+func isConfirm(ev *tcell.EventKey) bool {
+	return ev.Key() == tcell.KeyEnter || (ev.Key() == tcell.KeyRune && ev.Rune() == ' ')
+}
+
+// isQuit reports whether the key leaves the game.
+func isQuit(ev *tcell.EventKey) bool {
+	return ev.Key() == tcell.KeyCtrlC || (ev.Key() == tcell.KeyRune && (ev.Rune() == 'q' || ev.Rune() == 'Q'))
+}
+
+// isRetry reports whether the key restarts a puzzle.
+func isRetry(ev *tcell.EventKey) bool {
+	return ev.Key() == tcell.KeyRune && (ev.Rune() == 'r' || ev.Rune() == 'R')
+}
+
+// playerAct performs an action for the player, as the handling settings
+// have it: a soft drop may go several rows, and keys pressed between
+// pieces may be dropped instead of kept for the next one. What ends up
+// happening is recorded action by action, so replays need no settings.
+func (g *Game) playerAct(a Action) {
+	h := g.settings.Handling
+	if g.Current == nil && !h.BufferMoves {
+		return
+	}
+	if a != ActionSoftDrop {
+		g.act(a)
+		return
+	}
+
+	// The first row may lock a piece that already rests; further rows
+	// only go while that same piece can still fall
+	piece := g.Current
+	g.act(a)
+	for i := 1; (h.SoftDrop == 0 || i < h.SoftDrop) && g.Current == piece && piece != nil && !g.resting(); i++ {
+		g.act(a)
+	}
+}
+
+// applyAction performs a gameplay action on the current piece. Between
+// pieces it is kept for the next one.
+func (g *Game) applyAction(a Action) {
+	if g.State != Playing {
+		return
+	}
+	if g.Current == nil {
+		if g.Phase != PhaseFalling {
+			g.bufferAction(a)
+		}
+		return
+	}
+
+	switch a {
+	case ActionMoveLeft:
+		g.moveLeft()
+	case ActionMoveRight:
+		g.moveRight()
+	case ActionSoftDrop:
+		g.softDrop()
+	case ActionRotate:
+		g.rotate(1)
+	case ActionRotateCCW:
+		g.rotate(-1)
+	case ActionHardDrop:
+		g.hardDrop()
+	case ActionHold:
+		g.hold()
+	}
+}
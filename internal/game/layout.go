@@ -0,0 +1,236 @@
+package game
+
+import (
+	"fmt"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// --- Layout -------------------------------------------------------------------
+//
+// The board picks its layout from the space it gets each time it is drawn,
+// so resizing the terminal takes effect on the next frame. Blocks come in
+// three sizes: one cell for tiny panes such as tmux splits, the standard two
+// cells side by side, and four by two for projectors. The panels sit beside
+// the playfield, move under it in tall narrow panes, and are left out when
+// only the playfield fits. When not even that fits, the board says so. With
+// announcements on, the bottom row is kept for them, see access.go. In a
+// versus match the bot's board takes the right half, see battle.go.
+
+// cellSize is how many terminal cells a block takes.
+type cellSize struct {
+	W, H int
+}
+
+var (
+	compactCells  = cellSize{1, 1}
+	standardCells = cellSize{2, 1}
+	largeCells    = cellSize{4, 2}
+)
+
+// boardScale is a size of the board and its panels.
+type boardScale struct {
+	cells   cellSize
+	side    int // Width of the panels
+	preview int // Height of the next and hold boxes
+	gap     int // Columns between the playfield and the panels
+}
+
+var (
+	compactScale  = boardScale{cells: compactCells, side: 22, preview: 4, gap: 1}
+	standardScale = boardScale{cells: standardCells, side: 32, preview: 6, gap: 2}
+	largeScale    = boardScale{cells: largeCells, side: 32, preview: 8, gap: 2}
+)
+
+// Heights of the status box: with everything on it, and with the state, the
+// score, the level, the lines and the mode.
+const (
+	statusFull = 20
+	statusMin  = 8
+)
+
+// Where the panels go.
+const (
+	panelsBeside = iota // In a column right of the playfield
+	panelsBelow         // Under the playfield
+	panelsNone          // Left out
+)
+
+// boardLayouts are the layouts tried, best first.
+var boardLayouts = []struct {
+	scale  boardScale
+	panels int
+}{
+	{largeScale, panelsBeside},
+	{standardScale, panelsBeside},
+	{standardScale, panelsBelow},
+	{compactScale, panelsBeside},
+	{compactScale, panelsBelow},
+	{standardScale, panelsNone},
+	{compactScale, panelsNone},
+}
+
+// boardRect is where a box goes, relative to the board's top left. A box
+// with no width is not shown.
+type boardRect struct {
+	X, Y, W, H int
+}
+
+// boardLayout is where everything on the board goes.
+type boardLayout struct {
+	cells                     cellSize
+	field, status, next, hold boardRect
+}
+
+// layoutBoard lays the board out in width by height cells: the first of
+// boardLayouts that fits.
+func layoutBoard(width, height int) (boardLayout, bool) {
+	for _, l := range boardLayouts {
+		if layout, ok := l.scale.fit(width, height, l.panels); ok {
+			return layout, true
+		}
+	}
+	return boardLayout{}, false
+}
+
+// fit lays the board out at this scale with the panels where asked, if it
+// fits in width by height cells.
+func (sc boardScale) fit(width, height, panels int) (boardLayout, bool) {
+	fieldW, fieldH := PlayWidth*sc.cells.W+2, VisibleHeight*sc.cells.H+2
+	if width < fieldW || height < fieldH {
+		return boardLayout{}, false
+	}
+	top := min(1, height-fieldH) // Padding above, if there is room
+	l := boardLayout{cells: sc.cells, field: boardRect{0, top, fieldW, fieldH}}
+
+	switch panels {
+	case panelsBeside:
+		// Status, a gap, next and hold, with the status shortened to fit
+		x := fieldW + sc.gap
+		status := min(statusFull, height-top-1-2*sc.preview)
+		if width < x+sc.side || status < statusMin {
+			return boardLayout{}, false
+		}
+		l.status = boardRect{x, top, sc.side, status}
+		l.next = boardRect{x, top + status + 1, sc.side, sc.preview}
+		l.hold = boardRect{x, top + status + 1 + sc.preview, sc.side, sc.preview}
+	case panelsBelow:
+		// Next and hold side by side, then the status
+		y := top + fieldH
+		w := min(width, sc.side)
+		status := min(statusFull, height-y-sc.preview)
+		if w < fieldW || status < statusMin {
+			return boardLayout{}, false
+		}
+		l.next = boardRect{0, y, w / 2, sc.preview}
+		l.hold = boardRect{w / 2, y, w - w/2, sc.preview}
+		l.status = boardRect{0, y + sc.preview, w, status}
+	}
+	return l, true
+}
+
+// minBoardSize is the smallest area a board fits in.
+func minBoardSize() (int, int) {
+	return PlayWidth*compactCells.W + 2, VisibleHeight*compactCells.H + 2
+}
+
+// Board is a playfield with its status, next and hold boxes, all drawing
+// from one Source, laid out to fit the space it is given.
+type Board struct {
+	*tview.Box
+	field  *PlayfieldPrimitive
+	status *StatusPrimitive
+	next   *NextPiecePrimitive
+	hold   *HoldPrimitive
+	rival  *Board            // The bot's board in a versus match, drawn on demand
+	shown  []tview.Primitive // Boxes laid out in the last draw
+}
+
+// NewBoard lays out a playfield with its status, next and hold boxes, all
+// drawing from src. At its largest the board takes 76 columns by 43 rows;
+// it gets by with as little as 12 by 22.
+func NewBoard(src Source) *Board {
+	return &Board{
+		Box:    tview.NewBox(),
+		field:  NewPlayfieldPrimitive(src, 0, 0, 0, 0),
+		status: NewStatusPrimitive(src, 0, 0, 0, 0),
+		next:   NewNextPiecePrimitive(src, 0, 0, 0, 0),
+		hold:   NewHoldPrimitive(src, 0, 0, 0, 0),
+	}
+}
+
+// Draw lays the boxes out for the board's current size and draws them.
+func (b *Board) Draw(screen tcell.Screen) {
+	b.Box.DrawForSubclass(screen, b)
+	x, y, width, height := b.GetInnerRect()
+	b.shown = b.shown[:0]
+	view := b.field.Source.Snapshot()
+	if view != nil && view.Announce && height > 0 {
+		height--
+		b.drawAnnouncement(screen, x, y+height, width, view.Announcement)
+	}
+	if view != nil && view.Battle != nil {
+		if b.rival == nil {
+			b.rival = NewBoard(opponentSource{b.field.Source})
+		}
+		half := width / 2
+		b.rival.SetRect(x+half, y, width-half, height)
+		b.rival.Draw(screen)
+		width = half
+	}
+
+	layout, ok := layoutBoard(width, height)
+	if !ok {
+		minW, minH := minBoardSize()
+		drawTooSmall(screen, x, y, width, height, minW, minH)
+		return
+	}
+
+	b.field.cells, b.next.cells, b.hold.cells = layout.cells, layout.cells, layout.cells
+	for _, box := range []struct {
+		p    tview.Primitive
+		rect boardRect
+	}{
+		{b.field, layout.field},
+		{b.status, layout.status},
+		{b.next, layout.next},
+		{b.hold, layout.hold},
+	} {
+		if box.rect.W == 0 {
+			continue
+		}
+		box.p.SetRect(x+box.rect.X, y+box.rect.Y, box.rect.W, box.rect.H)
+		box.p.Draw(screen)
+		b.shown = append(b.shown, box.p)
+	}
+}
+
+// drawTooSmall says, in the middle of the area, that it needs minW by minH.
+func drawTooSmall(screen tcell.Screen, x, y, width, height, minW, minH int) {
+	style := tcell.StyleDefault.Foreground(tcell.ColorYellow)
+	drawCenteredText(screen, x, y+height/2-1, width, fitText(width, "Terminal too small", "Too small"), style)
+	drawCenteredText(screen, x, y+height/2, width, fitText(width, fmt.Sprintf("Need %dx%d", minW, minH), fmt.Sprintf("%dx%d", minW, minH)), tcell.StyleDefault)
+}
+
+// drawAnnouncement writes the latest announcement on a row of its own, with
+// the cursor at its end for screen readers that follow the cursor.
+func (b *Board) drawAnnouncement(screen tcell.Screen, x, y, width int, text string) {
+	_, printed := tview.Print(screen, tview.Escape(text), x, y, width, tview.AlignLeft, tcell.ColorWhite)
+	screen.ShowCursor(x+min(printed, max(0, width-1)), y)
+}
+
+// MouseHandler passes mouse events on to the box under the pointer.
+func (b *Board) MouseHandler() func(action tview.MouseAction, event *tcell.EventMouse, setFocus func(p tview.Primitive)) (consumed bool, capture tview.Primitive) {
+	return b.WrapMouseHandler(func(action tview.MouseAction, event *tcell.EventMouse, setFocus func(p tview.Primitive)) (consumed bool, capture tview.Primitive) {
+		if !b.InRect(event.Position()) {
+			return false, nil
+		}
+		for _, p := range b.shown {
+			if consumed, capture = p.MouseHandler()(action, event, setFocus); consumed {
+				return consumed, capture
+			}
+		}
+		return false, nil
+	})
+}
@@ -0,0 +1,148 @@
+package game
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+
+	"gotetris/internal/scores"
+)
+
+// --- Leaderboard --------------------------------------------------------------
+
+// SetLeaderboard ranks every finished game of player in t, and shows t on
+// the Leaderboard page. Bot games, puzzles and versus matches are not
+// ranked. Call it before Run.
+func (g *Game) SetLeaderboard(t *scores.Table, player string) {
+	g.leaderboard, g.player = t, player
+}
+
+// trackScores puts each game in the table as it ends and tells the player
+// if it made it.
+func (g *Game) trackScores(e Event) {
+	ev, ok := e.(GameOverEvent)
+	if !ok || g.leaderboard == nil || g.autoplay != nil || g.puzzle != nil || g.Mode.Versus {
+		return
+	}
+	// Scores kept by other rules than the mode's own do not compare
+	if m, err := ModeByName(ev.Mode); err != nil || m.scoring().Name() != g.scorer().Name() {
+		return
+	}
+	rank, err := g.leaderboard.Add(scores.Entry{
+		Name:     g.player,
+		Mode:     ev.Mode,
+		Score:    ev.Score,
+		Lines:    ev.Lines,
+		Level:    ev.Level,
+		Grade:    ev.Grade,
+		Time:     ev.Elapsed,
+		Finished: ev.Reason == ReasonGoalReached || ev.Reason == ReasonTimeUp,
+		Race:     g.Mode.LineGoal > 0,
+		Date:     time.Now(),
+	})
+	switch {
+	case err != nil:
+		g.setNotice("Score not saved: " + err.Error())
+	case rank > 0:
+		g.setNotice(fmt.Sprintf("High score #%d in %s!", rank, ev.Mode))
+	}
+}
+
+// leaderboard is the Leaderboard page: a tab per mode over its table.
+type leaderboard struct {
+	table *scores.Table
+	tabs  *tview.TextView
+	rows  *tview.Table
+	mode  int // Index in Modes of the tab shown
+}
+
+// addLeaderboard adds the Leaderboard page if there is a table to show.
+func (ui *screens) addLeaderboard() {
+	if ui.g.leaderboard == nil {
+		return
+	}
+	lb := &leaderboard{
+		table: ui.g.leaderboard,
+		tabs:  tview.NewTextView().SetRegions(true).SetDynamicColors(true).SetWrap(false),
+		rows:  tview.NewTable().SetFixed(1, 0).SetSelectable(true, false),
+	}
+	for i, m := range Modes {
+		fmt.Fprintf(lb.tabs, `["%d"]%s[""] `, i, m.Name)
+	}
+	// A click on a tab highlights it
+	lb.tabs.SetHighlightedFunc(func(added, _, _ []string) {
+		if len(added) > 0 {
+			fmt.Sscan(added[0], &lb.mode)
+			lb.refresh()
+		}
+	})
+	lb.rows.SetDoneFunc(func(key tcell.Key) {
+		if key == tcell.KeyEscape {
+			ui.back()
+		}
+	})
+	lb.rows.SetInputCapture(func(ev *tcell.EventKey) *tcell.EventKey {
+		switch ev.Key() {
+		case tcell.KeyLeft:
+			lb.mode = (lb.mode + len(Modes) - 1) % len(Modes)
+		case tcell.KeyRight, tcell.KeyTab:
+			lb.mode = (lb.mode + 1) % len(Modes)
+		default:
+			return ev
+		}
+		lb.refresh()
+		return nil
+	})
+	for i, m := range Modes {
+		if m.Name == ui.g.Mode.Name {
+			lb.mode = i
+		}
+	}
+
+	body := tview.NewFlex().SetDirection(tview.FlexRow)
+	body.AddItem(lb.tabs, 2, 0, false)
+	body.AddItem(lb.rows, 0, 1, true)
+	ui.scores = lb
+	ui.add(pageLeaderboard, "LEADERBOARD", body, lb.rows, "←→ mode • ↑↓ scroll • ESC back")
+}
+
+// refresh reads the table again for the tab shown.
+func (lb *leaderboard) refresh() {
+	tab := fmt.Sprint(lb.mode)
+	if h := lb.tabs.GetHighlights(); len(h) != 1 || h[0] != tab {
+		lb.tabs.Highlight(tab) // Calls back into refresh
+		return
+	}
+
+	lb.rows.Clear()
+	header := []string{"#", "Name", "Score", "Lines", "Level", "Time", "Date"}
+	for col, h := range header {
+		lb.rows.SetCell(0, col, tview.NewTableCell(h).SetTextColor(tcell.ColorYellow).SetSelectable(false).SetExpansion(1))
+	}
+	entries, err := lb.table.Top(Modes[lb.mode].Name)
+	if err != nil {
+		lb.rows.SetCell(1, 0, tview.NewTableCell(err.Error()).SetTextColor(tcell.ColorRed))
+		return
+	}
+	if len(entries) == 0 {
+		lb.rows.SetCell(1, 1, tview.NewTableCell("No games yet").SetTextColor(tcell.ColorGray))
+		return
+	}
+	for i, e := range entries {
+		level := fmt.Sprint(e.Level)
+		if e.Grade != "" {
+			level = e.Grade
+		}
+		played := fmt.Sprintf("%d:%05.2f", int(e.Time.Minutes()), (e.Time % time.Minute).Seconds())
+		color := tcell.ColorWhite
+		if !e.Finished && (e.Race || Modes[lb.mode].TimeLimit > 0) {
+			color = tcell.ColorGray // Topped out before the goal
+		}
+		for col, text := range []string{fmt.Sprint(i + 1), e.Name, fmt.Sprint(e.Score), fmt.Sprint(e.Lines), level, played, e.Date.Format("2006-01-02")} {
+			lb.rows.SetCell(i+1, col, tview.NewTableCell(tview.Escape(text)).SetTextColor(color).SetExpansion(1))
+		}
+	}
+	lb.rows.ScrollToBeginning()
+}
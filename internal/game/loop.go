@@ -1,6 +1,8 @@
 package game
 
 import (
+	"errors"
+	"fmt"
 	"time"
 
 	"github.com/gdamore/tcell/v2"
@@ -18,27 +20,74 @@ func NewGame(app *tview.Application, audioManager *audio.AudioManager) *Game {
 		NextQueue:    make([]PieceID, 0, 7),
 		State:        MainMenu,
 		Level:        1,
+		Mode:         Marathon,
 		app:          app,
 		audioManager: audioManager,
 		quit:         make(chan struct{}),
 		input:        make(chan *tcell.EventKey, 16),
+		clicks:       make(chan Click, 16),
+		requests:     make(chan func(), 16),
+		settings:     DefaultSettings,
 	}
-	g.gravityTicker = time.NewTicker(gravityForLevel(g.Level))
+	g.Subscribe(g.trackStats)
+	g.Subscribe(g.trackPuzzle)
+	g.Subscribe(g.trackHistory)
+	g.Subscribe(g.trackSave)
+	g.Subscribe(g.trackReplay)
+	g.Subscribe(g.trackScores)
+	g.Subscribe(g.trackAccess)
+	g.Subscribe(g.trackBattle)
 	return g
 }
 
+// Step advances a game without a screen (app == nil) by one frame. Games on
+// a screen use Run instead, which steps once per tick of the clock.
+func (g *Game) Step() {
+	g.step()
+}
+
+// step is one frame of the fixed 60 Hz timestep: the bot moves, hints come
+// in, the clock runs, delays count down and gravity pulls. A versus
+// opponent plays its frame last.
+func (g *Game) step() {
+	g.frame++
+	if g.autoplay != nil {
+		g.autoplay.step(g)
+	}
+	if g.hints != nil && g.hints.enabled {
+		g.hints.step(g)
+	}
+	if g.State != Playing {
+		return
+	}
+
+	g.tickClock()
+	g.tickDelay()
+	g.tickGravity()
+	g.autosave()
+	g.stepBattle()
+}
+
 // Run starts the concurrent loop and blocks until exit.
 func (g *Game) Run() error {
-	// Set up input forwarding
+	// Set up input forwarding. Keys on the board go to the loop; the menu
+	// pages take their own. Ctrl+C always goes to the loop, so it can save
+	// before the app stops.
 	g.app.SetInputCapture(func(ev *tcell.EventKey) *tcell.EventKey {
+		if ev.Key() != tcell.KeyCtrlC && g.ui.front != pageBoard {
+			return ev
+		}
 		select {
 		case g.input <- ev:
 		default: // drop if buffer full
 		}
-		return ev
+		return nil
 	})
 
-	// Initialize screen, layouts, etc.
+	g.app.EnableMouse(g.settings.Controls.Mouse)
+
+	// Initialize screen, layouts, etc. The menus read the first snapshot
+	g.publish()
 	if err := g.initScreen(); err != nil {
 		return err
 	}
@@ -46,66 +95,80 @@ func (g *Game) Run() error {
 	// Start the main loop in this goroutine
 	go g.loop()
 
-	// Run the tview application (blocks)
-	return g.app.Run()
+	// Run the tview application (blocks), then stop the loop too: the app
+	// also ends on its own when the terminal goes away
+	err := g.app.Run()
+	close(g.quit)
+	if err != nil {
+		return err
+	}
+	if g.saveErr != nil {
+		return fmt.Errorf("saving the game: %w", g.saveErr)
+	}
+	return nil
 }
 
 // loop is the select‑driven heartbeat
 func (g *Game) loop() {
-	ticker := time.NewTicker(TickRate)
-	defer ticker.Stop()
-
-	// Ensure the gravity ticker is initialized
-	if g.gravityTicker == nil {
-		g.gravityTicker = time.NewTicker(gravityForLevel(g.Level))
+	if g.clock == nil {
+		g.clock = NewRealtimeClock()
 	}
-	defer g.gravityTicker.Stop()
+	defer g.clock.Stop()
 
 	needsRedraw := true // Initial redraw needed
 
 	for {
 		select {
-		case <-ticker.C:
-			// Only redraw if we're in Animating state (for line clear animations)
-			if g.State == Animating {
-				needsRedraw = true
-			}
+		case <-g.clock.Ticks():
+			g.step()
 
-		case <-g.gravityTicker.C:
-			// Strict conditions for applying gravity
-			if g.State == Playing && g.Current != nil {
-				// Additional validation before applying gravity
-				if g.Current.Position.Y >= 0 && g.Current.Position.Y < TotalHeight {
-					g.ApplyGravity()
-					needsRedraw = true
-				}
+			// Redraw every frame of play (gravity, delays, the timer), for
+			// bots and hints, and when a notice just ran out
+			if g.State == Playing || g.autoplay != nil || (g.hints != nil && g.hints.enabled) ||
+				(g.notice != "" && g.frame == g.noticeUntil) {
+				needsRedraw = true
 			}
 
 		case ev := <-g.input:
-			switch ev.Key() {
-			case tcell.KeyCtrlC:
-				close(g.quit)
+			switch {
+			case isQuit(ev):
+				// Keep the game (and its replay so far) for next time, then leave
+				g.saveErr = errors.Join(g.saveProgress(), g.writeReplay())
+				g.app.Stop()
 				return
 			default:
 				oldState := g.State
 				g.HandleInput(ev)
 				// Only redraw if state actually changed or we're in a playable state
-				if g.State != oldState || g.State == Playing || g.State == MainMenu || g.State == Paused {
+				if g.State != oldState || g.State == Playing || g.State == Paused {
 					needsRedraw = true
 				}
 			}
 
+		case c := <-g.clicks:
+			g.handleClick(c)
+			needsRedraw = true
+
+		case f := <-g.requests:
+			f()
+			needsRedraw = true
+
 		case <-g.quit:
 			g.app.Stop()
 			return
 		}
 
-		// Only queue a redraw when needed
+		// Publish after every tick and key, drawn or not, so Snapshot().Frame
+		// tells when a tick has been processed; only queue a redraw when needed
+		g.publish()
+		g.followState()
 		if needsRedraw {
 			needsRedraw = false
-			g.app.QueueUpdateDraw(func() {
-				g.render()
-			})
+			// One draw in the queue at a time: it draws the latest
+			// snapshot anyway, and a slow terminal never holds up the loop
+			if g.drawQueued.CompareAndSwap(false, true) {
+				g.app.QueueUpdateDraw(func() { g.drawQueued.Store(false) })
+			}
 		}
 	}
 }
@@ -0,0 +1,161 @@
+package game
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// screenGame is a game running on a simulated screen, paced by hand.
+type screenGame struct {
+	t      *testing.T
+	g      *Game
+	screen tcell.SimulationScreen
+	clock  *ManualClock
+	done   chan error
+}
+
+// runOnScreen starts Run on a simulated screen of width by height.
+func runOnScreen(t *testing.T, width, height int) *screenGame {
+	t.Helper()
+	screen := tcell.NewSimulationScreen("UTF-8")
+	app := tview.NewApplication().SetScreen(screen)
+	screen.SetSize(width, height)
+
+	s := &screenGame{t: t, g: NewGame(app, nil), screen: screen, clock: NewManualClock(), done: make(chan error, 1)}
+	s.g.SetSeed(1)
+	s.g.SetClock(s.clock)
+	go func() { s.done <- s.g.Run() }()
+	s.waitFor("the menu", func(v *Snapshot) bool { return v.State == MainMenu }, "TETRIS")
+	return s
+}
+
+// waitFor waits until the last snapshot passes ok and, unless text is
+// empty, the screen shows text.
+func (s *screenGame) waitFor(what string, ok func(*Snapshot) bool, text string) *Snapshot {
+	s.t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if v := s.g.Snapshot(); v != nil && ok(v) && (text == "" || strings.Contains(s.contents(), text)) {
+			return v
+		}
+		time.Sleep(time.Millisecond)
+	}
+	s.t.Fatalf("timed out waiting for %s; the screen shows:\n%s", what, s.contents())
+	return nil
+}
+
+// contents is the text on the screen, a line per row. The screen hands out
+// its own cells, so they are read on tview's goroutine, between draws.
+func (s *screenGame) contents() string {
+	text := make(chan string, 1)
+	s.g.app.QueueUpdate(func() {
+		cells, width, height := s.screen.GetContents()
+		var b strings.Builder
+		for y := 0; y < height; y++ {
+			for x := 0; x < width; x++ {
+				if r := cells[y*width+x].Runes; len(r) > 0 {
+					b.WriteRune(r[0])
+				} else {
+					b.WriteByte(' ')
+				}
+			}
+			b.WriteByte('\n')
+		}
+		text <- b.String()
+	})
+	return <-text
+}
+
+// key types a key.
+func (s *screenGame) key(k tcell.Key, r rune) {
+	s.screen.InjectKey(k, r, tcell.ModNone)
+}
+
+// advance runs frames frames and waits until the game is done with them.
+func (s *screenGame) advance(frames int) *Snapshot {
+	s.t.Helper()
+	start := s.g.Snapshot().Frame
+	s.clock.Advance(frames)
+	return s.waitFor("the frames to run", func(v *Snapshot) bool { return v.Frame >= start+frames }, "")
+}
+
+// resize changes the size of the screen the way a terminal does.
+func (s *screenGame) resize(width, height int) {
+	s.screen.SetSize(width, height)
+	s.screen.PostEvent(tcell.NewEventResize(width, height))
+}
+
+func TestRunOnScreen(t *testing.T) {
+	s := runOnScreen(t, 100, 45)
+
+	// Play, then Marathon
+	s.key(tcell.KeyEnter, 0)
+	s.waitFor("the mode picker", func(*Snapshot) bool { return true }, "Marathon")
+	s.key(tcell.KeyEnter, 0)
+	s.waitFor("the board", func(v *Snapshot) bool { return v.State == Playing }, "STATUS")
+
+	// Pieces fall with the clock and drop on the keys
+	v := s.advance(60)
+	if v.Played == 0 {
+		t.Fatal("no frames of play")
+	}
+	s.key(tcell.KeyLeft, 0)
+	s.key(tcell.KeyRune, ' ')
+	s.waitFor("the hard drop", func(v *Snapshot) bool { return v.Stats.Pieces == 1 }, "")
+	s.advance(10)
+
+	// Paused, the clock runs but play does not
+	s.key(tcell.KeyRune, 'p')
+	paused := s.waitFor("the pause", func(v *Snapshot) bool { return v.State == Paused }, "PAUSED")
+	if v := s.advance(120); v.Played != paused.Played {
+		t.Fatalf("played %d frames while paused", v.Played-paused.Played)
+	}
+	s.key(tcell.KeyRune, 'p')
+	s.waitFor("the game to resume", func(v *Snapshot) bool { return v.State == Playing }, "")
+	s.advance(30)
+
+	// Too small a terminal says so, and the board comes back with room
+	s.resize(30, 10)
+	s.waitFor("the size notice", func(*Snapshot) bool { return true }, "too small")
+	s.advance(5)
+	s.resize(100, 45)
+	s.waitFor("the board again", func(*Snapshot) bool { return true }, "STATUS")
+	s.advance(5)
+
+	s.key(tcell.KeyCtrlC, 0)
+	select {
+	case err := <-s.done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run did not return after Ctrl+C")
+	}
+}
+
+func TestMenuTooSmall(t *testing.T) {
+	// Pages sit 2 columns in from the left of the terminal
+	width, height := pageWidth+2, pageHeight
+	s := runOnScreen(t, 100, 45)
+	menu := func(*Snapshot) bool { return true }
+	s.resize(width-1, 45)
+	s.waitFor("the size notice", menu, fmt.Sprintf("Need %dx%d", width, height))
+
+	// With just enough room, Settings shows all of itself
+	s.resize(width, height)
+	s.waitFor("the menu", menu, "Settings")
+	s.g.app.QueueUpdateDraw(func() { s.g.ui.show(pageSettings) })
+	s.waitFor("the whole Settings page", menu, "Back")
+	s.resize(width, height-1)
+	s.waitFor("the size notice", menu, "too small")
+
+	s.key(tcell.KeyCtrlC, 0)
+	if err := <-s.done; err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,111 @@
+package game
+
+import "time"
+
+// --- Master Mode --------------------------------------------------------------
+//
+// Master plays by TGM rules. The level runs from 0 to 999: every piece adds
+// one and every cleared line one more, except that the piece alone cannot
+// take the level past a section stop (x99, and 998 at the end), only a line
+// clear can. Gravity reaches 20G at 500 and the delays shrink from there.
+// The score earns a grade from 9 up to S9, and GM for finishing fast enough.
+
+// masterSpeed is TGM's gravity by level, with the delays of TGM2's Master.
+var masterSpeed = func() []SpeedStep {
+	early := Delays{Entry: 25, LineClear: 40, Lock: 30}
+	steps := []SpeedStep{}
+	for _, st := range []struct {
+		level   int
+		gravity int // In 1/256 G
+	}{
+		{0, 4}, {30, 6}, {35, 8}, {40, 10}, {50, 12}, {60, 16}, {70, 32}, {80, 48},
+		{90, 64}, {100, 80}, {120, 96}, {140, 112}, {160, 128}, {170, 144}, {200, 4},
+		{220, 32}, {230, 64}, {233, 96}, {236, 128}, {239, 160}, {243, 192}, {247, 224},
+		{251, 256}, {300, 512}, {330, 768}, {360, 1024}, {400, 1280}, {420, 1024}, {450, 768},
+	} {
+		steps = append(steps, SpeedStep{Level: st.level, Gravity: float64(st.gravity) / 256, Delays: early})
+	}
+	return append(steps,
+		SpeedStep{Level: 500, Gravity: MaxGravity, Delays: Delays{Entry: 25, LineClear: 25, Lock: 30}},
+		SpeedStep{Level: 600, Gravity: MaxGravity, Delays: Delays{Entry: 25, LineClear: 16, Lock: 30}},
+		SpeedStep{Level: 700, Gravity: MaxGravity, Delays: Delays{Entry: 16, LineClear: 12, Lock: 30}},
+		SpeedStep{Level: 800, Gravity: MaxGravity, Delays: Delays{Entry: 12, LineClear: 6, Lock: 30}},
+		SpeedStep{Level: 900, Gravity: MaxGravity, Delays: Delays{Entry: 12, LineClear: 6, Lock: 17}},
+	)
+}()
+
+// masterMaxLevel ends a Master game.
+const masterMaxLevel = 999
+
+// masterGrades are the scores each grade takes, lowest first.
+var masterGrades = []struct {
+	name  string
+	score int
+}{
+	{"9", 0}, {"8", 400}, {"7", 800}, {"6", 1400}, {"5", 2000}, {"4", 3500},
+	{"3", 5500}, {"2", 8000}, {"1", 12000}, {"S1", 16000}, {"S2", 22000},
+	{"S3", 30000}, {"S4", 40000}, {"S5", 52000}, {"S6", 66000}, {"S7", 82000},
+	{"S8", 100000}, {"S9", 120000},
+}
+
+// masterCheckpoints are what it takes to stay in the running for GM: the
+// score and the time by which each level has to be reached.
+var masterCheckpoints = []struct {
+	level int
+	score int
+	time  time.Duration
+}{
+	{300, 12000, 4*time.Minute + 15*time.Second},
+	{500, 40000, 7*time.Minute + 30*time.Second},
+	{masterMaxLevel, 126000, 13*time.Minute + 30*time.Second},
+}
+
+// masterRun is the state of a Master game on top of the usual.
+type masterRun struct {
+	Missed bool `json:"missed"` // A GM checkpoint was missed
+	GM     bool `json:"gm"`     // Every checkpoint was met
+}
+
+// Grade is the grade a Master game has earned so far, or "" in other modes.
+func (g *Game) Grade() string {
+	if g.master == nil {
+		return ""
+	}
+	if g.master.GM {
+		return "GM"
+	}
+	grade := masterGrades[0].name
+	for _, gr := range masterGrades {
+		if g.Score >= gr.score {
+			grade = gr.name
+		}
+	}
+	return grade
+}
+
+// masterStop is the level the next section stop is at.
+func masterStop(level int) int {
+	return min(level/100*100+99, masterMaxLevel-1)
+}
+
+// advanceMasterLevel moves the level on after a lock: one for the piece
+// unless it sits at a section stop, one more per cleared line.
+func (g *Game) advanceMasterLevel(lines int) {
+	before := g.Level
+	if g.Level != masterStop(g.Level) {
+		g.Level++
+	}
+	g.Level = min(g.Level+lines, masterMaxLevel)
+	if g.Level/100 > before/100 {
+		g.emit(LevelUpEvent{Level: g.Level})
+	}
+
+	for _, cp := range masterCheckpoints {
+		if before < cp.level && g.Level >= cp.level && (g.Score < cp.score || g.Elapsed() > cp.time) {
+			g.master.Missed = true
+		}
+	}
+	if g.Level == masterMaxLevel && !g.master.Missed {
+		g.master.GM = true
+	}
+}
@@ -0,0 +1,163 @@
+package game
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMasterSpeed(t *testing.T) {
+	early := Delays{Entry: 25, LineClear: 40, Lock: 30}
+	for _, c := range []struct {
+		level   int
+		gravity float64
+		delays  Delays
+	}{
+		{0, 4.0 / 256, early},
+		{29, 4.0 / 256, early},
+		{30, 6.0 / 256, early},
+		{199, 144.0 / 256, early},
+		{200, 4.0 / 256, early}, // The drop back down at 200
+		{251, 1, early},
+		{499, 3, early},
+		{500, MaxGravity, Delays{Entry: 25, LineClear: 25, Lock: 30}},
+		{699, MaxGravity, Delays{Entry: 25, LineClear: 16, Lock: 30}},
+		{700, MaxGravity, Delays{Entry: 16, LineClear: 12, Lock: 30}},
+		{800, MaxGravity, Delays{Entry: 12, LineClear: 6, Lock: 30}},
+		{899, MaxGravity, Delays{Entry: 12, LineClear: 6, Lock: 30}},
+		{900, MaxGravity, Delays{Entry: 12, LineClear: 6, Lock: 17}},
+		{999, MaxGravity, Delays{Entry: 12, LineClear: 6, Lock: 17}},
+	} {
+		g := NewGame(nil, nil)
+		g.SetMode(Master)
+		g.Level = c.level
+		if got := g.gravity(); got != c.gravity {
+			t.Errorf("level %d: %v G, want %v", c.level, got, c.gravity)
+		}
+		if got := g.delays(); got != c.delays {
+			t.Errorf("level %d: delays %+v, want %+v", c.level, got, c.delays)
+		}
+	}
+}
+
+// masterGame is a Master game with its first piece in play.
+func masterGame() *Game {
+	g := NewGame(nil, nil)
+	g.SetMode(Master)
+	g.SetSeed(3)
+	g.StartGame()
+	return g
+}
+
+// lockPiece hard drops the piece in play onto an empty board, clearing the
+// bottom row with it if clear is set, and waits for the next piece.
+func lockPiece(t *testing.T, g *Game, clear bool) {
+	t.Helper()
+	g.Playfield = Field{}
+	if clear {
+		landed := Landing(&g.Playfield, g.Current.placement())
+		for x := 0; x < PlayWidth; x++ {
+			g.Playfield[x][0] = int(Garbage)
+		}
+		for _, c := range landed.Cells() {
+			if c.Y == 0 {
+				g.Playfield[c.X][0] = 0
+			}
+		}
+	}
+	g.applyAction(ActionHardDrop)
+	for i := 0; g.Current == nil && g.State == Playing && i < 120; i++ {
+		g.Step()
+	}
+	if g.Current == nil && g.State == Playing {
+		t.Fatal("no piece after the lock")
+	}
+}
+
+func TestMasterSectionStop(t *testing.T) {
+	g := masterGame()
+	var levelUps []int
+	g.Subscribe(func(e Event) {
+		if ev, ok := e.(LevelUpEvent); ok {
+			levelUps = append(levelUps, ev.Level)
+		}
+	})
+
+	// A single at 97 takes the level to the stop at 99, and pieces alone
+	// leave it there
+	g.Level = 97
+	lockPiece(t, g, true)
+	if g.Level != 99 {
+		t.Fatalf("level %d after a single at 97, want 99", g.Level)
+	}
+	for i := 0; i < 3; i++ {
+		lockPiece(t, g, false)
+		if g.Level != 99 {
+			t.Fatalf("a piece moved the level off the stop to %d", g.Level)
+		}
+	}
+	if len(levelUps) != 0 {
+		t.Fatalf("leveled up to %v inside the section", levelUps)
+	}
+
+	// Only the next clear crosses into the next section
+	lockPiece(t, g, true)
+	if g.Level != 100 || len(levelUps) != 1 || levelUps[0] != 100 {
+		t.Fatalf("level %d, level ups %v after a single at the stop, want 100", g.Level, levelUps)
+	}
+	lockPiece(t, g, false)
+	if g.Level != 101 {
+		t.Fatalf("level %d after a piece at 100, want 101", g.Level)
+	}
+
+	// The last stop is 998; a clear from there ends the game
+	g.Level = 997
+	lockPiece(t, g, false)
+	lockPiece(t, g, false)
+	if g.Level != 998 {
+		t.Fatalf("level %d at the end, want the stop at 998", g.Level)
+	}
+	lockPiece(t, g, true)
+	if g.Level != masterMaxLevel || g.State != GameOver {
+		t.Fatalf("level %d, state %v after a clear at 998, want 999 and the end", g.Level, g.State)
+	}
+}
+
+func TestMasterCheckpoints(t *testing.T) {
+	// Reaching 300 with a clear from the stop at 299: the checkpoint wants
+	// 12000 points within 4:15
+	cross := func(score int, played time.Duration) *Game {
+		g := masterGame()
+		g.Level, g.Score = 299, score
+		g.playFrames = int(played / TickRate)
+		lockPiece(t, g, true)
+		if g.Level != 300 {
+			t.Fatalf("level %d after a single at 299", g.Level)
+		}
+		return g
+	}
+	if g := cross(12000, 4*time.Minute); g.master.Missed {
+		t.Error("a run with the score in time missed the checkpoint")
+	}
+	if g := cross(11000, 4*time.Minute); !g.master.Missed {
+		t.Error("a run short of the score made the checkpoint")
+	}
+	if g := cross(12000, 4*time.Minute+16*time.Second); !g.master.Missed {
+		t.Error("a run out of time made the checkpoint")
+	}
+
+	// Finishing earns GM only with every checkpoint met
+	finish := func(missed bool) *Game {
+		g := masterGame()
+		g.Level, g.Score = 998, 130000
+		g.playFrames = int(13 * time.Minute / TickRate)
+		g.master.Missed = missed
+		lockPiece(t, g, true)
+		return g
+	}
+	if g := finish(false); !g.master.GM || g.Grade() != "GM" {
+		t.Errorf("a clean finish graded %q, want GM", g.Grade())
+	}
+	if g := finish(true); g.master.GM || g.Grade() != "S9" {
+		t.Errorf("a finish after a missed checkpoint graded %q, want S9", g.Grade())
+	}
+}
@@ -0,0 +1,152 @@
+package game
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// --- Menus --------------------------------------------------------------------
+
+// newMenuList is a list styled like the rest of the menus.
+func newMenuList() *tview.List {
+	return tview.NewList().
+		SetHighlightFullLine(true).
+		SetMainTextColor(tcell.ColorWhite).
+		SetSecondaryTextColor(tcell.ColorGray).
+		SetSelectedTextColor(tcell.ColorBlack).
+		SetSelectedBackgroundColor(tcell.ColorYellow)
+}
+
+// addMainMenu adds the page the game starts on.
+func (ui *screens) addMainMenu() {
+	ui.menu = newMenuList()
+
+	title := tview.NewTextView().SetText("T E T R I S").SetTextAlign(tview.AlignCenter).SetTextColor(tcell.ColorGreen)
+	body := tview.NewFlex().SetDirection(tview.FlexRow)
+	body.AddItem(tview.NewBox(), 1, 0, false)
+	body.AddItem(title, 2, 0, false)
+	body.AddItem(ui.menu, 0, 1, true)
+	body.AddItem(&noticeLine{Box: tview.NewBox(), src: ui.g}, 1, 0, false)
+	ui.add(pageMenu, "TETRIS", body, ui.menu, "↑↓ choose • ENTER select • Q quit")
+
+	ui.menu.SetInputCapture(func(ev *tcell.EventKey) *tcell.EventKey {
+		if ev.Key() == tcell.KeyRune && (ev.Rune() == 'q' || ev.Rune() == 'Q') {
+			ui.quit()
+			return nil
+		}
+		return ev
+	})
+}
+
+// refreshMenu lists what the main menu offers right now.
+func (ui *screens) refreshMenu() {
+	g := ui.g
+	view := g.Snapshot()
+	ui.menu.Clear()
+	if view != nil && view.Continue != "" {
+		ui.menu.AddItem("Continue", "The "+view.Continue+" game you left", 0, func() { g.request(g.continueSaved) })
+	}
+	ui.menu.AddItem("Play", "Pick a mode", 0, func() { ui.show(pagePlay) })
+	ui.menu.AddItem("Practice", Practice.Description, 0, func() { g.request(func() { g.startMode(Practice) }) })
+	if g.battleBot != nil {
+		ui.menu.AddItem("Versus", Versus.Description, 0, func() { ui.show(pageVersus) })
+	}
+	if len(g.puzzles) > 0 {
+		ui.menu.AddItem("Puzzles", "Drill setups and openers", 0, func() {
+			g.request(func() { g.puzzle, g.State = nil, PuzzleSelect })
+		})
+	}
+	if ui.scores != nil {
+		ui.menu.AddItem("Leaderboard", "The best games of each mode", 0, func() { ui.show(pageLeaderboard) })
+	}
+	ui.menu.AddItem("Settings", "Controls, audio, visuals and handling", 0, func() { ui.show(pageSettings) })
+	if ui.replays != nil {
+		ui.menu.AddItem("Replays", "Watch finished games again", 0, func() { ui.show(pageReplays) })
+	}
+	ui.menu.AddItem("Quit", "", 0, ui.quit)
+}
+
+// noticeLine shows the game's notice under the main menu, e.g. a new high
+// score.
+type noticeLine struct {
+	*tview.Box
+	src Source
+}
+
+// Draw draws the notice of the last snapshot.
+func (n *noticeLine) Draw(screen tcell.Screen) {
+	n.Box.DrawForSubclass(screen, n)
+	x, y, width, _ := n.GetInnerRect()
+	if view := n.src.Snapshot(); view != nil && view.Notice != "" {
+		drawCenteredText(screen, x, y, width, view.Notice, tcell.StyleDefault.Foreground(tcell.ColorAqua))
+	}
+}
+
+// quit leaves the way Ctrl+C does, so the loop saves first.
+func (ui *screens) quit() {
+	ui.g.app.QueueEvent(tcell.NewEventKey(tcell.KeyCtrlC, 0, tcell.ModCtrl))
+}
+
+// back returns to the main menu.
+func (ui *screens) back() {
+	ui.show(pageMenu)
+}
+
+// addPlayMenu adds the mode picker behind Play.
+func (ui *screens) addPlayMenu() {
+	g := ui.g
+	list := newMenuList()
+	for _, m := range Modes {
+		if m.Practice {
+			continue // It has an entry of its own
+		}
+		list.AddItem(strings.ToUpper(m.Name[:1])+m.Name[1:], m.Description, 0, func() {
+			g.request(func() { g.startMode(m) })
+		})
+		if m.Name == g.Mode.Name {
+			list.SetCurrentItem(list.GetItemCount() - 1)
+		}
+	}
+	list.SetDoneFunc(ui.back)
+	ui.add(pagePlay, "PLAY", list, list, "ENTER play • ESC back")
+}
+
+// startMode starts a game in a built-in mode, and a new match in Versus.
+// The mode the game was set up with keeps its settings, e.g. a stack
+// hidden from the command line.
+func (g *Game) startMode(m Mode) {
+	if m.Name != g.Mode.Name {
+		g.SetMode(m)
+	}
+	g.puzzle, g.battle = nil, nil
+	g.StartGame()
+}
+
+// addPuzzles adds the puzzle browser: the puzzles, and the goal and
+// description of the selected one.
+func (ui *screens) addPuzzles() {
+	g := ui.g
+	list := newMenuList()
+	details := tview.NewTextView().SetWordWrap(true).SetDynamicColors(true)
+	describe := func(i int) {
+		if i >= 0 && i < len(g.puzzles) {
+			p := g.puzzles[i]
+			details.SetText(fmt.Sprintf("[teal]Goal: %s[-]\n%s", p.Goal, tview.Escape(p.Description)))
+		}
+	}
+	for _, p := range g.puzzles {
+		list.AddItem(p.Name, "", 0, func() { g.request(func() { g.StartPuzzle(p) }) })
+	}
+	list.ShowSecondaryText(false)
+	list.SetChangedFunc(func(i int, _, _ string, _ rune) { describe(i) })
+	list.SetDoneFunc(func() { g.request(func() { g.State = MainMenu }) })
+	describe(0)
+
+	body := tview.NewFlex().SetDirection(tview.FlexRow)
+	body.AddItem(list, 0, 1, true)
+	body.AddItem(details, 6, 0, false)
+	ui.add(pagePuzzles, "PUZZLES", body, list, "ENTER play • ESC back")
+}
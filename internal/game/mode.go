@@ -0,0 +1,175 @@
+package game
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// --- Game Modes ---------------------------------------------------------------
+
+// Mode is a ruleset: what ends the game besides topping out, how fast it
+// goes and how long the engine waits around each piece.
+type Mode struct {
+	Name        string
+	Description string
+	LineGoal    int           // Game is won after this many lines (0 = endless)
+	TimeLimit   time.Duration // Game ends after this much play time (0 = none)
+	Practice    bool          // Hints available, speed stays at level 1
+	Master      bool          // TGM rules: levels 0-999 and grades
+	Versus      bool          // A match against the bot, see battle.go
+	Scorer      Scorer        // Scoring rules; nil for the guideline
+	Garbage     *GarbageRules // Attacks and garbage; nil for none
+	Delays      Delays        // Delays around each piece, unless Speed says otherwise
+	Speed       []SpeedStep   // Gravity and delays by level; nil for the guideline curve
+	Stack       Stack         // How long locked blocks stay on screen
+}
+
+// Stack says how long locked blocks stay on screen. The zero value keeps
+// them there; the whole board comes back once the game is over.
+type Stack struct {
+	Invisible bool          // Blocks vanish the moment they lock
+	FadeAfter time.Duration // Blocks fade out this long after they lock (0 = never)
+}
+
+// Hidden reports whether locked blocks ever leave the screen.
+func (s Stack) Hidden() bool {
+	return s.Invisible || s.FadeAfter > 0
+}
+
+// Delays are the pauses around each piece, in frames (1/60 s). Zero skips a
+// delay.
+type Delays struct {
+	LineClear int // Cleared rows flash this long before they collapse
+	Entry     int // The next piece appears this long after the last one settled (ARE)
+	Lock      int // A piece resting on the stack locks after this long; 0 locks on the next gravity pull
+}
+
+// SpeedStep is the speed from Level on, up to the next step.
+type SpeedStep struct {
+	Level   int
+	Gravity float64 // Rows per frame (G)
+	Delays  Delays  // Zero for the mode's Delays
+}
+
+// DefaultDelays are the delays of the built-in modes.
+var DefaultDelays = Delays{LineClear: 30, Entry: 6, Lock: int(LockDelay / TickRate)}
+
+// Built-in modes.
+var (
+	Marathon = Mode{Name: "marathon", Description: "Endless, speeds up every 10 lines", Delays: DefaultDelays}
+	Sprint   = Mode{Name: "sprint", Description: "Clear 40 lines as fast as you can", LineGoal: 40, Delays: DefaultDelays}
+	Ultra    = Mode{Name: "ultra", Description: "Score as much as you can in 2 minutes", TimeLimit: 2 * time.Minute, Delays: DefaultDelays}
+	Practice = Mode{Name: "practice", Description: "No speed-up, placement hints on H", Practice: true, Delays: DefaultDelays}
+	Master   = Mode{Name: "master", Description: "Levels 0-999, 20G from 500, earn a grade", Master: true, Scorer: TGMScorer{}, Delays: masterSpeed[0].Delays, Speed: masterSpeed}
+
+	Fading    = Mode{Name: "fading", Description: "Marathon, locked blocks fade after 5 seconds", Delays: DefaultDelays, Stack: Stack{FadeAfter: 5 * time.Second}}
+	Invisible = Mode{Name: "invisible", Description: "Marathon, locked blocks vanish at once", Delays: DefaultDelays, Stack: Stack{Invisible: true}}
+
+	// Versus keeps to the guideline's level 1 speed; the garbage is pressure
+	// enough
+	Versus = Mode{Name: "versus", Description: "Battle the bot, trading garbage", Versus: true, Garbage: &GuidelineGarbage,
+		Delays: DefaultDelays, Speed: []SpeedStep{{Level: 1, Gravity: GravityForLevel(1)}}}
+)
+
+// Modes lists every built-in mode played alone, in menu order. Versus has
+// a page of its own and no leaderboard.
+var Modes = []Mode{Marathon, Sprint, Ultra, Practice, Master, Fading, Invisible}
+
+// ModeByName looks up a built-in mode, Versus included, ignoring case.
+func ModeByName(name string) (Mode, error) {
+	all := append(Modes[:len(Modes):len(Modes)], Versus)
+	for _, m := range all {
+		if strings.EqualFold(m.Name, name) {
+			return m, nil
+		}
+	}
+	names := make([]string, len(all))
+	for i, m := range all {
+		names[i] = m.Name
+	}
+	return Mode{}, fmt.Errorf("unknown mode %q (available: %s)", name, strings.Join(names, ", "))
+}
+
+// Reasons a mode ends the game, reported by GameOverEvent.
+const (
+	ReasonGoalReached = "goal reached"
+	ReasonTimeUp      = "time up"
+)
+
+// SetMode picks the ruleset for the next StartGame.
+func (g *Game) SetMode(m Mode) {
+	g.Mode = m
+}
+
+// scoring is the mode's Scorer, the guideline unless it says otherwise.
+func (m Mode) scoring() Scorer {
+	if m.Scorer == nil {
+		return GuidelineScorer{}
+	}
+	return m.Scorer
+}
+
+// speedStep is the step of the mode's speed table the current level is on.
+func (g *Game) speedStep() (SpeedStep, bool) {
+	var step SpeedStep
+	found := false
+	for _, st := range g.Mode.Speed {
+		if st.Level > g.Level {
+			break
+		}
+		step, found = st, true
+	}
+	return step, found
+}
+
+// gravity is how many rows per frame pieces fall right now.
+func (g *Game) gravity() float64 {
+	if step, ok := g.speedStep(); ok {
+		return step.Gravity
+	}
+	return GravityForLevel(g.Level)
+}
+
+// delays are the delays around the current piece.
+func (g *Game) delays() Delays {
+	if step, ok := g.speedStep(); ok && step.Delays != (Delays{}) {
+		return step.Delays
+	}
+	return g.Mode.Delays
+}
+
+// fixedSpeed reports whether gravity stays at level 1: in practice and in
+// puzzles.
+func (g *Game) fixedSpeed() bool {
+	return g.Mode.Practice || g.puzzle != nil
+}
+
+// Elapsed is how long the current game has been played, pauses excluded.
+func (g *Game) Elapsed() time.Duration {
+	return time.Duration(g.playFrames) * TickRate
+}
+
+// tickClock counts one frame of play time and ends timed modes.
+func (g *Game) tickClock() {
+	g.playFrames++
+	if g.Mode.TimeLimit > 0 && g.puzzle == nil && g.Elapsed() >= g.Mode.TimeLimit {
+		g.endGame(ReasonTimeUp)
+	}
+}
+
+// checkGoal ends the game once the mode's line goal (or the puzzle's goal)
+// is met.
+func (g *Game) checkGoal() {
+	if g.puzzle != nil {
+		g.checkPuzzle()
+		return
+	}
+	if g.master != nil && g.Level >= masterMaxLevel {
+		g.endGame(ReasonGoalReached)
+		return
+	}
+	if g.Mode.LineGoal > 0 && g.LinesCleared >= g.Mode.LineGoal {
+		g.endGame(ReasonGoalReached)
+	}
+}
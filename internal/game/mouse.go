@@ -0,0 +1,171 @@
+package game
+
+import (
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// --- Mouse --------------------------------------------------------------------
+//
+// tview hands mouse events to the primitive under the pointer. The menu
+// pages are tview widgets and take clicks themselves. The board's
+// primitives know where they drew what, so they turn a press into a Click
+// naming what was hit, and the loop acts on it like on a key. Only a game
+// takes clicks; a watched one stays read-only. The Controls settings turn
+// the mouse and click-to-drop on and off.
+
+// ClickTarget is what a click landed on.
+type ClickTarget int
+
+const (
+	ClickPause   ClickTarget = iota // The pause button, or the pause screen
+	ClickOverlay                    // The game over screen
+	ClickColumn                     // A column of the board (Index), to drop the piece there
+	ClickRotate                     // The board with the right button, to rotate the piece
+)
+
+// Click is a mouse press on something the game offers.
+type Click struct {
+	Target ClickTarget
+	Index  int
+}
+
+// clickable is a Source that takes clicks.
+type clickable interface {
+	Click(c Click)
+}
+
+// Click passes a click to the loop. It is safe to call from any goroutine,
+// and drops the click if the loop is behind.
+func (g *Game) Click(c Click) {
+	select {
+	case g.clicks <- c:
+	default:
+	}
+}
+
+// handleClick acts on a click like on the matching keys.
+func (g *Game) handleClick(c Click) {
+	controls := g.settings.Controls
+	if !controls.Mouse {
+		return
+	}
+	switch g.State {
+	case GameOver:
+		if c.Target == ClickOverlay {
+			g.HandleInput(tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone))
+		}
+	case Paused:
+		if c.Target == ClickPause {
+			g.setPaused(false)
+		}
+	case Playing:
+		switch {
+		case c.Target == ClickPause:
+			g.setPaused(true)
+		case !controls.ClickToDrop || g.autoplay != nil:
+		case c.Target == ClickRotate:
+			g.act(ActionRotate)
+		case c.Target == ClickColumn:
+			g.dropAt(c.Index)
+		}
+	}
+}
+
+// dropAt shifts the piece until it covers column x, as far as it gets, and
+// drops it.
+func (g *Game) dropAt(x int) {
+	if g.Current == nil {
+		return
+	}
+	for range PlayWidth {
+		left, right := PlayWidth, -1
+		for _, c := range g.Current.placement().Cells() {
+			left, right = min(left, c.X), max(right, c.X)
+		}
+		before := g.Current.Position.X
+		switch {
+		case x < left:
+			g.act(ActionMoveLeft)
+		case x > right:
+			g.act(ActionMoveRight)
+		}
+		if g.Current == nil || g.Current.Position.X == before {
+			break
+		}
+	}
+	g.act(ActionHardDrop)
+}
+
+// --- Hit Testing --------------------------------------------------------------
+
+// MouseHandler turns presses on the playfield into clicks.
+func (p *PlayfieldPrimitive) MouseHandler() func(action tview.MouseAction, event *tcell.EventMouse, setFocus func(p tview.Primitive)) (consumed bool, capture tview.Primitive) {
+	return p.WrapMouseHandler(func(action tview.MouseAction, event *tcell.EventMouse, setFocus func(p tview.Primitive)) (consumed bool, capture tview.Primitive) {
+		target, ok := p.Source.(clickable)
+		view := p.Source.Snapshot()
+		mx, my := event.Position()
+		if !ok || view == nil || !view.Mouse || !p.InInnerRect(mx, my) {
+			return false, nil
+		}
+		x0, _, width, height := p.GetInnerRect()
+		x := mx - x0
+
+		switch action {
+		case tview.MouseRightClick:
+			if view.State == Playing {
+				target.Click(Click{Target: ClickRotate})
+			}
+			return true, nil
+		case tview.MouseLeftClick, tview.MouseLeftDoubleClick:
+		default:
+			return false, nil
+		}
+
+		switch view.State {
+		case Paused:
+			target.Click(Click{Target: ClickPause})
+		case GameOver:
+			target.Click(Click{Target: ClickOverlay})
+		case Playing:
+			startX, _ := boardOrigin(0, 0, width, height, p.cells)
+			if col := (x - startX) / p.cells.W; x >= startX && col < PlayWidth {
+				target.Click(Click{Target: ClickColumn, Index: col})
+			}
+		}
+		return true, nil
+	})
+}
+
+// MouseHandler presses the pause button on the status panel.
+func (s *StatusPrimitive) MouseHandler() func(action tview.MouseAction, event *tcell.EventMouse, setFocus func(p tview.Primitive)) (consumed bool, capture tview.Primitive) {
+	return s.WrapMouseHandler(func(action tview.MouseAction, event *tcell.EventMouse, setFocus func(p tview.Primitive)) (consumed bool, capture tview.Primitive) {
+		target, ok := s.Source.(clickable)
+		view := s.Source.Snapshot()
+		if !ok || view == nil || (action != tview.MouseLeftClick && action != tview.MouseLeftDoubleClick) {
+			return false, nil
+		}
+		label := pauseButton(view)
+		x0, y0, width, _ := s.GetInnerRect()
+		mx, my := event.Position()
+		if label == "" || my != y0 || mx < x0+width-len(label) || mx >= x0+width {
+			return false, nil
+		}
+		target.Click(Click{Target: ClickPause})
+		return true, nil
+	})
+}
+
+// pauseButton is the label of the pause button, "" when there is none.
+func pauseButton(view *Snapshot) string {
+	if !view.Mouse {
+		return ""
+	}
+	switch view.State {
+	case Playing:
+		return "[Pause]"
+	case Paused:
+		return "[Resume]"
+	}
+	return ""
+}
@@ -0,0 +1,159 @@
+package game
+
+import (
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// --- Pages --------------------------------------------------------------------
+//
+// Outside of a game the screen is a set of tview pages: the main menu and
+// the pages it leads to. Like every primitive they belong to tview's
+// goroutine. Whatever they change in the game goes to the loop as a request,
+// and the loop in turn switches pages when the game starts or ends.
+
+// Page names.
+const (
+	pageMenu        = "menu"
+	pagePlay        = "play"
+	pagePuzzles     = "puzzles"
+	pageLeaderboard = "leaderboard"
+	pageSettings    = "settings"
+	pageReplays     = "replays"
+	pageVersus      = "versus"
+	pageReplay      = "replay" // A replay playing back
+	pageBoard       = "board"  // The game itself
+)
+
+// The menu pages are pageWidth wide. They need pageHeight rows, which is
+// what the tallest, Settings, takes to show all of its items and buttons.
+const (
+	pageWidth  = 60
+	pageHeight = 25
+)
+
+// screens holds the pages and what is on them. Only tview's goroutine may
+// touch it.
+type screens struct {
+	g     *Game
+	pages *tview.Pages
+	front string                     // Page on screen
+	focus map[string]tview.Primitive // What takes the keys on each page
+
+	menu        *tview.List
+	scores      *leaderboard
+	settings    Settings // As last changed on the Settings page
+	replays     *tview.List
+	replayFiles []string // Replay behind each entry of the list
+	stopReplay  func()   // Ends the replay playing back, if one is
+}
+
+// newScreens builds every page. The game is not running yet, so this is the
+// one time the drawing side may read it directly.
+func newScreens(g *Game) *screens {
+	ui := &screens{
+		g:        g,
+		pages:    tview.NewPages(),
+		focus:    make(map[string]tview.Primitive),
+		settings: g.settings,
+	}
+	ui.addMainMenu()
+	ui.addPlayMenu()
+	ui.addPuzzles()
+	ui.addLeaderboard()
+	ui.addSettings()
+	ui.addReplays()
+	ui.addVersus()
+	ui.pages.AddPage(pageBoard, NewBoard(g), true, false)
+	return ui
+}
+
+// add puts a page in a bordered frame with a line of help under it.
+func (ui *screens) add(name, title string, body, focus tview.Primitive, help string) {
+	frame := tview.NewFlex().SetDirection(tview.FlexRow)
+	frame.SetBorder(true).SetTitle(" "+title+" ").SetBorderPadding(0, 0, 1, 1)
+	frame.AddItem(body, 0, 1, true)
+	frame.AddItem(tview.NewTextView().SetText(help).SetTextAlign(tview.AlignCenter).SetTextColor(tcell.ColorGray), 1, 0, false)
+
+	// Pages are as wide as the standard board and as tall as the terminal,
+	// with the board's padding
+	column := tview.NewFlex().SetDirection(tview.FlexRow)
+	column.AddItem(tview.NewBox(), 1, 0, false)
+	column.AddItem(frame, 0, 1, true)
+	column.AddItem(tview.NewBox(), 1, 0, false)
+	page := tview.NewFlex()
+	page.AddItem(column, pageWidth, 0, true)
+	page.AddItem(tview.NewBox(), 0, 1, false)
+
+	ui.pages.AddPage(name, &menuPage{page}, true, false)
+	ui.focus[name] = focus
+}
+
+// menuPage is a menu page that, like the board, says the terminal is too
+// small instead of cutting itself off.
+type menuPage struct {
+	*tview.Flex
+}
+
+// Draw implements tview.Primitive.
+func (p *menuPage) Draw(screen tcell.Screen) {
+	x, y, width, height := p.GetRect()
+	if width < pageWidth || height < pageHeight {
+		// The terminal it takes, margin included
+		drawTooSmall(screen, x, y, width, height, x+pageWidth, y+pageHeight)
+		return
+	}
+	p.Flex.Draw(screen)
+}
+
+// show brings a page to the front, with what it shows brought up to date.
+func (ui *screens) show(name string) {
+	if ui.stopReplay != nil && name != pageReplay {
+		ui.stopReplay()
+		ui.stopReplay = nil
+	}
+	switch name {
+	case pageMenu:
+		ui.refreshMenu()
+	case pageLeaderboard:
+		ui.scores.refresh()
+	case pageReplays:
+		ui.refreshReplays()
+	}
+	ui.front = name
+	ui.pages.SwitchToPage(name)
+	if focus := ui.focus[name]; focus != nil {
+		ui.g.app.SetFocus(focus)
+	}
+}
+
+// request has the loop run f, on the game's goroutine. It is dropped if
+// the loop is behind.
+func (g *Game) request(f func()) {
+	select {
+	case g.requests <- f:
+	default:
+	}
+}
+
+// pageFor is the page a state of the game is shown on.
+func pageFor(s GameState) string {
+	switch s {
+	case MainMenu:
+		return pageMenu
+	case PuzzleSelect:
+		return pagePuzzles
+	}
+	return pageBoard
+}
+
+// followState switches to the page for the game's state when it changes.
+func (g *Game) followState() {
+	page := pageFor(g.State)
+	if g.ui == nil || page == g.statePage {
+		return
+	}
+	g.statePage = page
+	ui := g.ui
+	g.app.QueueUpdateDraw(func() { ui.show(page) })
+}
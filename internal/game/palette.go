@@ -0,0 +1,107 @@
+package game
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// --- Palettes -----------------------------------------------------------------
+//
+// The standard colors tell S from Z and J from L by red and green alone. The
+// other palettes are picked for the common kinds of color blindness, mostly
+// from the Okabe-Ito set, and keep each confusable pair apart in lightness
+// as well. Patterns go further: every piece gets a glyph pair of its own,
+// so the pieces can be told apart with no color at all.
+
+// Palette is a set of piece colors.
+type Palette struct {
+	Name        string
+	Description string
+	Colors      map[PieceID]tcell.Color
+}
+
+// Built-in palettes.
+var (
+	StandardPalette = Palette{Name: "standard", Description: "The usual guideline colors", Colors: PieceColors}
+
+	DeuteranopiaPalette = Palette{Name: "deuteranopia", Description: "Green-weak vision", Colors: map[PieceID]tcell.Color{
+		I: tcell.NewHexColor(0x56b4e9), // Sky blue
+		O: tcell.NewHexColor(0xf0e442), // Yellow
+		T: tcell.NewHexColor(0xcc79a7), // Reddish purple
+		S: tcell.NewHexColor(0x0072b2), // Blue
+		Z: tcell.NewHexColor(0xe69f00), // Orange
+		J: tcell.NewHexColor(0xf5f5f5), // White
+		L: tcell.NewHexColor(0xa04000), // Brown
+
+		Garbage: tcell.ColorGray,
+	}}
+
+	ProtanopiaPalette = Palette{Name: "protanopia", Description: "Red-weak vision", Colors: map[PieceID]tcell.Color{
+		I: tcell.NewHexColor(0x56b4e9), // Sky blue
+		O: tcell.NewHexColor(0xf0e442), // Yellow
+		T: tcell.NewHexColor(0xa98bff), // Violet, as reds look dark
+		S: tcell.NewHexColor(0x0072b2), // Blue
+		Z: tcell.NewHexColor(0xffb000), // Amber
+		J: tcell.NewHexColor(0xf5f5f5), // White
+		L: tcell.NewHexColor(0x9a6a00), // Dark amber
+
+		Garbage: tcell.ColorGray,
+	}}
+
+	TritanopiaPalette = Palette{Name: "tritanopia", Description: "Blue-weak vision", Colors: map[PieceID]tcell.Color{
+		I: tcell.NewHexColor(0x00c0c0), // Cyan
+		O: tcell.NewHexColor(0xf5f5f5), // White
+		T: tcell.NewHexColor(0xc0c0c0), // Silver
+		S: tcell.NewHexColor(0x009e73), // Bluish green
+		Z: tcell.NewHexColor(0xd55e00), // Vermillion
+		J: tcell.NewHexColor(0x005a8c), // Dark blue
+		L: tcell.NewHexColor(0xff9dc8), // Pink
+
+		Garbage: tcell.ColorGray,
+	}}
+)
+
+// Palettes lists every built-in palette, in menu order.
+var Palettes = []Palette{StandardPalette, DeuteranopiaPalette, ProtanopiaPalette, TritanopiaPalette}
+
+// PaletteByName looks up a built-in palette, ignoring case. The empty name
+// is the standard palette.
+func PaletteByName(name string) (Palette, error) {
+	if name == "" {
+		return StandardPalette, nil
+	}
+	for _, p := range Palettes {
+		if strings.EqualFold(p.Name, name) {
+			return p, nil
+		}
+	}
+	names := make([]string, len(Palettes))
+	for i, p := range Palettes {
+		names[i] = p.Name
+	}
+	return Palette{}, fmt.Errorf("unknown palette %q (available: %s)", name, strings.Join(names, ", "))
+}
+
+// Color is the color of piece id, gray for anything else.
+func (p *Palette) Color(id PieceID) tcell.Color {
+	if c, ok := p.Colors[id]; ok {
+		return c
+	}
+	return tcell.ColorGray
+}
+
+// PieceGlyphs are drawn on the blocks of each piece when patterns are on.
+// Mirror pieces get mirror glyphs.
+var PieceGlyphs = map[PieceID][2]rune{
+	I: {'[', ']'},
+	O: {'#', '#'},
+	T: {'<', '>'},
+	S: {'/', '/'},
+	Z: {'\\', '\\'},
+	J: {'(', '('},
+	L: {')', ')'},
+
+	Garbage: {':', ':'},
+}
@@ -1,6 +1,8 @@
 package game
 
 import (
+	"fmt"
+	"math"
 	"time"
 )
 
@@ -18,205 +20,268 @@ func (g *Game) lockPiece() {
 
 	// Paint blocks into playfield
 	p := g.Current
-	for _, b := range p.Blocks {
+	var cells [4]Point
+	for i, b := range p.Blocks {
 		// Calculate absolute position in playfield
 		x, y := p.Position.X+b.X, p.Position.Y+b.Y
+		cells[i] = Point{X: x, Y: y}
 
 		// Only add blocks that are in the valid playfield area
 		if x >= 0 && x < PlayWidth && y >= 0 && y < TotalHeight {
 			g.Playfield[x][y] = int(p.ID)
+			g.lockedAt[x][y] = g.playFrames
 		}
 	}
+	g.emit(LockedEvent{Piece: p.ID, Rotation: p.RotationState, Position: p.Position, Blocks: cells})
+
+	// T-spin corners are checked before the rows collapse
+	g.clearTSpin = g.detectTSpin(p)
+	g.clearing = g.fullRowsUnder(p)
+	g.Current = nil
+
+	// Full rows flash for a while before they go; otherwise the lock is
+	// settled right away
+	if len(g.clearing) > 0 && g.delays().LineClear > 0 {
+		g.setPhase(PhaseLineClear, g.delays().LineClear)
+		return
+	}
+	g.finishLock()
+}
 
-	// Detect, clear lines & score
-	cleared := g.clearLines(p)
-	g.updateScore(cleared, p)
+// finishLock clears the rows the last piece filled, scores them and moves on
+// to the next piece.
+func (g *Game) finishLock() {
+	cleared := len(g.clearing)
+	removeRows(&g.Playfield, g.clearing)
+	removeRows(&g.lockedAt, g.clearing)
+	g.LinesCleared += cleared
+	lock := g.updateScore(cleared, g.clearTSpin)
+	g.clearing, g.clearTSpin = nil, NoTSpin
 
 	// Level up?
-	if g.LinesCleared/10+1 > g.Level {
+	if g.master != nil {
+		g.advanceMasterLevel(cleared)
+	} else if g.LinesCleared/10+1 > g.Level && !g.fixedSpeed() {
 		g.Level = g.LinesCleared/10 + 1
-		g.adjustGravity()
+		g.emit(LevelUpEvent{Level: g.Level})
 	}
 
-	// T-spin detection can be implemented here if needed
-	// Currently just tracking for future scoring enhancements
-	_ = p.ID == T && g.LastMoveWasRotation
-
-	// Clear the current piece reference
-	g.Current = nil
+	// Clears attack, and pieces that clear nothing let garbage in
+	if g.garbage != nil {
+		g.exchangeGarbage(lock)
+	}
 
-	// Spawn next piece
-	g.spawnNext()
+	// Sprint-style modes end as soon as the goal is met
+	g.checkGoal()
+	if g.State == GameOver {
+		g.setPhase(PhaseFalling, 0)
+		return
+	}
 
-	// spawnNext now handles collision checking and game over internally
+	// Wait out the entry delay, if the mode has one, before spawning
+	if g.delays().Entry > 0 {
+		g.setPhase(PhaseEntry, g.delays().Entry)
+		return
+	}
+	g.enterNext()
 }
 
-// --- Line Clearing & Animation ------------------------------------------------
+// --- Delays -------------------------------------------------------------------
+//
+// Between pieces the engine runs through frame-counted phases: cleared rows
+// flash (line clear delay), then the playfield sits empty-handed for a moment
+// (entry delay, ARE). Both are counted in ticks, so they pause with the game
+// and never hold up the loop.
 
-// clearLines returns number of lines cleared by the last lock.
-func (g *Game) clearLines(p *Piece) int {
-	// Check all rows where the piece has blocks
-	rows := make(map[int]struct{})
-	for _, b := range p.Blocks {
-		rows[p.Position.Y+b.Y] = struct{}{}
-	}
+// lineClearFlash is how many frames cleared rows stay in one color while
+// they flash.
+const lineClearFlash = 4
 
-	// Find rows that are completely filled
-	var toClear []int
-	for row := range rows {
-		// Skip rows that are outside the visible playing area
-		if row < 0 || row >= VisibleHeight {
-			continue
-		}
+// maxBuffered caps the actions kept for the next piece.
+const maxBuffered = 8
 
-		full := true
-		for x := 0; x < PlayWidth; x++ {
-			if g.Playfield[x][row] == 0 {
-				full = false
-				break
-			}
-		}
-		if full {
-			toClear = append(toClear, row)
-		}
-	}
+// setPhase switches phases, counting down frames.
+func (g *Game) setPhase(ph Phase, frames int) {
+	g.Phase, g.phaseFrames = ph, frames
+}
 
-	if len(toClear) == 0 {
-		return 0
+// tickDelay counts down one frame of a line clear or entry delay.
+func (g *Game) tickDelay() {
+	if g.Phase == PhaseFalling {
+		return
+	}
+	g.phaseFrames--
+	if g.phaseFrames > 0 {
+		return
+	}
+	switch g.Phase {
+	case PhaseLineClear:
+		g.finishLock()
+	case PhaseEntry:
+		g.enterNext()
 	}
+}
+
+// enterNext spawns the next piece and replays the input buffered while it
+// was on its way.
+func (g *Game) enterNext() {
+	g.setPhase(PhaseFalling, 0)
+	g.spawnNext()
 
-	// Animate the clearing process
-	g.State = Animating
-	frames := 6
-	for i := 0; i < frames; i++ {
-		g.app.QueueUpdateDraw(func() { g.renderClearing(toClear, i%2 == 0) })
-		time.Sleep(time.Millisecond * 80)
+	buffered := g.buffered
+	g.buffered = nil
+	for _, a := range buffered {
+		g.applyAction(a)
 	}
+}
 
-	// Actually remove the rows from bottom to top to avoid overwriting
-	// Sort toClear from low to high for proper clearing
-	for _, row := range toClear {
-		// Shift all rows above the cleared row down within the visible area
-		for y := row; y < VisibleHeight-1; y++ {
-			for x := 0; x < PlayWidth; x++ {
-				g.Playfield[x][y] = g.Playfield[x][y+1]
-			}
-		}
-		// Clear the top row of visible area, or pull from hidden buffer if needed
-		for x := 0; x < PlayWidth; x++ {
-			if VisibleHeight < TotalHeight {
-				// Pull down from hidden buffer
-				g.Playfield[x][VisibleHeight-1] = g.Playfield[x][VisibleHeight]
-				g.Playfield[x][VisibleHeight] = 0
-			} else {
-				g.Playfield[x][VisibleHeight-1] = 0
-			}
-		}
+// bufferAction keeps an action for the piece still to come.
+func (g *Game) bufferAction(a Action) {
+	if a != ActionNone && len(g.buffered) < maxBuffered {
+		g.buffered = append(g.buffered, a)
 	}
-	g.State = Playing
-	g.LinesCleared += len(toClear)
-	return len(toClear)
 }
 
-// renderClearing overlays flashing rows during animation.
-func (g *Game) renderClearing(rows []int, flash bool) {
-	// Update the PlayfieldPrimitive's flashing rows data if available
-	if g.playfieldView != nil {
-		g.playfieldView.flashingRows = rows
-		g.playfieldView.flashOn = flash
+// ClearingRows returns the rows waiting to be cleared and whether they are
+// lit in the current frame of their flash.
+func (g *Game) ClearingRows() ([]int, bool) {
+	if g.Phase != PhaseLineClear {
+		return nil, false
 	}
+	return g.clearing, (g.phaseFrames/lineClearFlash)%2 == 0
+}
 
-	// Render the game state
-	g.render()
+// fullRowsUnder returns the full rows among those the piece covers.
+func (g *Game) fullRowsUnder(p *Piece) []int {
+	rows := make(map[int]struct{})
+	for _, b := range p.Blocks {
+		rows[p.Position.Y+b.Y] = struct{}{}
+	}
+	return fullRows(&g.Playfield, rows)
 }
 
 // --- Scoring & Progression -----------------------------------------------------
 
-// scoring state
-var (
-	basePoints = map[int]int{1: 100, 2: 300, 3: 500, 4: 800}
-	comboBonus = 50
-)
+// dropTally is what the piece in play has earned by dropping so far.
+type dropTally struct {
+	SoftRows   int `json:"soft_rows,omitempty"`
+	HardRows   int `json:"hard_rows,omitempty"`
+	SoftPoints int `json:"soft_points,omitempty"`
+	HardPoints int `json:"hard_points,omitempty"`
+}
 
-// updateScore handles Guideline scoring: line clears, T‑Spins, Combo, B2B.
-func (g *Game) updateScore(linesCleared int, p *Piece) {
-	pts := 0
+// scorer is the rule set the game is scored by, see scoring.go.
+func (g *Game) scorer() Scorer {
+	return g.Mode.scoring()
+}
 
-	// Check for T-spin (only when the piece is a T and the last move was a rotation)
-	isTspin := false
-	if p.ID == T && g.detectTSpin(p) {
-		isTspin = true
+// scoreDrop pays for rows the player dropped the piece.
+func (g *Game) scoreDrop(kind DropKind, rows int) {
+	pts := g.scorer().Drop(kind, rows)
+	switch kind {
+	case SoftDrop:
+		g.drops.SoftRows += rows
+		g.drops.SoftPoints += pts
+	case HardDrop:
+		g.drops.HardRows += rows
+		g.drops.HardPoints += pts
+	}
+	g.Score += pts
+}
+
+// updateScore keeps the combo and back-to-back chains going, has the scorer
+// score the lock and reports what the points were for. It returns the lock
+// as it was scored.
+func (g *Game) updateScore(linesCleared int, spin TSpin) Lock {
+	lock := Lock{
+		Lines: linesCleared, TSpin: spin, Level: g.Level - 1,
+		SoftRows: g.drops.SoftRows, HardRows: g.drops.HardRows,
+	}
+	if g.master != nil {
+		lock.Level = g.Level // Master counts levels from 0 already
 	}
 
-	// Base points calculation
+	wasB2B := g.B2B
 	if linesCleared == 0 {
-		// No lines cleared, no points
-		return
-	} else if isTspin {
-		// T-Spin base: line‑dependent (e.g., 800 for single, 1200 for double)
-		// T-Spin gives higher scores compared to regular line clears
-		if linesCleared == 1 {
-			pts = 800 // T-Spin Single
-		} else if linesCleared == 2 {
-			pts = 1200 // T-Spin Double
-		} else if linesCleared == 3 {
-			pts = 1600 // T-Spin Triple
+		// No lines cleared, and the combo chain is broken. B2B status
+		// doesn't change when no lines are cleared
+		if g.Combo != 0 {
+			g.Combo, g.comboLines = 0, 0
+			g.emit(ComboChangedEvent{Combo: 0})
 		}
 	} else {
-		// Regular line clear points from the basePoints map
-		pts = basePoints[linesCleared]
-	}
-
-	// Apply level multiplier
-	pts *= g.Level
+		lock.Perfect = g.isPerfectClear()
+		g.emit(LinesClearedEvent{Count: linesCleared, TSpin: spin != NoTSpin, Mini: spin == MiniTSpin, PerfectClear: lock.Perfect})
+
+		// Tetrises and T-spins are difficult clears; one right after
+		// another is back to back, and any other clear breaks the chain
+		difficult := linesCleared == 4 || spin != NoTSpin
+		lock.B2B = difficult && g.B2B
+		g.B2B = difficult
+		if g.B2B != wasB2B {
+			g.emit(B2BEvent{Active: g.B2B})
+		}
 
-	// Back-to-Back bonus (applies to Tetrises and T-Spins)
-	// Only count as B2B if the previous clear was also a Tetris or T-Spin
-	if (linesCleared == 4 || isTspin) && g.B2B {
-		// Apply 50% bonus for back-to-back difficult clears
-		pts = int(float64(pts) * 1.5)
+		g.Combo++
+		g.comboLines += linesCleared
+		g.emit(ComboChangedEvent{Combo: g.Combo})
 	}
+	lock.Combo, lock.ComboLines = g.Combo, g.comboLines
 
-	// Update B2B flag for next clear
-	// B2B continues if this was a Tetris or T-Spin, otherwise resets
-	if linesCleared == 4 || isTspin {
-		g.B2B = true
-	} else if linesCleared > 0 {
-		// Only reset B2B if some lines were cleared but it wasn't a Tetris or T-spin
-		g.B2B = false
+	// Drops were paid as they happened; they are listed with the lock
+	var awards []Award
+	if d := g.drops; d.SoftPoints > 0 {
+		awards = append(awards, Award{fmt.Sprintf("Soft drop, %d rows", d.SoftRows), d.SoftPoints, ""})
+	}
+	if d := g.drops; d.HardPoints > 0 {
+		awards = append(awards, Award{fmt.Sprintf("Hard drop, %d rows", d.HardRows), d.HardPoints, ""})
+	}
+	g.drops = dropTally{}
+
+	// The side panel shows what the lock earned, named by its first award
+	locked := g.scorer().Lock(lock)
+	points := 0
+	for _, a := range locked {
+		g.Score += a.Points
+		points += a.Points
+	}
+	if points > 0 {
+		g.scored, g.scoredUntil = fmt.Sprintf("+%d %s", points, locked[0].Name), g.frame+noticeFrames
 	}
-	// Note: B2B status doesn't change when no lines are cleared
+	awards = append(awards, locked...)
+	if len(awards) > 0 {
+		g.emit(ScoredEvent{Awards: awards, Score: g.Score})
+	}
+	return lock
+}
 
-	// Combo system
-	if linesCleared > 0 {
-		// Increment combo counter for any line clear
-		g.Combo++
-		// Apply combo bonus starting from the 2nd consecutive clear
-		if g.Combo > 1 {
-			pts += (g.Combo - 1) * comboBonus * g.Level
+// isPerfectClear reports whether the playfield is completely empty.
+func (g *Game) isPerfectClear() bool {
+	for x := 0; x < PlayWidth; x++ {
+		for y := 0; y < TotalHeight; y++ {
+			if g.Playfield[x][y] != 0 {
+				return false
+			}
 		}
-	} else {
-		// Reset combo counter when no lines are cleared
-		g.Combo = 0
 	}
-
-	// Add points to score
-	g.Score += pts
+	return true
 }
 
 // detectTSpin checks for T-spin conditions:
 // 1. The piece must be a T piece
 // 2. The last move was a rotation (not a shift)
 // 3. At least 3 of the 4 corners around the T's center are occupied
-func (g *Game) detectTSpin(p *Piece) bool {
+// It is a full T-spin when both corners the T points at are among them, or
+// the last rotation needed the far kick, and a mini T-spin otherwise.
+func (g *Game) detectTSpin(p *Piece) TSpin {
 	// T-spin detection requires the last move to be a rotation
-	if !g.LastMoveWasRotation {
-		return false
+	if p.ID != T || !g.LastMoveWasRotation {
+		return NoTSpin
 	}
 
-	// Find the center of the T piece (pivot point for rotation)
-	// For a standard T piece, this is at (1,1) relative to the piece origin
-	cx, cy := p.Position.X+1, p.Position.Y+1
+	// Find the center of the T piece (pivot point for rotation): the one
+	// block that touches all three others
+	cx, cy := tCenter(p)
 
 	// Define the four corners around the center
 	corners := []Point{
@@ -227,7 +292,7 @@ func (g *Game) detectTSpin(p *Piece) bool {
 	}
 
 	// Count how many corners are occupied (either by a block or by being outside the playfield)
-	occupied := 0
+	occupied, front := 0, 0
 	for _, c := range corners {
 		// A corner is considered occupied if:
 		// 1. It's outside the playfield boundaries
@@ -235,29 +300,105 @@ func (g *Game) detectTSpin(p *Piece) bool {
 		if c.X < 0 || c.X >= PlayWidth || c.Y < 0 || c.Y >= TotalHeight ||
 			g.Playfield[c.X][c.Y] != 0 {
 			occupied++
+			if facesCorner(p.RotationState, c.X-cx, c.Y-cy) {
+				front++
+			}
 		}
 	}
 
 	// Standard rule: A T-spin requires at least 3 corners to be occupied
-	return occupied >= 3
+	switch {
+	case occupied < 3:
+		return NoTSpin
+	case front == 2 || g.lastKick == farKick:
+		return FullTSpin
+	}
+	return MiniTSpin
 }
 
-// adjustGravity resets the ticker to the new speed for the current level.
-func (g *Game) adjustGravity() {
-	interval := gravityForLevel(g.Level)
-	g.gravityTicker.Reset(interval)
+// farKick is the last wall kick tested, which moves a T far enough that
+// the spin counts in full even with a front corner open.
+const farKick = 4
+
+// facesCorner reports whether a T in rotation state r points at the corner
+// dx, dy from its center. Spawn points up, toward higher rows.
+func facesCorner(r, dx, dy int) bool {
+	switch r {
+	case 0:
+		return dy > 0
+	case 1:
+		return dx > 0
+	case 2:
+		return dy < 0
+	}
+	return dx < 0
 }
 
-// gravityForLevel maps level → tick interval.
-func gravityForLevel(level int) time.Duration {
-	switch {
-	case level < 10:
-		return time.Second * time.Duration(48-level*5) / 60
-	case level < 20:
-		return time.Second * time.Duration(28-(level-10)*2) / 60
-	case level < 30:
-		return time.Second * time.Duration(8-(level-20)) / 60
-	default:
-		return time.Second / 60
+// tCenter returns the playfield coordinates of a T piece's middle block.
+func tCenter(p *Piece) (int, int) {
+	for _, b := range p.Blocks {
+		touching := 0
+		for _, o := range p.Blocks {
+			if d := abs(b.X-o.X) + abs(b.Y-o.Y); d == 1 {
+				touching++
+			}
+		}
+		if touching == 3 {
+			return p.Position.X + b.X, p.Position.Y + b.Y
+		}
+	}
+	return p.Position.X + 1, p.Position.Y + 1
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// --- Gravity --------------------------------------------------------------------
+//
+// Gravity is measured in G, rows per frame. Slow levels pull a fraction of a
+// row each frame and the fractions add up; at 20G the piece drops to the
+// stack the frame it appears.
+
+// MaxGravity is 20G, as fast as gravity gets.
+const MaxGravity = 20.0
+
+// GravityForLevel is the guideline speed curve: a row takes
+// (0.8-(level-1)*0.007)^(level-1) seconds.
+func GravityForLevel(level int) float64 {
+	base := 0.8 - float64(level-1)*0.007
+	if base <= 0 {
+		return MaxGravity
+	}
+	secondsPerRow := math.Pow(base, float64(level-1))
+	return min(MaxGravity, 1/(secondsPerRow*60))
+}
+
+// tickGravity lets gravity pull the current piece for one frame.
+func (g *Game) tickGravity() {
+	if g.Current == nil {
+		return
+	}
+	g.gravityProgress += g.gravity()
+	rows := int(g.gravityProgress)
+	g.gravityProgress -= float64(rows)
+
+	lock := g.delays().Lock
+	if lock == 0 {
+		g.fall(rows)
+		return
+	}
+
+	// With a lock delay the piece may rest on the stack for a while before
+	// it locks; dropping a row starts the count over
+	g.sink(rows)
+	if g.resting() {
+		g.lockFrames++
+		if g.lockFrames >= lock {
+			g.settle()
+		}
 	}
 }
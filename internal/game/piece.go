@@ -11,13 +11,15 @@ var PieceColors = map[PieceID]tcell.Color{
 	Z: tcell.ColorRed,
 	J: tcell.ColorBlue,
 	L: tcell.NewRGBColor(255, 165, 0), // Orange
+
+	Garbage: tcell.ColorGray,
 }
 
-// SRS offsets and block matrices.
-// Each [4][4]bool is a rotation state; true = block present.
+// SRS block matrices, top row first. Each [4][4]bool is a rotation state,
+// clockwise from spawn; true = block present. Kicks are in srs.go.
 var PieceShapes = map[PieceID][4][4][4]bool{
 	I: {
-		// state 0
+		// state 0 (spawn)
 		{
 			{false, false, false, false},
 			{true, true, true, true},
@@ -49,178 +51,178 @@ var PieceShapes = map[PieceID][4][4][4]bool{
 	O: {
 		// All four rotation states are the same for O
 		{
-			{false, false, false, false},
 			{false, true, true, false},
 			{false, true, true, false},
 			{false, false, false, false},
+			{false, false, false, false},
 		},
 		{
-			{false, false, false, false},
 			{false, true, true, false},
 			{false, true, true, false},
 			{false, false, false, false},
+			{false, false, false, false},
 		},
 		{
-			{false, false, false, false},
 			{false, true, true, false},
 			{false, true, true, false},
 			{false, false, false, false},
+			{false, false, false, false},
 		},
 		{
-			{false, false, false, false},
 			{false, true, true, false},
 			{false, true, true, false},
 			{false, false, false, false},
+			{false, false, false, false},
 		},
 	},
 	T: {
-		// state 0
+		// state 0 (spawn)
 		{
+			{false, true, false, false},
+			{true, true, true, false},
 			{false, false, false, false},
-			{false, true, true, true},
-			{false, false, true, false},
 			{false, false, false, false},
 		},
 		// R
 		{
-			{false, false, false, false},
-			{false, false, true, false},
+			{false, true, false, false},
 			{false, true, true, false},
-			{false, false, true, false},
+			{false, true, false, false},
+			{false, false, false, false},
 		},
 		// 2
 		{
 			{false, false, false, false},
-			{false, false, true, false},
-			{false, true, true, true},
+			{true, true, true, false},
+			{false, true, false, false},
 			{false, false, false, false},
 		},
 		// L
 		{
-			{false, false, false, false},
 			{false, true, false, false},
-			{false, true, true, false},
+			{true, true, false, false},
 			{false, true, false, false},
+			{false, false, false, false},
 		},
 	},
 	J: {
-		// state 0
+		// state 0 (spawn)
 		{
+			{true, false, false, false},
+			{true, true, true, false},
 			{false, false, false, false},
-			{false, true, true, true},
-			{false, false, false, true},
 			{false, false, false, false},
 		},
 		// R
 		{
-			{false, false, false, false},
-			{false, false, true, false},
-			{false, false, true, false},
 			{false, true, true, false},
+			{false, true, false, false},
+			{false, true, false, false},
+			{false, false, false, false},
 		},
 		// 2
 		{
 			{false, false, false, false},
-			{false, true, false, false},
-			{false, true, true, true},
+			{true, true, true, false},
+			{false, false, true, false},
 			{false, false, false, false},
 		},
 		// L
 		{
-			{false, false, false, false},
-			{false, true, true, false},
 			{false, true, false, false},
 			{false, true, false, false},
+			{true, true, false, false},
+			{false, false, false, false},
 		},
 	},
 	L: {
-		// state 0
+		// state 0 (spawn)
 		{
+			{false, false, true, false},
+			{true, true, true, false},
 			{false, false, false, false},
-			{false, true, true, true},
-			{false, true, false, false},
 			{false, false, false, false},
 		},
 		// R
 		{
-			{false, false, false, false},
+			{false, true, false, false},
+			{false, true, false, false},
 			{false, true, true, false},
-			{false, false, true, false},
-			{false, false, true, false},
+			{false, false, false, false},
 		},
 		// 2
 		{
 			{false, false, false, false},
-			{false, false, false, true},
-			{false, true, true, true},
+			{true, true, true, false},
+			{true, false, false, false},
 			{false, false, false, false},
 		},
 		// L
 		{
-			{false, false, false, false},
+			{true, true, false, false},
 			{false, true, false, false},
 			{false, true, false, false},
-			{false, true, true, false},
+			{false, false, false, false},
 		},
 	},
 	S: {
-		// state 0
+		// state 0 (spawn)
 		{
-			{false, false, false, false},
-			{false, false, true, true},
 			{false, true, true, false},
+			{true, true, false, false},
+			{false, false, false, false},
 			{false, false, false, false},
 		},
 		// R
 		{
-			{false, false, false, false},
 			{false, true, false, false},
 			{false, true, true, false},
 			{false, false, true, false},
+			{false, false, false, false},
 		},
 		// 2
 		{
 			{false, false, false, false},
-			{false, false, true, true},
 			{false, true, true, false},
+			{true, true, false, false},
 			{false, false, false, false},
 		},
 		// L
 		{
-			{false, false, false, false},
+			{true, false, false, false},
+			{true, true, false, false},
 			{false, true, false, false},
-			{false, true, true, false},
-			{false, false, true, false},
+			{false, false, false, false},
 		},
 	},
 	Z: {
-		// state 0
+		// state 0 (spawn)
 		{
-			{false, false, false, false},
+			{true, true, false, false},
 			{false, true, true, false},
-			{false, false, true, true},
+			{false, false, false, false},
 			{false, false, false, false},
 		},
 		// R
 		{
-			{false, false, false, false},
 			{false, false, true, false},
 			{false, true, true, false},
 			{false, true, false, false},
+			{false, false, false, false},
 		},
 		// 2
 		{
 			{false, false, false, false},
+			{true, true, false, false},
 			{false, true, true, false},
-			{false, false, true, true},
 			{false, false, false, false},
 		},
 		// L
 		{
-			{false, false, false, false},
-			{false, false, true, false},
-			{false, true, true, false},
 			{false, true, false, false},
+			{true, true, false, false},
+			{true, false, false, false},
+			{false, false, false, false},
 		},
 	},
 }
@@ -0,0 +1,362 @@
+package game
+
+import "sort"
+
+// --- Board Helpers ------------------------------------------------------------
+//
+// These functions work on a bare playfield instead of a Game so bots can try
+// out placements on copies of the board without touching the real game.
+
+// Field is the raw playfield grid, indexed [x][y] with y=0 at the bottom.
+type Field = [PlayWidth][TotalHeight]int
+
+// Placement is a piece in a specific rotation and position.
+type Placement struct {
+	Piece    PieceID
+	Rotation int
+	Position Point
+}
+
+// Cells returns the absolute playfield coordinates covered by the placement.
+func (p Placement) Cells() [4]Point {
+	var cells [4]Point
+	for i, b := range ShapeBlocks(p.Piece, p.Rotation) {
+		cells[i] = Point{X: p.Position.X + b.X, Y: p.Position.Y + b.Y}
+	}
+	return cells
+}
+
+// ShapeBlocks builds the four block offsets of a piece in a rotation state.
+func ShapeBlocks(id PieceID, rotation int) [4]Point {
+	if id >= I && id <= Z {
+		return shapeCache[id][rotation]
+	}
+	return buildBlocks(id, rotation)
+}
+
+// shapeCache holds the block offsets of every piece and rotation, since the
+// bots ask for them many thousands of times per move.
+var shapeCache = func() (cache [Z + 1][4][4]Point) {
+	for id := I; id <= Z; id++ {
+		for r := range cache[id] {
+			cache[id][r] = buildBlocks(id, r)
+		}
+	}
+	return cache
+}()
+
+// buildBlocks reads the block offsets out of the shape matrix. The matrices
+// are written top row first while the playfield counts rows from the bottom,
+// so rows are flipped.
+func buildBlocks(id PieceID, rotation int) [4]Point {
+	matrix := PieceShapes[id][rotation]
+
+	var blocks [4]Point
+	idx := 0
+	for y := 0; y < 4; y++ {
+		for x := 0; x < 4; x++ {
+			if matrix[y][x] {
+				blocks[idx] = Point{X: x, Y: 3 - y}
+				idx++
+			}
+		}
+	}
+	return blocks
+}
+
+// SpawnPoint is where new pieces appear: centered horizontally, in the two
+// rows of the hidden buffer right above the visible playfield.
+func SpawnPoint() Point {
+	spawnX := PlayWidth/2 - 2
+	if spawnX < 0 {
+		spawnX = 0
+	}
+	return Point{X: spawnX, Y: VisibleHeight - 2}
+}
+
+// Fits reports whether the placement is inside the playfield and free of
+// locked blocks.
+func Fits(f *Field, p Placement) bool {
+	return blocksFit(f, ShapeBlocks(p.Piece, p.Rotation), p.Position)
+}
+
+// blocksFit is the collision test shared by the game and the board helpers.
+func blocksFit(f *Field, blocks [4]Point, pos Point) bool {
+	for _, b := range blocks {
+		x, y := pos.X+b.X, pos.Y+b.Y
+
+		// Walls, floor and the top of the hidden buffer
+		if x < 0 || x >= PlayWidth || y < 0 || y >= TotalHeight {
+			return false
+		}
+
+		// Existing blocks
+		if f[x][y] != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// PlacementForCells finds the rotation and position of piece id that covers
+// exactly the given cells.
+func PlacementForCells(id PieceID, cells [4]Point) (Placement, bool) {
+	want := cellsKey(cells)
+	for rot := 0; rot < 4; rot++ {
+		for _, b := range ShapeBlocks(id, rot) {
+			p := Placement{Piece: id, Rotation: rot, Position: Point{X: cells[0].X - b.X, Y: cells[0].Y - b.Y}}
+			if cellsKey(p.Cells()) == want {
+				return p, true
+			}
+		}
+	}
+	return Placement{}, false
+}
+
+// Landing drops p straight down until it rests on something.
+func Landing(f *Field, p Placement) Placement {
+	for {
+		p.Position.Y--
+		if !Fits(f, p) {
+			p.Position.Y++
+			return p
+		}
+	}
+}
+
+// PlacePiece paints p into f and removes any rows it completes. It returns
+// the number of cleared rows.
+func PlacePiece(f *Field, p Placement) int {
+	rows := make(map[int]struct{})
+	for _, c := range p.Cells() {
+		if c.X >= 0 && c.X < PlayWidth && c.Y >= 0 && c.Y < TotalHeight {
+			f[c.X][c.Y] = int(p.Piece)
+			rows[c.Y] = struct{}{}
+		}
+	}
+
+	full := fullRows(f, rows)
+	removeRows(f, full)
+	return len(full)
+}
+
+// fullRows returns the completely filled rows among candidates, highest first.
+// Only rows in the visible area count; a full row in the hidden buffer stays.
+func fullRows(f *Field, candidates map[int]struct{}) []int {
+	var full []int
+	for row := range candidates {
+		if row < 0 || row >= VisibleHeight {
+			continue
+		}
+
+		filled := true
+		for x := 0; x < PlayWidth; x++ {
+			if f[x][row] == 0 {
+				filled = false
+				break
+			}
+		}
+		if filled {
+			full = append(full, row)
+		}
+	}
+
+	// Highest first, so removing one row never shifts another pending one
+	sort.Sort(sort.Reverse(sort.IntSlice(full)))
+	return full
+}
+
+// removeRows deletes the given rows (highest first) and lets everything above
+// them fall down, pulling rows out of the hidden buffer.
+func removeRows(f *Field, rows []int) {
+	for _, row := range rows {
+		for y := row; y < TotalHeight-1; y++ {
+			for x := 0; x < PlayWidth; x++ {
+				f[x][y] = f[x][y+1]
+			}
+		}
+		for x := 0; x < PlayWidth; x++ {
+			f[x][TotalHeight-1] = 0
+		}
+	}
+}
+
+// raiseRows pushes everything up n rows, through the hidden buffer, leaving
+// n empty rows at the bottom. It reports whether blocks were pushed out of
+// the top.
+func raiseRows(f *Field, n int) bool {
+	n = min(n, TotalHeight)
+	over := false
+	for x := 0; x < PlayWidth; x++ {
+		for y := TotalHeight - n; y < TotalHeight; y++ {
+			over = over || f[x][y] != 0
+		}
+		copy(f[x][n:], f[x][:TotalHeight-n])
+		clear(f[x][:n])
+	}
+	return over
+}
+
+// InsertGarbage pushes the board up a row for each hole and fills the rows
+// underneath with garbage, each open at its hole's column; holes[0] is the
+// top new row. It reports whether blocks were pushed out of the top.
+func InsertGarbage(f *Field, holes []int) bool {
+	over := raiseRows(f, len(holes))
+	for i, hole := range holes {
+		y := len(holes) - 1 - i
+		if y >= TotalHeight {
+			continue
+		}
+		for x := 0; x < PlayWidth; x++ {
+			if x != hole {
+				f[x][y] = int(Garbage)
+			}
+		}
+	}
+	return over
+}
+
+// --- Reachability -------------------------------------------------------------
+
+// moveStep is one BFS edge: an action and the placement on the other end.
+type moveStep struct {
+	at     Placement
+	action Action
+}
+
+// neighbors lists the placements reachable from p with a single action,
+// using exactly the same rules as the player's moves.
+func neighbors(f *Field, p Placement) (out [5]moveStep, n int) {
+	try := func(a Action, next Placement) {
+		if Fits(f, next) {
+			out[n] = moveStep{at: next, action: a}
+			n++
+		}
+	}
+
+	left := p
+	left.Position.X--
+	try(ActionMoveLeft, left)
+
+	right := p
+	right.Position.X++
+	try(ActionMoveRight, right)
+
+	if cw, _, ok := rotatePlacement(f, p, 1); ok {
+		try(ActionRotate, cw)
+	}
+	if ccw, _, ok := rotatePlacement(f, p, -1); ok {
+		try(ActionRotateCCW, ccw)
+	}
+
+	down := p
+	down.Position.Y--
+	try(ActionSoftDrop, down)
+
+	return out, n
+}
+
+// Search space for the BFS: a 4x4 shape box can hang up to 3 cells past
+// the left wall and the floor.
+const (
+	searchW = PlayWidth + 3
+	searchH = TotalHeight + 3
+)
+
+// searchIndex flattens a placement of one piece into a slot of the BFS tables.
+func searchIndex(p Placement) int {
+	return (p.Position.X + 3) + searchW*((p.Position.Y+3)+searchH*p.Rotation)
+}
+
+// searchFrom walks every position the piece can reach from start and calls
+// visit with the landing spot of each one. The path callback builds the
+// actions that lead there, ending in a hard drop. The walk stops early when
+// visit returns false.
+func searchFrom(f *Field, start Placement, visit func(land Placement, path func() []Action) bool) {
+	if !Fits(f, start) {
+		return
+	}
+
+	// Flat tables instead of maps: this runs for every piece a bot places
+	parent := make([]moveStep, searchW*searchH*4)
+	seen := make([]bool, len(parent))
+	seen[searchIndex(start)] = true
+	queue := []Placement{start}
+
+	pathTo := func(p Placement) []Action {
+		var path []Action
+		for p != start {
+			step := parent[searchIndex(p)]
+			path = append(path, step.action)
+			p = step.at
+		}
+		for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+			path[i], path[j] = path[j], path[i]
+		}
+		return path
+	}
+
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+
+		// A hard drop from here ends the piece at its landing spot
+		path := func() []Action { return append(pathTo(cur), ActionHardDrop) }
+		if !visit(Landing(f, cur), path) {
+			return
+		}
+
+		steps, count := neighbors(f, cur)
+		for _, n := range steps[:count] {
+			idx := searchIndex(n.at)
+			if seen[idx] {
+				continue
+			}
+			seen[idx] = true
+			parent[idx] = moveStep{at: cur, action: n.action}
+			queue = append(queue, n.at)
+		}
+	}
+}
+
+// ReachablePlacements lists every distinct spot where the piece in start can
+// lock, using only moves a player could make.
+func ReachablePlacements(f *Field, start Placement) []Placement {
+	var out []Placement
+	seen := map[[4]int]bool{}
+	searchFrom(f, start, func(land Placement, _ func() []Action) bool {
+		key := cellsKey(land.Cells())
+		if !seen[key] {
+			seen[key] = true
+			out = append(out, land)
+		}
+		return true
+	})
+	return out
+}
+
+// PathTo finds the shortest action sequence that locks the piece from start
+// onto the same cells as target. The last action is always a hard drop.
+func PathTo(f *Field, start, target Placement) ([]Action, bool) {
+	want := cellsKey(target.Cells())
+	var found []Action
+	searchFrom(f, start, func(land Placement, path func() []Action) bool {
+		if cellsKey(land.Cells()) == want {
+			found = path()
+			return false
+		}
+		return true
+	})
+	return found, found != nil
+}
+
+// cellsKey identifies a set of cells regardless of block order, so rotation
+// states that cover the same cells (like the O piece) count as one spot.
+func cellsKey(cells [4]Point) [4]int {
+	var key [4]int
+	for i, c := range cells {
+		key[i] = c.Y*PlayWidth + c.X
+	}
+	sort.Ints(key[:])
+	return key
+}
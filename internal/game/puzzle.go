@@ -0,0 +1,384 @@
+package game
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// --- Puzzles ------------------------------------------------------------------
+//
+// A puzzle is a training drill: a prepared board, a fixed piece queue and a
+// goal such as "T-spin double" or "perfect clear in 10 pieces". They are read
+// from small text files:
+//
+//	# Comments start with a hash
+//	name: TSD slot
+//	description: Spin the T into the slot
+//	goal: tsd
+//	queue: T
+//	hold: I
+//	board:
+//	XXXX......
+//	XXX...XXXX
+//	XXXX.XXXXX
+//
+// The board is drawn top row first and ends at the floor. A dot is an empty
+// cell, a piece letter paints that piece and X (or G) is garbage. Instead of
+// a board, "fumen: v115@..." takes the board and, unless queue and hold are
+// given, the pieces from the first page of a fumen.
+//
+// Goals are tss, tsd and tst (clear 1-3 rows with a T-spin), "pc N" (perfect
+// clear within N pieces), "survive N" (place N pieces without topping out)
+// and free (just play from the setup). Survival and free play continue with
+// random bags once the fixed part of the queue runs out.
+
+// GoalKind is the kind of task a puzzle sets.
+type GoalKind int
+
+const (
+	GoalTSpin        GoalKind = iota + 1 // Clear Lines rows with one T-spin
+	GoalPerfectClear                     // Empty the board within Pieces pieces
+	GoalSurvive                          // Lock Pieces pieces without topping out
+	GoalFree                             // No goal, practice from the setup
+)
+
+// Goal is what has to happen for a puzzle to count as solved.
+type Goal struct {
+	Kind   GoalKind
+	Lines  int // T-spin goals
+	Pieces int // Perfect clear and survival goals
+}
+
+var tspinNames = map[int]string{1: "single", 2: "double", 3: "triple"}
+
+// String describes the goal for the side panel and the browser.
+func (g Goal) String() string {
+	switch g.Kind {
+	case GoalTSpin:
+		return "T-spin " + tspinNames[g.Lines]
+	case GoalPerfectClear:
+		return fmt.Sprintf("Perfect clear in %d", g.Pieces)
+	case GoalSurvive:
+		return fmt.Sprintf("Survive %d pieces", g.Pieces)
+	case GoalFree:
+		return "Free play"
+	}
+	return "?"
+}
+
+// parseGoal reads the value of a goal line.
+func parseGoal(s string) (Goal, error) {
+	fields := strings.Fields(strings.ToLower(s))
+	if len(fields) == 0 {
+		return Goal{}, fmt.Errorf("empty goal")
+	}
+
+	switch fields[0] {
+	case "tss":
+		return Goal{Kind: GoalTSpin, Lines: 1}, nil
+	case "tsd":
+		return Goal{Kind: GoalTSpin, Lines: 2}, nil
+	case "tst":
+		return Goal{Kind: GoalTSpin, Lines: 3}, nil
+	case "free":
+		return Goal{Kind: GoalFree}, nil
+	case "pc", "survive":
+		if len(fields) != 2 {
+			return Goal{}, fmt.Errorf("goal %q needs a piece count", fields[0])
+		}
+		n, err := strconv.Atoi(fields[1])
+		if err != nil || n <= 0 {
+			return Goal{}, fmt.Errorf("bad piece count %q", fields[1])
+		}
+		if fields[0] == "pc" {
+			return Goal{Kind: GoalPerfectClear, Pieces: n}, nil
+		}
+		return Goal{Kind: GoalSurvive, Pieces: n}, nil
+	}
+	return Goal{}, fmt.Errorf("unknown goal %q", s)
+}
+
+// Puzzle is a training setup loaded from a puzzle file.
+type Puzzle struct {
+	Name        string
+	Description string
+	Playfield   Field
+	Queue       []PieceID
+	Hold        PieceID // Starts in the hold slot, 0 for none
+	Goal        Goal
+}
+
+// ParsePuzzle reads a puzzle file.
+func ParsePuzzle(r io.Reader) (Puzzle, error) {
+	var (
+		p     Puzzle
+		rows  []string
+		board bool
+		setup *Puzzle // From a fumen
+	)
+
+	sc := bufio.NewScanner(r)
+	for n := 1; sc.Scan(); n++ {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if board {
+			rows = append(rows, line)
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			return p, fmt.Errorf("line %d: expected key: value", n)
+		}
+		value = strings.TrimSpace(value)
+
+		var err error
+		switch strings.ToLower(strings.TrimSpace(key)) {
+		case "name":
+			p.Name = value
+		case "description":
+			p.Description = value
+		case "goal":
+			p.Goal, err = parseGoal(value)
+		case "queue":
+			p.Queue, err = parsePieces(value)
+		case "hold":
+			var hold []PieceID
+			if hold, err = parsePieces(value); err == nil && len(hold) > 1 {
+				err = fmt.Errorf("hold takes a single piece")
+			} else if len(hold) == 1 {
+				p.Hold = hold[0]
+			}
+		case "board":
+			board = true
+		case "fumen":
+			var f Puzzle
+			if f, err = PuzzleFromFumen(value, 0); err == nil {
+				setup = &f
+			}
+		default:
+			err = fmt.Errorf("unknown key %q", key)
+		}
+		if err != nil {
+			return p, fmt.Errorf("line %d: %w", n, err)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return p, err
+	}
+
+	if p.Goal.Kind == 0 {
+		return p, fmt.Errorf("missing goal")
+	}
+	if setup != nil {
+		if len(rows) > 0 {
+			return p, fmt.Errorf("give either a board or a fumen, not both")
+		}
+		p.Playfield = setup.Playfield
+		if len(p.Queue) == 0 && p.Hold == 0 {
+			p.Queue, p.Hold = setup.Queue, setup.Hold
+		}
+	}
+	if len(p.Queue) == 0 && p.Goal.Kind != GoalSurvive && p.Goal.Kind != GoalFree {
+		return p, fmt.Errorf("missing queue")
+	}
+	if len(rows) > VisibleHeight {
+		return p, fmt.Errorf("board has %d rows, at most %d fit", len(rows), VisibleHeight)
+	}
+
+	// The last row is the floor
+	for i, row := range rows {
+		y := len(rows) - 1 - i
+		if len([]rune(row)) != PlayWidth {
+			return p, fmt.Errorf("board row %d: want %d cells, got %d", i+1, PlayWidth, len([]rune(row)))
+		}
+		for x, c := range []rune(row) {
+			switch c {
+			case '.', '_':
+			case 'X', 'G', '#':
+				p.Playfield[x][y] = int(Garbage)
+			default:
+				id, ok := PieceFromLetter(string(c))
+				if !ok {
+					return p, fmt.Errorf("board row %d: unknown cell %q", i+1, c)
+				}
+				p.Playfield[x][y] = int(id)
+			}
+		}
+	}
+	return p, nil
+}
+
+// parsePieces reads a list of piece letters like "TIO" or "T I O".
+func parsePieces(s string) ([]PieceID, error) {
+	var ids []PieceID
+	for _, c := range strings.ToUpper(s) {
+		if c == ' ' || c == ',' {
+			continue
+		}
+		id, ok := PieceFromLetter(string(c))
+		if !ok {
+			return nil, fmt.Errorf("unknown piece %q", c)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// LoadPuzzles reads every .txt puzzle in fsys, sorted by file name. Puzzles
+// without a name are named after their file.
+func LoadPuzzles(fsys fs.FS) ([]Puzzle, error) {
+	names, err := fs.Glob(fsys, "*.txt")
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(names)
+
+	var puzzles []Puzzle
+	for _, name := range names {
+		f, err := fsys.Open(name)
+		if err != nil {
+			return nil, err
+		}
+		p, err := ParsePuzzle(f)
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		if p.Name == "" {
+			p.Name = strings.TrimSuffix(path.Base(name), ".txt")
+		}
+		puzzles = append(puzzles, p)
+	}
+	return puzzles, nil
+}
+
+// --- Playing Puzzles ----------------------------------------------------------
+
+// Reasons a puzzle ends, reported by GameOverEvent.
+const (
+	ReasonPuzzleSolved = "puzzle solved"
+	ReasonOutOfPieces  = "out of pieces"
+)
+
+// puzzleRun is a puzzle in progress.
+type puzzleRun struct {
+	Puzzle
+	clear  *LinesClearedEvent // Rows cleared by the last lock, if any
+	solved bool
+	reason string // Why it ended
+}
+
+// SetPuzzles fills the puzzle browser. Call it before Run.
+func (g *Game) SetPuzzles(puzzles []Puzzle) {
+	g.puzzles = puzzles
+}
+
+// Puzzles returns the puzzles offered in the browser.
+func (g *Game) Puzzles() []Puzzle {
+	return g.puzzles
+}
+
+// StartPuzzle starts a game on the puzzle's board and queue.
+func (g *Game) StartPuzzle(p Puzzle) {
+	g.puzzle = &puzzleRun{Puzzle: p}
+	g.StartGame()
+}
+
+// RetryPuzzle starts the current puzzle over. It does nothing outside puzzles.
+func (g *Game) RetryPuzzle() {
+	if g.puzzle == nil {
+		return
+	}
+	g.StartPuzzle(g.puzzle.Puzzle)
+}
+
+// PuzzleResult reports whether a puzzle is being played and, once it is
+// over, whether it was solved.
+func (g *Game) PuzzleResult() (active, solved bool) {
+	if g.puzzle == nil {
+		return false, false
+	}
+	return true, g.puzzle.solved
+}
+
+// fixedQueue reports whether the pieces come only from the puzzle's queue.
+func (g *Game) fixedQueue() bool {
+	return g.puzzle != nil && g.puzzle.Goal.Kind != GoalSurvive && g.puzzle.Goal.Kind != GoalFree
+}
+
+// piecesLeft is how many more pieces the puzzle allows, or -1 without a
+// limit.
+func (g *Game) piecesLeft() int {
+	if g.puzzle == nil {
+		return -1
+	}
+	switch g.puzzle.Goal.Kind {
+	case GoalPerfectClear, GoalSurvive:
+		return max(0, g.puzzle.Goal.Pieces-g.Stats.Pieces)
+	case GoalFree:
+		return -1
+	}
+	left := len(g.NextQueue)
+	if g.Current != nil {
+		left++
+	}
+	if g.Hold != 0 {
+		left++
+	}
+	return left
+}
+
+// trackPuzzle remembers what the last lock cleared, for checkPuzzle.
+func (g *Game) trackPuzzle(e Event) {
+	if g.puzzle == nil {
+		return
+	}
+	switch ev := e.(type) {
+	case LockedEvent:
+		g.puzzle.clear = nil
+	case LinesClearedEvent:
+		g.puzzle.clear = &ev
+	case GameOverEvent:
+		g.puzzle.reason = ev.Reason
+	}
+}
+
+// checkPuzzle runs after every lock and ends the puzzle once it is solved or
+// can no longer be solved.
+func (g *Game) checkPuzzle() {
+	run := g.puzzle
+	goal := run.Goal
+
+	switch goal.Kind {
+	case GoalTSpin:
+		// Other clears on the way are fine; running out of pieces is not,
+		// which spawnNext takes care of
+		if c := run.clear; c != nil && c.TSpin && c.Count == goal.Lines {
+			run.solved = true
+		}
+	case GoalPerfectClear:
+		if c := run.clear; c != nil && c.PerfectClear {
+			run.solved = true
+		} else if g.Stats.Pieces >= goal.Pieces {
+			g.endGame(ReasonOutOfPieces)
+			return
+		}
+	case GoalSurvive:
+		run.solved = g.Stats.Pieces >= goal.Pieces
+	case GoalFree:
+		// Plays on until the stack tops out
+	}
+
+	if run.solved {
+		g.endGame(ReasonPuzzleSolved)
+	}
+}
@@ -10,64 +10,79 @@ import (
 // PlayfieldPrimitive embeds Box for borders, sizing, focus.
 type PlayfieldPrimitive struct {
 	*tview.Box
-	Game         *Game
-	flashingRows []int // Rows that are flashing during animation
-	flashOn      bool  // Whether the flash is currently on or off
+	Source Source
+	cells  cellSize // Size of a block, picked by the board's layout
 }
 
 // StatusPrimitive shows game status information (score, level, etc.)
 type StatusPrimitive struct {
 	*tview.Box
-	Game *Game
+	Source Source
 }
 
 // NextPiecePrimitive shows the next piece preview
 type NextPiecePrimitive struct {
 	*tview.Box
-	Game *Game
+	Source Source
+	cells  cellSize
+}
+
+// HoldPrimitive shows the piece in the hold slot
+type HoldPrimitive struct {
+	*tview.Box
+	Source Source
+	cells  cellSize
 }
 
 // NewPlayfieldPrimitive constructs and positions the grid.
-func NewPlayfieldPrimitive(g *Game, x, y, width, height int) *PlayfieldPrimitive {
+func NewPlayfieldPrimitive(src Source, x, y, width, height int) *PlayfieldPrimitive {
 	box := tview.NewBox().
 		SetBorder(true).
 		SetTitle(" TETRIS ")
 	box.SetRect(x, y, width, height)
-	return &PlayfieldPrimitive{Box: box, Game: g}
+	return &PlayfieldPrimitive{Box: box, Source: src, cells: standardCells}
 }
 
 // NewStatusPrimitive creates a new status display box
-func NewStatusPrimitive(g *Game, x, y, width, height int) *StatusPrimitive {
+func NewStatusPrimitive(src Source, x, y, width, height int) *StatusPrimitive {
 	box := tview.NewBox().
 		SetBorder(true).
 		SetTitle(" STATUS ").
 		SetBorderColor(tcell.ColorBlue)
 	box.SetRect(x, y, width, height)
-	return &StatusPrimitive{Box: box, Game: g}
+	return &StatusPrimitive{Box: box, Source: src}
 }
 
 // NewNextPiecePrimitive creates a new next piece preview box
-func NewNextPiecePrimitive(g *Game, x, y, width, height int) *NextPiecePrimitive {
+func NewNextPiecePrimitive(src Source, x, y, width, height int) *NextPiecePrimitive {
 	box := tview.NewBox().
 		SetBorder(true).
 		SetTitle(" NEXT ").
 		SetBorderColor(tcell.ColorRed)
 	box.SetRect(x, y, width, height)
-	return &NextPiecePrimitive{Box: box, Game: g}
+	return &NextPiecePrimitive{Box: box, Source: src, cells: standardCells}
 }
 
-// Draw is called each frame by QueueUpdateDraw.
-func (p *PlayfieldPrimitive) Draw(screen tcell.Screen) {
-	// Update title with current piece info for debugging
-	if p.Game.Current != nil {
-		p.Box.SetTitle(" TETRIS ")
-	} else {
-		p.Box.SetTitle(" TETRIS ")
-	}
+// NewHoldPrimitive creates the hold slot box
+func NewHoldPrimitive(src Source, x, y, width, height int) *HoldPrimitive {
+	box := tview.NewBox().
+		SetBorder(true).
+		SetTitle(" HOLD ").
+		SetBorderColor(tcell.ColorGray)
+	box.SetRect(x, y, width, height)
+	return &HoldPrimitive{Box: box, Source: src, cells: standardCells}
+}
 
+// Draw is called each frame by QueueUpdateDraw. Like every primitive it
+// draws the last published snapshot, never the live game.
+func (p *PlayfieldPrimitive) Draw(screen tcell.Screen) {
 	// Draw border & background.
 	p.Box.DrawForSubclass(screen, p)
 	x0, y0, width, height := p.GetInnerRect()
+	view := p.Source.Snapshot()
+	if view == nil {
+		return
+	}
 
 	// Clear the inner rectangle to prevent artifacts
 	for x := 0; x < width; x++ {
@@ -76,59 +91,27 @@ func (p *PlayfieldPrimitive) Draw(screen tcell.Screen) {
 		}
 	}
 
-	// Draw proper content based on game state
-	switch p.Game.State {
-	case MainMenu:
-		p.drawMainMenu(screen, x0, y0, width, height)
+	// Draw proper content based on game state. The menus have pages of
+	// their own, so the board only shows them to spectators, as a game on
+	// its way.
+	switch view.State {
+	case MainMenu, PuzzleSelect:
+		if view.Player != "" {
+			drawCenteredText(screen, x0, y0+height/2, width, "Waiting for "+view.Player, tcell.StyleDefault.Foreground(tcell.ColorYellow))
+		}
 	case Paused:
-		p.drawPausedOverlay(screen, x0, y0, width, height)
+		p.drawPausedOverlay(screen, view, x0, y0, width, height)
 	case GameOver:
-		p.drawGameOverOverlay(screen, x0, y0, width, height)
-	case Animating:
-		p.drawPlayfield(screen, x0, y0, width, height)
-		p.drawAnimatingLines(screen, x0, y0, width, height)
+		p.drawGameOverOverlay(screen, view, x0, y0, width, height)
 	case Playing:
-		p.drawPlayfield(screen, x0, y0, width, height)
-	}
-}
-
-// drawMainMenu draws the welcome screen
-func (p *PlayfieldPrimitive) drawMainMenu(screen tcell.Screen, x0, y0, width, height int) {
-	// Clear the entire area first
-	for x := 0; x < width; x++ {
-		for y := 0; y < height; y++ {
-			screen.SetContent(x0+x, y0+y, ' ', nil, tcell.StyleDefault)
-		}
-	}
-
-	// Draw a simple centered menu
-	centerY := height / 2
-
-	if centerY-3 >= 0 && centerY-3 < height {
-		drawCenteredText(screen, x0, y0+centerY-3, width, "TETRIS", tcell.StyleDefault.Foreground(tcell.ColorGreen).Bold(true))
-	}
-
-	if centerY-1 >= 0 && centerY-1 < height {
-		drawCenteredText(screen, x0, y0+centerY-1, width, "Press ENTER to start", tcell.StyleDefault.Foreground(tcell.ColorYellow))
-	}
-
-	if centerY+1 >= 0 && centerY+1 < height {
-		drawCenteredText(screen, x0, y0+centerY+1, width, "Arrow Keys: Move/Rotate", tcell.StyleDefault)
-	}
-
-	if centerY+2 >= 0 && centerY+2 < height {
-		drawCenteredText(screen, x0, y0+centerY+2, width, "Space: Drop • ESC: Pause", tcell.StyleDefault)
-	}
-
-	if centerY+3 >= 0 && centerY+3 < height {
-		drawCenteredText(screen, x0, y0+centerY+3, width, "Q: Quit", tcell.StyleDefault)
+		p.drawPlayfield(screen, view, x0, y0, width, height)
 	}
 }
 
 // drawPausedOverlay draws the pause screen
-func (p *PlayfieldPrimitive) drawPausedOverlay(screen tcell.Screen, x0, y0, width, height int) {
+func (p *PlayfieldPrimitive) drawPausedOverlay(screen tcell.Screen, view *Snapshot, x0, y0, width, height int) {
 	// Draw the playfield in the background
-	p.drawPlayfield(screen, x0, y0, width, height)
+	p.drawPlayfield(screen, view, x0, y0, width, height)
 
 	// Draw semi-transparent overlay
 	style := tcell.StyleDefault.Background(tcell.ColorBlack.TrueColor() & 0x80FFFFFF)
@@ -144,13 +127,13 @@ func (p *PlayfieldPrimitive) drawPausedOverlay(screen tcell.Screen, x0, y0, widt
 
 	// Draw pause message
 	drawCenteredText(screen, x0, y0+height/2-1, width, "PAUSED", tcell.StyleDefault.Foreground(tcell.ColorYellow))
-	drawCenteredText(screen, x0, y0+height/2+1, width, "Press P or ENTER to resume", tcell.StyleDefault)
+	drawCenteredText(screen, x0, y0+height/2+1, width, fitText(width, "Press P or ENTER to resume", "P: resume"), tcell.StyleDefault)
 }
 
 // drawGameOverOverlay draws the game over screen
-func (p *PlayfieldPrimitive) drawGameOverOverlay(screen tcell.Screen, x0, y0, width, height int) {
+func (p *PlayfieldPrimitive) drawGameOverOverlay(screen tcell.Screen, view *Snapshot, x0, y0, width, height int) {
 	// Draw the final playfield state in the background
-	p.drawPlayfield(screen, x0, y0, width, height)
+	p.drawPlayfield(screen, view, x0, y0, width, height)
 
 	// Draw semi-transparent overlay
 	style := tcell.StyleDefault.Background(tcell.ColorBlack.TrueColor() & 0x80FFFFFF)
@@ -164,145 +147,188 @@ func (p *PlayfieldPrimitive) drawGameOverOverlay(screen tcell.Screen, x0, y0, wi
 		}
 	}
 
+	// Puzzles say how they went and offer a retry
+	if pz := view.Puzzle; pz != nil {
+		if pz.Solved {
+			drawCenteredText(screen, x0, y0+height/2-2, width, "SOLVED!", tcell.StyleDefault.Foreground(tcell.ColorGreen))
+		} else {
+			drawCenteredText(screen, x0, y0+height/2-2, width, "FAILED", tcell.StyleDefault.Foreground(tcell.ColorRed))
+			drawCenteredText(screen, x0, y0+height/2-1, width, pz.Reason, tcell.StyleDefault)
+		}
+		drawCenteredText(screen, x0, y0+height/2+1, width, "R: retry", tcell.StyleDefault)
+		drawCenteredText(screen, x0, y0+height/2+2, width, fitText(width, "ENTER: puzzles", "⏎ puzzles"), tcell.StyleDefault)
+		return
+	}
+
+	// Versus rounds say who took them and how the match stands
+	if b := view.Battle; b != nil {
+		if b.Won {
+			drawCenteredText(screen, x0, y0+height/2-2, width, "ROUND WON", tcell.StyleDefault.Foreground(tcell.ColorGreen))
+		} else {
+			drawCenteredText(screen, x0, y0+height/2-2, width, "ROUND LOST", tcell.StyleDefault.Foreground(tcell.ColorRed))
+		}
+		match, next := fmt.Sprintf("Match: %d-%d", b.Wins, b.Losses), "ENTER: next round"
+		if b.Decided() {
+			match, next = "MATCH LOST", "ENTER: rematch"
+			if b.Wins > b.Losses {
+				match = "MATCH WON"
+			}
+		}
+		drawCenteredText(screen, x0, y0+height/2, width, match, tcell.StyleDefault.Foreground(tcell.ColorYellow))
+		drawCenteredText(screen, x0, y0+height/2+2, width, fitText(width, next, "⏎ next"), tcell.StyleDefault)
+		drawCenteredText(screen, x0, y0+height/2+3, width, fitText(width, "ESC: menu", "ESC menu"), tcell.StyleDefault)
+		return
+	}
+
 	// Draw game over message
 	drawCenteredText(screen, x0, y0+height/2-2, width, "GAME OVER", tcell.StyleDefault.Foreground(tcell.ColorRed))
-	gameOverScore := fmt.Sprintf("Score: %d", p.Game.Score)
-	drawCenteredText(screen, x0, y0+height/2, width, gameOverScore, tcell.StyleDefault)
-	drawCenteredText(screen, x0, y0+height/2+2, width, "Press ENTER to restart", tcell.StyleDefault)
+	gameOverScore := fmt.Sprintf("Score: %d", view.Score)
+	if view.Grade != "" {
+		gameOverScore += "  Grade: " + view.Grade
+	}
+	drawCenteredText(screen, x0, y0+height/2, width, fitText(width, gameOverScore, fmt.Sprint(view.Score)), tcell.StyleDefault)
+	if view.Player != "" {
+		return // Someone else's game, e.g. the bot's: the keys are not theirs
+	}
+	drawCenteredText(screen, x0, y0+height/2+2, width, fitText(width, "ENTER: play again", "⏎ again"), tcell.StyleDefault)
+	drawCenteredText(screen, x0, y0+height/2+3, width, fitText(width, "ESC: menu", "ESC menu"), tcell.StyleDefault)
 }
 
 // drawPlayfield draws the main game grid and active piece
-func (p *PlayfieldPrimitive) drawPlayfield(screen tcell.Screen, x0, y0, width, height int) {
-	// Calculate available space for the playfield
-	playfieldWidth := PlayWidth * 2 // Double-width blocks
-	playfieldHeight := VisibleHeight
-
+func (p *PlayfieldPrimitive) drawPlayfield(screen tcell.Screen, view *Snapshot, x0, y0, width, height int) {
 	// Center the playfield within the available space
-	startX := x0 + (width-playfieldWidth)/2
-	startY := y0 + (height-playfieldHeight)/2
-
-	// Ensure we don't go outside bounds
-	if startX < x0 {
-		startX = x0
-	}
-	if startY < y0 {
-		startY = y0
+	cells := p.cells
+	startX, startY := boardOrigin(x0, y0, width, height, cells)
+	block := func(col, row int, glyphs [2]rune, style tcell.Style) {
+		if col < 0 || col >= PlayWidth || row < 0 || row >= VisibleHeight {
+			return
+		}
+		fillBlock(screen, startX+col*cells.W, startY+(VisibleHeight-1-row)*cells.H, cells, x0+width, y0+height, glyphs, style)
 	}
 
 	// Draw the game grid
-	for screenRow := 0; screenRow < playfieldHeight; screenRow++ {
-		// Convert screen row to playfield row (flip Y coordinate)
-		playfieldRow := playfieldHeight - 1 - screenRow
-
+	for row := 0; row < VisibleHeight; row++ {
 		for col := 0; col < PlayWidth; col++ {
-			// Calculate screen position
-			screenX := startX + col*2
-			screenY := startY + screenRow
-
-			// Skip if outside available area
-			if screenX >= x0+width-1 || screenY >= y0+height {
-				continue
+			// Choose character and style. Hidden stacks fade their blocks
+			// out, outlining each piece for a moment as it locks
+			glyphs, style := solid(' '), tcell.StyleDefault.Background(tcell.ColorBlack)
+			switch fade := view.CellFade(col, row); {
+			case view.Playfield[col][row] == 0:
+			case view.JustLocked(col, row):
+				glyphs = solid('▒')
+				style = tcell.StyleDefault.Foreground(tcell.ColorWhite).Background(tcell.ColorBlack)
+			case fade < 1:
+				id := PieceID(view.Playfield[col][row])
+				glyphs, style = pieceLook(view, id, fadeColor(view.PieceColor(id), fade))
 			}
+			block(col, row, glyphs, style)
+		}
+	}
 
-			// Get the cell value from playfield
-			cellVal := p.Game.Playfield[col][playfieldRow]
-
-			// Choose character and style
-			ch, style := ' ', tcell.StyleDefault.Background(tcell.ColorBlack)
-			if cellVal != 0 {
-				ch = '█'
-				style = tcell.StyleDefault.Foreground(ColorFor(cellVal)).Background(tcell.ColorBlack)
+	// Rows waiting out the line clear delay flash white and red
+	if rows := view.Clearing; len(rows) > 0 {
+		flashColor := tcell.ColorRed
+		if view.ClearingLit {
+			flashColor = tcell.ColorWhite
+		}
+		style := tcell.StyleDefault.Foreground(flashColor).Background(tcell.ColorBlack)
+		for _, row := range rows {
+			for col := 0; col < PlayWidth; col++ {
+				block(col, row, solid('█'), style)
 			}
+		}
+	}
 
-			// Draw the block (2 characters wide)
-			screen.SetContent(screenX, screenY, ch, nil, style)
-			if screenX+1 < x0+width {
-				screen.SetContent(screenX+1, screenY, ch, nil, style)
-			}
+	// Draw the practice hint as a dashed outline where the bot would put the piece
+	if hint := view.Hints.Hint; hint != nil {
+		style := tcell.StyleDefault.Foreground(view.PieceColor(hint.Placement.Piece)).Background(tcell.ColorBlack)
+		for _, c := range hint.Placement.Cells() {
+			block(c.X, c.Y, solid('╌'), style)
+		}
+	}
+
+	// Draw the ghost, where a hard drop would put the piece
+	if ghost := view.Ghost; ghost != nil {
+		style := tcell.StyleDefault.Foreground(view.PieceColor(ghost.Piece)).Background(tcell.ColorBlack)
+		for _, c := range ghost.Cells() {
+			block(c.X, c.Y, solid('░'), style)
 		}
 	}
 
 	// Draw the current falling piece if present
-	if p.Game.Current != nil {
-		for _, b := range p.Game.Current.Blocks {
-			// Calculate absolute position in playfield
-			col := p.Game.Current.Position.X + b.X
-			playfieldRow := p.Game.Current.Position.Y + b.Y
-
-			// Only draw blocks that are within the visible area
-			if col >= 0 && col < PlayWidth && playfieldRow >= 0 && playfieldRow < VisibleHeight {
-				// Convert to screen coordinates
-				screenRow := playfieldHeight - 1 - playfieldRow
-				screenX := startX + col*2
-				screenY := startY + screenRow
-
-				// Skip if outside available area
-				if screenX >= x0+width-1 || screenY >= y0+height {
-					continue
-				}
-
-				// Draw the falling piece block
-				style := tcell.StyleDefault.Foreground(p.Game.Current.Color)
-				screen.SetContent(screenX, screenY, '█', nil, style)
-				if screenX+1 < x0+width {
-					screen.SetContent(screenX+1, screenY, '█', nil, style)
-				}
-			}
+	if cur := view.Current; cur != nil {
+		glyphs, style := pieceLook(view, cur.Piece, view.PieceColor(cur.Piece))
+		for _, c := range cur.Cells() {
+			block(c.X, c.Y, glyphs, style)
 		}
 	}
 }
 
-// drawAnimatingLines draws flashing animation for rows being cleared
-func (p *PlayfieldPrimitive) drawAnimatingLines(screen tcell.Screen, x0, y0, width, height int) {
-	// Toggle flash state for animation
-	p.flashOn = !p.flashOn
-
-	// Get current flashing rows from the game state
-	// This should be set when entering Animating state
-	if len(p.flashingRows) == 0 {
-		// Use a default if not set properly
-		return
+// fillBlock draws one block as cells.W by cells.H terminal cells with its
+// top left at x, y, clipped to the right and bottom edges. The glyph pair
+// repeats across the block; a one-cell block shows the first of the pair.
+func fillBlock(screen tcell.Screen, x, y int, cells cellSize, right, bottom int, glyphs [2]rune, style tcell.Style) {
+	for dy := 0; dy < cells.H && y+dy < bottom; dy++ {
+		for dx := 0; dx < cells.W && x+dx < right; dx++ {
+			screen.SetContent(x+dx, y+dy, glyphs[dx%2], nil, style)
+		}
 	}
+}
 
-	// Draw flashing rows
-	flashColor := tcell.ColorWhite
-	if !p.flashOn {
-		flashColor = tcell.ColorRed
-	}
+// solid is a glyph pair of one glyph.
+func solid(ch rune) [2]rune {
+	return [2]rune{ch, ch}
+}
 
-	// Draw each flashing row
-	for _, playFieldRow := range p.flashingRows {
-		// Skip if row is outside of the visible area
-		if playFieldRow < HiddenBuffer {
-			continue
-		}
+// pieceLook is how a block of piece id looks in color: solid, or with
+// patterns on, the piece's glyphs on a background of the color.
+func pieceLook(view *Snapshot, id PieceID, color tcell.Color) ([2]rune, tcell.Style) {
+	if glyphs, ok := PieceGlyphs[id]; ok && view.Patterns {
+		return glyphs, tcell.StyleDefault.Foreground(tcell.ColorBlack).Background(color)
+	}
+	return solid('█'), tcell.StyleDefault.Foreground(color).Background(tcell.ColorBlack)
+}
 
-		// Convert playfield row to visible row index
-		visRow := playFieldRow - HiddenBuffer
+// boardOrigin is the top left of the board, blocks cells in size, centered
+// in the area, or of the area if the board does not fit.
+func boardOrigin(x0, y0, width, height int, cells cellSize) (int, int) {
+	startX := x0 + max(0, (width-PlayWidth*cells.W)/2)
+	startY := y0 + max(0, (height-VisibleHeight*cells.H)/2)
+	return startX, startY
+}
 
-		// Convert to screen coordinates (inverted so 0 is at bottom)
-		screenY := y0 + (VisibleHeight - 1 - visRow)
+// fadeColor darkens c towards the black background; 0 keeps it, 1 is black.
+func fadeColor(c tcell.Color, fade float64) tcell.Color {
+	if fade <= 0 {
+		return c
+	}
+	r, g, b := c.RGB()
+	keep := 1 - fade
+	return tcell.NewRGBColor(int32(float64(r)*keep), int32(float64(g)*keep), int32(float64(b)*keep))
+}
 
-		// Draw the flashing row
-		if screenY >= y0 && screenY < y0+height {
-			style := tcell.StyleDefault.Foreground(flashColor)
-			for col := 0; col < PlayWidth && col*2 < width; col++ {
-				screen.SetContent(x0+col*2, screenY, '█', nil, style)
-				if x0+col*2+1 < x0+width {
-					screen.SetContent(x0+col*2+1, screenY, '█', nil, style)
-				}
-			}
+// drawCenteredText draws text centered horizontally at the given y
+// position, cut off at the edges of the area if it is too long
+func drawCenteredText(screen tcell.Screen, x, y, width int, text string, style tcell.Style) {
+	runes := []rune(text)
+	startX := max(0, (width-len(runes))/2)
+	for i, r := range runes {
+		if startX+i >= width {
+			break
 		}
+		screen.SetContent(x+startX+i, y, r, nil, style)
 	}
 }
 
-// drawCenteredText draws text centered horizontally at the given y position
-func drawCenteredText(screen tcell.Screen, x, y, width int, text string, style tcell.Style) {
-	textWidth := len(text)
-	startX := x + (width-textWidth)/2
-	for i, r := range text {
-		screen.SetContent(startX+i, y, r, nil, style)
+// fitText is the first of texts, from the longest, that fits in width, or
+// the last one if none does.
+func fitText(width int, texts ...string) string {
+	for _, text := range texts {
+		if len([]rune(text)) <= width {
+			return text
+		}
 	}
+	return texts[len(texts)-1]
 }
 
 // Draw method for StatusPrimitive
@@ -310,6 +336,10 @@ func (s *StatusPrimitive) Draw(screen tcell.Screen) {
 	// Draw border & background
 	s.Box.DrawForSubclass(screen, s)
 	x0, y0, width, height := s.GetInnerRect()
+	view := s.Source.Snapshot()
+	if view == nil {
+		return
+	}
 
 	// Clear the inner area
 	for x := 0; x < width; x++ {
@@ -323,44 +353,149 @@ func (s *StatusPrimitive) Draw(screen tcell.Screen) {
 
 	// Game State
 	var stateText string
-	switch s.Game.State {
+	switch view.State {
 	case Playing:
 		stateText = "PLAYING"
+		if view.Phase == PhaseLineClear {
+			stateText = "CLEARING"
+		}
 	case Paused:
 		stateText = "PAUSED"
 	case GameOver:
 		stateText = "GAME OVER"
 	case MainMenu:
 		stateText = "MAIN MENU"
-	case Animating:
-		stateText = "CLEARING"
+	case PuzzleSelect:
+		stateText = "PUZZLES"
 	default:
 		stateText = "UNKNOWN"
 	}
 
 	if currentLine < height {
 		drawLeftAlignedText(screen, x0, y0+currentLine, width, fmt.Sprintf("State: %s", stateText), tcell.StyleDefault.Foreground(tcell.ColorYellow))
+		if button := pauseButton(view); button != "" {
+			drawLeftAlignedText(screen, x0+width-len(button), y0+currentLine, len(button), button, tcell.StyleDefault.Foreground(tcell.ColorWhite).Background(tcell.ColorNavy))
+		}
 		currentLine += 2
 	}
 
+	// Short-lived notices (exports) use the gap under the state
+	if view.Notice != "" && height > 1 {
+		drawLeftAlignedText(screen, x0, y0+1, width, view.Notice, tcell.StyleDefault.Foreground(tcell.ColorAqua))
+	}
+
 	// Score
 	if currentLine < height {
-		drawLeftAlignedText(screen, x0, y0+currentLine, width, fmt.Sprintf("Score: %d", s.Game.Score), tcell.StyleDefault.Foreground(tcell.ColorGreen))
+		scoreText := fmt.Sprintf("Score: %d", view.Score)
+		drawLeftAlignedText(screen, x0, y0+currentLine, width, scoreText, tcell.StyleDefault.Foreground(tcell.ColorGreen))
+		if view.Scored != "" && width > len(scoreText)+1 {
+			drawLeftAlignedText(screen, x0+len(scoreText)+1, y0+currentLine, width-len(scoreText)-1, view.Scored, tcell.StyleDefault.Foreground(tcell.ColorGray))
+		}
 		currentLine += 1
 	}
 
-	// Level
+	// Level, out of the section in Master mode
 	if currentLine < height {
-		drawLeftAlignedText(screen, x0, y0+currentLine, width, fmt.Sprintf("Level: %d", s.Game.Level), tcell.StyleDefault.Foreground(tcell.ColorBlue))
+		levelText := fmt.Sprintf("Level: %d", view.Level)
+		if view.Section > 0 {
+			levelText = fmt.Sprintf("Level: %d/%d", view.Level, view.Section)
+		}
+		drawLeftAlignedText(screen, x0, y0+currentLine, width, levelText, tcell.StyleDefault.Foreground(tcell.ColorBlue))
 		currentLine += 1
 	}
 
 	// Lines Cleared
 	if currentLine < height {
-		drawLeftAlignedText(screen, x0, y0+currentLine, width, fmt.Sprintf("Lines: %d", s.Game.LinesCleared), tcell.StyleDefault.Foreground(tcell.ColorPurple))
+		drawLeftAlignedText(screen, x0, y0+currentLine, width, fmt.Sprintf("Lines: %d", view.Lines), tcell.StyleDefault.Foreground(tcell.ColorPurple))
+		currentLine += 1
+	}
+
+	// Grade
+	if currentLine < height && view.Grade != "" {
+		drawLeftAlignedText(screen, x0, y0+currentLine, width, "Grade: "+view.Grade, tcell.StyleDefault.Foreground(tcell.ColorOrange))
+		currentLine += 1
+	}
+
+	// Garbage sent, and waiting to rise
+	if gs := view.Garbage; gs != nil && currentLine < height {
+		drawLeftAlignedText(screen, x0, y0+currentLine, width, fmt.Sprintf("Sent: %d", gs.Sent), tcell.StyleDefault.Foreground(tcell.ColorOrange))
+		if gs.Incoming > 0 {
+			text := fmt.Sprintf("Incoming: %d", gs.Incoming)
+			if width > 10+len(text) {
+				drawLeftAlignedText(screen, x0+10, y0+currentLine, width-10, text, tcell.StyleDefault.Foreground(tcell.ColorRed))
+			}
+		}
+		currentLine += 1
+	}
+
+	// Match score and the bot played against
+	if b := view.Battle; b != nil && currentLine < height {
+		text := fmt.Sprintf("Match: %d-%d, best of %d", b.Wins, b.Losses, b.BestOf)
+		drawLeftAlignedText(screen, x0, y0+currentLine, width, fitText(width, text, fmt.Sprintf("Match: %d-%d", b.Wins, b.Losses), fmt.Sprintf("%d-%d", b.Wins, b.Losses)), tcell.StyleDefault.Foreground(tcell.ColorYellow))
+		currentLine += 1
+	}
+
+	// Mode and play time
+	if currentLine < height {
+		elapsed := view.Elapsed
+		modeName := view.Mode
+		if view.Puzzle != nil {
+			modeName = "puzzle"
+		}
+		modeText := fmt.Sprintf("Mode: %s %d:%02d", modeName, int(elapsed.Minutes()), int(elapsed.Seconds())%60)
+		drawLeftAlignedText(screen, x0, y0+currentLine, width, modeText, tcell.StyleDefault.Foreground(tcell.ColorTeal))
 		currentLine += 2
 	}
 
+	// Puzzle name, goal and how many pieces are left for it
+	if pz := view.Puzzle; pz != nil {
+		puzzleLines := []string{pz.Name, "Goal: " + pz.Goal.String()}
+		if left := pz.PiecesLeft; left >= 0 {
+			puzzleLines = append(puzzleLines, fmt.Sprintf("Pieces left: %d", left))
+		}
+		for _, line := range puzzleLines {
+			if currentLine < height {
+				drawLeftAlignedText(screen, x0, y0+currentLine, width, line, tcell.StyleDefault.Foreground(tcell.ColorWhite))
+				currentLine += 1
+			}
+		}
+		currentLine += 1
+	}
+
+	// Practice hint readout: what the suggested placement does and how
+	// often the player agreed with it
+	if view.Hints.Available {
+		hintText := "Hint (H): off"
+		if hint := view.Hints.Hint; hint != nil {
+			hintText = "Hint: " + hint.Why()
+		} else if view.Hints.Enabled {
+			hintText = "Hint: thinking..."
+		}
+		if currentLine < height {
+			drawLeftAlignedText(screen, x0, y0+currentLine, width, hintText, tcell.StyleDefault.Foreground(tcell.ColorWhite))
+			currentLine += 1
+		}
+
+		if st := view.Stats; currentLine < height && st.Hinted > 0 {
+			matchText := fmt.Sprintf("Matched: %d/%d (%d%%)", st.HintMatches, st.Hinted, st.HintMatches*100/st.Hinted)
+			drawLeftAlignedText(screen, x0, y0+currentLine, width, matchText, tcell.StyleDefault.Foreground(tcell.ColorGray))
+			currentLine += 1
+		}
+		currentLine += 1
+	}
+
+	// Spectators get the player's name instead of the controls
+	if view.Player != "" {
+		if currentLine < height {
+			drawLeftAlignedText(screen, x0, y0+currentLine, width, "WATCHING:", tcell.StyleDefault.Foreground(tcell.ColorWhite))
+			currentLine += 1
+		}
+		if currentLine < height {
+			drawLeftAlignedText(screen, x0, y0+currentLine, width, view.Player, tcell.StyleDefault.Foreground(tcell.ColorYellow))
+		}
+		return
+	}
+
 	// Add key shortcuts help
 	if currentLine < height {
 		drawLeftAlignedText(screen, x0, y0+currentLine, width, "CONTROLS:", tcell.StyleDefault.Foreground(tcell.ColorWhite))
@@ -369,11 +504,18 @@ func (s *StatusPrimitive) Draw(screen tcell.Screen) {
 
 	controls := []string{
 		"← → Move",
-		"↑ Rotate",
+		"↑/X Rotate  Z Left",
 		"↓ Soft Drop",
-		"Space Drop",
+		"Space Drop  C Hold",
 		"ESC Pause",
-		"Q Quit",
+		"Q Quit  F/G Fumen",
+		"S Screenshot",
+	}
+	if view.UpDrop {
+		controls[1], controls[3] = "X Rotate  Z Left", "↑/Space Drop  C Hold"
+	}
+	if view.Puzzle != nil {
+		controls = append(controls, "R Retry")
 	}
 
 	for _, control := range controls {
@@ -399,6 +541,10 @@ func (n *NextPiecePrimitive) Draw(screen tcell.Screen) {
 	// Draw border & background
 	n.Box.DrawForSubclass(screen, n)
 	x0, y0, width, height := n.GetInnerRect()
+	view := n.Source.Snapshot()
+	if view == nil {
+		return
+	}
 
 	// Clear the inner area
 	for x := 0; x < width; x++ {
@@ -408,42 +554,64 @@ func (n *NextPiecePrimitive) Draw(screen tcell.Screen) {
 	}
 
 	// Draw the next piece if available
-	// NextQueue[0] is always the next piece that will spawn when current piece locks
-	if len(n.Game.NextQueue) > 0 {
-		nextPieceID := n.Game.NextQueue[0]
-
-		// Validate piece ID
-		if nextPieceID < I || nextPieceID > Z {
-			return // Invalid piece ID, don't draw anything
-		}
-
-		// Update the NEXT box title
-		n.Box.SetTitle(" NEXT ")
-		shape := PieceShapes[nextPieceID][0]
-		color := PieceColors[nextPieceID]
-
-		// Calculate center position for the piece
-		centerX := x0 + width/2
-		centerY := y0 + height/2
-
-		// Draw the piece blocks
-		for y := 0; y < 4; y++ {
-			for x := 0; x < 4; x++ {
-				if shape[y][x] {
-					// Calculate screen position (centered)
-					screenX := centerX + (x-2)*2 // *2 for double-width, -2 to center 4x4 grid
-					screenY := centerY + (y - 2) // -2 to center 4x4 grid
-
-					// Only draw if within bounds
-					if screenX >= x0 && screenX < x0+width-1 && screenY >= y0 && screenY < y0+height {
-						style := tcell.StyleDefault.Foreground(color).Background(tcell.ColorBlack)
-						screen.SetContent(screenX, screenY, '█', nil, style)
-						if screenX+1 < x0+width {
-							screen.SetContent(screenX+1, screenY, '█', nil, style)
-						}
-					}
-				}
-			}
+	// Next[0] is always the next piece that will spawn when current piece locks
+	if len(view.Next) > 0 {
+		drawPiecePreview(screen, view, x0, y0, width, height, n.cells, view.Next[0], view.PieceColor(view.Next[0]))
+	}
+}
+
+// Draw method for HoldPrimitive
+func (h *HoldPrimitive) Draw(screen tcell.Screen) {
+	// Draw border & background
+	h.Box.DrawForSubclass(screen, h)
+	x0, y0, width, height := h.GetInnerRect()
+	view := h.Source.Snapshot()
+	if view == nil {
+		return
+	}
+
+	// Clear the inner area
+	for x := 0; x < width; x++ {
+		for y := 0; y < height; y++ {
+			screen.SetContent(x0+x, y0+y, ' ', nil, tcell.StyleDefault.Background(tcell.ColorBlack))
+		}
+	}
+
+	// A piece that cannot be swapped back in right now is grayed out
+	if id := view.Hold; id != 0 {
+		color := view.PieceColor(id)
+		if view.HoldUsed {
+			color = tcell.ColorGray
+		}
+		drawPiecePreview(screen, view, x0, y0, width, height, h.cells, id, color)
+	}
+}
+
+// drawPiecePreview draws a piece the way it spawns, blocks cells in size,
+// centered in the area
+func drawPiecePreview(screen tcell.Screen, view *Snapshot, x0, y0, width, height int, cells cellSize, id PieceID, color tcell.Color) {
+	// Validate piece ID
+	if id < I || id > Z {
+		return // Invalid piece ID, don't draw anything
+	}
+
+	// Center the piece's bounding box in the area
+	blocks := ShapeBlocks(id, 0)
+	left, right, bottom, top := blocks[0].X, blocks[0].X, blocks[0].Y, blocks[0].Y
+	for _, b := range blocks {
+		left, right = min(left, b.X), max(right, b.X)
+		bottom, top = min(bottom, b.Y), max(top, b.Y)
+	}
+	startX := x0 + (width-(right-left+1)*cells.W)/2
+	startY := y0 + (height-(top-bottom+1)*cells.H)/2
+
+	// Block Y counts upwards
+	glyphs, style := pieceLook(view, id, color)
+	for _, b := range blocks {
+		x := startX + (b.X-left)*cells.W
+		y := startY + (top-b.Y)*cells.H
+		if x >= x0 && y >= y0 {
+			fillBlock(screen, x, y, cells, x0+width, y0+height, glyphs, style)
 		}
 	}
 }
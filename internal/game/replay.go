@@ -0,0 +1,331 @@
+package game
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// --- Replays ------------------------------------------------------------------
+//
+// The engine is deterministic: from the same randomizer state, the same
+// actions on the same frames of play give the same game. A replay is just
+// that, how a game started and every action taken in it, so the game can be
+// played back (or rendered) later. Pauses are not in it, and neither are
+// puzzles or resumed games, which do not start from a fresh board.
+
+// replayVersion is bumped whenever the replay format changes.
+const replayVersion = 1
+
+// Replay is a game as it was played.
+type Replay struct {
+	Version  int           `json:"version"`
+	Recorded time.Time     `json:"recorded"`
+	Mode     string        `json:"mode"`
+	Stack    Stack         `json:"stack"`
+	Scoring  string        `json:"scoring,omitempty"` // Name of the Scorer, the mode's own if empty
+	Garbage  *GarbageRules `json:"garbage,omitempty"` // Nil for a mode without garbage
+	Seed     uint64        `json:"seed"`
+	RNG      []byte        `json:"rng,omitempty"` // PCG state when the game started
+	Inputs   []ReplayInput `json:"inputs"`
+	Frames   int           `json:"frames"` // Frames of play the game lasted
+	Score    int           `json:"score,omitempty"`
+	Lines    int           `json:"lines,omitempty"`
+}
+
+// ReplayInput is an action, or garbage from an opponent, and when it came
+// in.
+type ReplayInput struct {
+	Frame   int    `json:"frame"` // Frames of play before the action
+	Action  Action `json:"action"`
+	Garbage int    `json:"garbage,omitempty"` // Lines received instead of an action
+}
+
+// SetReplayFile records every game to path, each one replacing the last.
+func (g *Game) SetReplayFile(path string) {
+	g.replayPath = path
+}
+
+// SetReplayDir keeps every finished game in dir as well, for the Replays
+// page.
+func (g *Game) SetReplayDir(dir string) {
+	g.replayDir = dir
+}
+
+// ReadReplay decodes a replay and checks that this version can play it.
+func ReadReplay(r io.Reader) (*Replay, error) {
+	var rp Replay
+	if err := json.NewDecoder(r).Decode(&rp); err != nil {
+		return nil, err
+	}
+	if rp.Version != replayVersion {
+		return nil, fmt.Errorf("replay version %d, want %d", rp.Version, replayVersion)
+	}
+	return &rp, nil
+}
+
+// startReplay begins recording a game that is about to draw its first bag.
+func (g *Game) startReplay() {
+	g.replay = nil
+	if (g.replayPath == "" && g.replayDir == "") || g.puzzle != nil {
+		return
+	}
+	if g.rng == nil {
+		g.SetSeed(rand.Uint64())
+	}
+	state, err := g.pcg.MarshalBinary()
+	if err != nil {
+		return
+	}
+	g.replay = &Replay{
+		Version:  replayVersion,
+		Recorded: time.Now(),
+		Mode:     g.Mode.Name,
+		Stack:    g.Mode.Stack,
+		Scoring:  g.scorer().Name(),
+		Garbage:  g.Mode.Garbage,
+		Seed:     g.seed,
+		RNG:      state,
+	}
+}
+
+// act performs an action from the player or the bot, recording it.
+func (g *Game) act(a Action) {
+	if g.replay != nil && g.State == Playing && a != ActionNone {
+		g.replay.Inputs = append(g.replay.Inputs, ReplayInput{Frame: g.playFrames, Action: a})
+	}
+	g.applyAction(a)
+}
+
+// writeReplay writes the game recorded so far to the replay file.
+func (g *Game) writeReplay() error {
+	if g.replay == nil || g.replayPath == "" {
+		return nil
+	}
+	g.replay.Frames = g.playFrames
+	return g.replay.save(g.replayPath)
+}
+
+// save writes the replay to path, replacing what was there.
+func (rp *Replay) save(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	tmp := path + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	err = json.NewEncoder(f).Encode(rp)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmp, path)
+	}
+	if err != nil {
+		os.Remove(tmp)
+	}
+	return err
+}
+
+// fileName names the replay in the replay directory. Names sort by when the
+// game was played.
+func (rp *Replay) fileName() string {
+	return rp.Recorded.Format("20060102-150405") + "-" + rp.Mode + replayExt
+}
+
+// replayExt is the extension of the replays in the replay directory.
+const replayExt = ".replay"
+
+// trackReplay writes each game out as it ends.
+func (g *Game) trackReplay(e Event) {
+	ev, ok := e.(GameOverEvent)
+	if !ok || g.replay == nil {
+		return
+	}
+	g.replay.Frames, g.replay.Score, g.replay.Lines = g.playFrames, ev.Score, ev.Lines
+	err := g.writeReplay()
+	if g.replayDir != "" {
+		err = errors.Join(err, g.replay.save(filepath.Join(g.replayDir, g.replay.fileName())))
+	}
+	if err != nil {
+		g.setNotice("Replay not saved: " + err.Error())
+	}
+	g.replay = nil
+}
+
+// ReplayPlayer plays a replay back on a game of its own, without a screen.
+// It is a Source, so the primitives draw it like a live game.
+type ReplayPlayer struct {
+	g      *Game
+	replay *Replay
+	next   int // Next input to apply
+}
+
+// NewReplayPlayer sets up the game the replay starts with.
+func NewReplayPlayer(r *Replay) (*ReplayPlayer, error) {
+	mode, err := ModeByName(r.Mode)
+	if err != nil {
+		return nil, err
+	}
+	mode.Stack, mode.Garbage = r.Stack, r.Garbage
+	if r.Scoring != "" {
+		if mode.Scorer, err = ScorerByName(r.Scoring); err != nil {
+			return nil, err
+		}
+	}
+
+	g := NewGame(nil, nil)
+	g.SetMode(mode)
+	g.settings.Controls.Mouse = false // There is nothing to click in a replay
+	g.SetSeed(r.Seed)
+	if r.RNG != nil {
+		if err := g.pcg.UnmarshalBinary(r.RNG); err != nil {
+			return nil, fmt.Errorf("randomizer state: %w", err)
+		}
+	}
+	g.StartGame()
+	g.publish()
+	return &ReplayPlayer{g: g, replay: r}, nil
+}
+
+// Step plays one frame, unless the replay is over.
+func (p *ReplayPlayer) Step() {
+	if p.Done() {
+		return
+	}
+	inputs := p.replay.Inputs
+	for p.next < len(inputs) && inputs[p.next].Frame <= p.g.playFrames {
+		if in := inputs[p.next]; in.Garbage > 0 {
+			p.g.ReceiveGarbage(in.Garbage)
+		} else {
+			p.g.applyAction(in.Action)
+		}
+		p.next++
+	}
+	p.g.step()
+	p.g.publish()
+}
+
+// Done reports whether the replay has played out.
+func (p *ReplayPlayer) Done() bool {
+	return p.g.State != Playing || p.g.playFrames >= p.replay.Frames
+}
+
+// Snapshot returns the game as of the last frame played.
+func (p *ReplayPlayer) Snapshot() *Snapshot {
+	return p.g.Snapshot()
+}
+
+// FumenGame writes the game as far as it has played, like Game.FumenGame.
+func (p *ReplayPlayer) FumenGame() string {
+	return p.g.FumenGame()
+}
+
+// --- Replays Page -------------------------------------------------------------
+
+// maxReplays is how many of the latest replays the Replays page lists.
+const maxReplays = 100
+
+// addReplays adds the Replays page if games are kept in a replay directory.
+func (ui *screens) addReplays() {
+	if ui.g.replayDir == "" {
+		return
+	}
+	ui.replays = newMenuList()
+	ui.replays.SetDoneFunc(ui.back)
+	ui.replays.SetSelectedFunc(func(i int, _, _ string, _ rune) {
+		if i < len(ui.replayFiles) {
+			ui.playReplay(ui.replayFiles[i])
+		}
+	})
+	ui.add(pageReplays, "REPLAYS", ui.replays, ui.replays, "ENTER watch • ESC back")
+}
+
+// refreshReplays lists the replays in the directory, newest first.
+func (ui *screens) refreshReplays() {
+	ui.replays.Clear()
+	ui.replayFiles = nil
+	names, err := filepath.Glob(filepath.Join(ui.g.replayDir, "*"+replayExt))
+	if err != nil || len(names) == 0 {
+		ui.replays.AddItem("No replays yet", "Finished games show up here", 0, nil)
+		return
+	}
+	slices.Reverse(names)
+	for _, name := range names[:min(len(names), maxReplays)] {
+		rp, err := readReplayFile(name)
+		if err != nil {
+			ui.replays.AddItem(filepath.Base(name), err.Error(), 0, nil)
+		} else {
+			ui.replays.AddItem(rp.Recorded.Format("2006-01-02 15:04")+"  "+rp.Mode,
+				fmt.Sprintf("Score %d, %d lines", rp.Score, rp.Lines), 0, nil)
+		}
+		ui.replayFiles = append(ui.replayFiles, name)
+	}
+}
+
+// readReplayFile reads the replay in path.
+func readReplayFile(path string) (*Replay, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ReadReplay(f)
+}
+
+// playReplay plays the replay in path back on a board of its own, in real
+// time. ESC, or leaving the page any other way, stops it.
+func (ui *screens) playReplay(path string) {
+	rp, err := readReplayFile(path)
+	var player *ReplayPlayer
+	if err == nil {
+		player, err = NewReplayPlayer(rp)
+	}
+	if err != nil {
+		i := ui.replays.GetCurrentItem()
+		ui.replays.SetItemText(i, filepath.Base(path), "[red]"+tview.Escape(err.Error()))
+		return
+	}
+
+	board := NewBoard(player)
+	board.SetInputCapture(func(ev *tcell.EventKey) *tcell.EventKey {
+		if ev.Key() == tcell.KeyEscape {
+			ui.show(pageReplays)
+			return nil
+		}
+		return ev
+	})
+	ui.pages.AddPage(pageReplay, board, true, false)
+	ui.focus[pageReplay] = board
+
+	// The player runs on a goroutine of its own; the board only reads its
+	// snapshots
+	stop := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(TickRate)
+		defer ticker.Stop()
+		for !player.Done() {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				player.Step()
+				ui.g.app.QueueUpdateDraw(func() {})
+			}
+		}
+	}()
+	ui.show(pageReplay)
+	ui.stopReplay = func() { close(stop) }
+}
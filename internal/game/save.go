@@ -0,0 +1,329 @@
+package game
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// --- Save & Resume ------------------------------------------------------------
+//
+// A game in progress is written to a save file when the player quits (and
+// every so often with autosave), and the main menu offers to continue it on
+// the next launch. The save holds everything the rules depend on, the
+// randomizer included, so a resumed game plays out exactly like the original
+// would have.
+
+// saveVersion is bumped whenever the save format changes. Saves written by
+// another version are not offered.
+const saveVersion = 2
+
+// savedGame is the on-disk form of a game in progress.
+type savedGame struct {
+	Version int           `json:"version"`
+	Saved   time.Time     `json:"saved"`
+	Mode    string        `json:"mode"`
+	Puzzle  *Puzzle       `json:"puzzle,omitempty"`
+	Master  *masterRun    `json:"master,omitempty"`
+	Garbage *garbageRun   `json:"garbage,omitempty"`
+	Stack   Stack         `json:"stack"`
+	Scoring string        `json:"scoring,omitempty"`       // Name of the Scorer, the mode's own if empty
+	Rules   *GarbageRules `json:"garbage_rules,omitempty"` // Nil for a mode without garbage
+
+	Playfield  Field         `json:"playfield"`
+	LockedAt   Field         `json:"locked_at"`
+	Current    *Placement    `json:"current,omitempty"`
+	NextQueue  []PieceID     `json:"next_queue"`
+	Hold       PieceID       `json:"hold"`
+	HoldUsed   bool          `json:"hold_used"`
+	Seed       uint64        `json:"seed"`
+	RNG        []byte        `json:"rng,omitempty"` // PCG state
+	StartField Field         `json:"start_field"`
+	History    []historyStep `json:"history"`
+
+	Score        int   `json:"score"`
+	Level        int   `json:"level"`
+	LinesCleared int   `json:"lines_cleared"`
+	Combo        int   `json:"combo"`
+	ComboLines   int   `json:"combo_lines,omitempty"`
+	B2B          bool  `json:"b2b"`
+	Stats        Stats `json:"stats"`
+
+	LastMoveWasRotation bool      `json:"last_move_was_rotation"`
+	PlayFrames          int       `json:"play_frames"`
+	GravityProgress     float64   `json:"gravity_progress"` // Fraction of a row
+	LockFrames          int       `json:"lock_frames,omitempty"`
+	LowestY             int       `json:"lowest_y,omitempty"`
+	LastKick            int       `json:"last_kick,omitempty"`
+	Drops               dropTally `json:"drops"`
+
+	// Saved between pieces, during a line clear or entry delay
+	Phase       Phase    `json:"phase,omitempty"`
+	PhaseFrames int      `json:"phase_frames,omitempty"`
+	Clearing    []int    `json:"clearing,omitempty"`
+	ClearTSpin  bool     `json:"clear_tspin,omitempty"`
+	ClearMini   bool     `json:"clear_mini,omitempty"` // The T-spin was a mini
+	Buffered    []Action `json:"buffered,omitempty"`
+}
+
+// Save writes the game in progress.
+func (g *Game) Save(w io.Writer) error {
+	if g.State != Playing && g.State != Paused {
+		return fmt.Errorf("no game in progress")
+	}
+
+	s := savedGame{
+		Version:             saveVersion,
+		Saved:               time.Now(),
+		Mode:                g.Mode.Name,
+		Stack:               g.Mode.Stack,
+		Scoring:             g.scorer().Name(),
+		Rules:               g.Mode.Garbage,
+		Playfield:           g.Playfield,
+		LockedAt:            g.lockedAt,
+		NextQueue:           g.NextQueue,
+		Hold:                g.Hold,
+		HoldUsed:            g.holdUsed,
+		Seed:                g.seed,
+		StartField:          g.startField,
+		History:             g.history,
+		Score:               g.Score,
+		Level:               g.Level,
+		LinesCleared:        g.LinesCleared,
+		Combo:               g.Combo,
+		ComboLines:          g.comboLines,
+		B2B:                 g.B2B,
+		Stats:               g.Stats,
+		LastMoveWasRotation: g.LastMoveWasRotation,
+		PlayFrames:          g.playFrames,
+		GravityProgress:     g.gravityProgress,
+		LockFrames:          g.lockFrames,
+		LowestY:             g.lowestY,
+		LastKick:            g.lastKick,
+		Drops:               g.drops,
+		Master:              g.master,
+		Phase:               g.Phase,
+		PhaseFrames:         g.phaseFrames,
+		Clearing:            g.clearing,
+		ClearTSpin:          g.clearTSpin != NoTSpin,
+		ClearMini:           g.clearTSpin == MiniTSpin,
+		Buffered:            g.buffered,
+	}
+	if g.puzzle != nil {
+		s.Puzzle = &g.puzzle.Puzzle
+	}
+	if g.Current != nil {
+		p := g.Current.placement()
+		s.Current = &p
+	}
+	if g.pcg != nil {
+		state, err := g.pcg.MarshalBinary()
+		if err != nil {
+			return err
+		}
+		s.RNG = state
+	}
+	if run := g.garbage; run != nil {
+		state, err := run.pcg.MarshalBinary()
+		if err != nil {
+			return err
+		}
+		run.RNG = state
+		s.Garbage = run
+	}
+	return json.NewEncoder(w).Encode(s)
+}
+
+// Resume continues a saved game. It starts out paused.
+func (g *Game) Resume(r io.Reader) error {
+	s, err := readSave(r)
+	if err != nil {
+		return err
+	}
+	mode, err := ModeByName(s.Mode)
+	if err != nil {
+		return err
+	}
+
+	g.Mode = mode
+	g.Mode.Stack, g.Mode.Garbage = s.Stack, s.Rules
+	if s.Scoring != "" {
+		if g.Mode.Scorer, err = ScorerByName(s.Scoring); err != nil {
+			return err
+		}
+	}
+	g.puzzle, g.replay, g.battle = nil, nil, nil
+	if s.Puzzle != nil {
+		g.puzzle = &puzzleRun{Puzzle: *s.Puzzle}
+	}
+	g.Playfield, g.startField = s.Playfield, s.StartField
+	g.lockedAt = s.LockedAt
+	g.history = append(g.history[:0], s.History...)
+	g.NextQueue = append(g.NextQueue[:0], s.NextQueue...)
+	g.Hold, g.holdUsed = s.Hold, s.HoldUsed
+	g.Score, g.Level, g.LinesCleared = s.Score, s.Level, s.LinesCleared
+	g.Combo, g.comboLines, g.B2B, g.Stats = s.Combo, s.ComboLines, s.B2B, s.Stats
+	g.LastMoveWasRotation, g.lastKick, g.drops = s.LastMoveWasRotation, s.LastKick, s.Drops
+	g.playFrames, g.gravityProgress = s.PlayFrames, s.GravityProgress
+	g.lockFrames, g.lowestY, g.master = s.LockFrames, s.LowestY, s.Master
+	g.setPhase(s.Phase, s.PhaseFrames)
+	g.clearing = append([]int(nil), s.Clearing...)
+	g.clearTSpin = NoTSpin
+	switch {
+	case s.ClearMini:
+		g.clearTSpin = MiniTSpin
+	case s.ClearTSpin:
+		g.clearTSpin = FullTSpin
+	}
+	g.buffered = append([]Action(nil), s.Buffered...)
+
+	g.SetSeed(s.Seed)
+	if s.RNG != nil {
+		if err := g.pcg.UnmarshalBinary(s.RNG); err != nil {
+			return fmt.Errorf("randomizer state: %w", err)
+		}
+	}
+
+	g.garbage = nil
+	if run := s.Garbage; run != nil && g.Mode.Garbage != nil {
+		run.seed(0)
+		if err := run.pcg.UnmarshalBinary(run.RNG); err != nil {
+			return fmt.Errorf("garbage randomizer state: %w", err)
+		}
+		g.garbage = run
+	}
+
+	g.Current = nil
+	if p := s.Current; p != nil {
+		g.Current = &Piece{
+			ID:            p.Piece,
+			RotationState: p.Rotation,
+			Color:         PieceColors[p.Piece],
+			Position:      p.Position,
+			Blocks:        ShapeBlocks(p.Piece, p.Rotation),
+		}
+	}
+
+	g.State = Paused
+	g.emit(PausedEvent{Paused: true})
+	return nil
+}
+
+// readSave decodes a save and checks that this version can resume it.
+func readSave(r io.Reader) (savedGame, error) {
+	var s savedGame
+	if err := json.NewDecoder(r).Decode(&s); err != nil {
+		return s, err
+	}
+	if s.Version != saveVersion {
+		return s, fmt.Errorf("save is version %d, this build reads version %d", s.Version, saveVersion)
+	}
+	if p := s.Current; p != nil && (p.Piece < I || p.Piece > Z || p.Rotation < 0 || p.Rotation > 3) {
+		return s, fmt.Errorf("save has a broken current piece")
+	}
+	if s.Phase < PhaseFalling || s.Phase > PhaseEntry || (s.Phase == PhaseFalling) != (s.Current != nil) {
+		return s, fmt.Errorf("save has a broken phase")
+	}
+	for _, row := range s.Clearing {
+		if row < 0 || row >= TotalHeight {
+			return s, fmt.Errorf("save has a broken line clear")
+		}
+	}
+	return s, nil
+}
+
+// --- Save File ----------------------------------------------------------------
+
+// SetSaveFile makes the game save to path on quit and offer to continue the
+// game saved there. An empty path turns saving off.
+func (g *Game) SetSaveFile(path string) {
+	g.savePath = path
+	g.saved = nil
+	if path == "" {
+		return
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	if s, err := readSave(f); err == nil {
+		g.saved = &s
+	}
+}
+
+// SetAutosave also saves every interval of play. Zero turns it off.
+func (g *Game) SetAutosave(interval time.Duration) {
+	g.autosaveFrames = int(interval / TickRate)
+}
+
+// canContinue reports whether there is a saved game to offer.
+func (g *Game) canContinue() bool {
+	return g.saved != nil
+}
+
+// continueSaved resumes the game in the save file.
+func (g *Game) continueSaved() {
+	f, err := os.Open(g.savePath)
+	if err == nil {
+		err = g.Resume(f)
+		f.Close()
+	}
+	if err != nil {
+		g.setNotice("Cannot continue: " + err.Error())
+	}
+	g.saved = nil
+}
+
+// saveProgress writes the game in progress to the save file. Bot games,
+// versus matches and games without a save file are not saved.
+func (g *Game) saveProgress() error {
+	if g.savePath == "" || g.autoplay != nil || g.battle != nil || (g.State != Playing && g.State != Paused) {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(g.savePath), 0o755); err != nil {
+		return err
+	}
+
+	// Write next to the real file and swap, so a crash never leaves half a
+	// save behind
+	tmp := g.savePath + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	err = g.Save(f)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmp, g.savePath)
+	}
+	if err != nil {
+		os.Remove(tmp)
+	}
+	return err
+}
+
+// autosave saves every autosaveFrames of play.
+func (g *Game) autosave() {
+	if g.autosaveFrames <= 0 || g.State != Playing || g.playFrames%g.autosaveFrames != 0 {
+		return
+	}
+	if err := g.saveProgress(); err != nil {
+		g.setNotice("Autosave failed: " + err.Error())
+	}
+}
+
+// trackSave throws the save away once its game is over, so a finished game
+// is never offered again.
+func (g *Game) trackSave(e Event) {
+	if _, ok := e.(GameOverEvent); !ok || g.savePath == "" || g.autoplay != nil {
+		return
+	}
+	os.Remove(g.savePath)
+	g.saved = nil
+}
@@ -0,0 +1,227 @@
+package game
+
+import (
+	"fmt"
+	"strings"
+)
+
+// --- Scoring Rules ------------------------------------------------------------
+//
+// How points are earned is up to the mode's Scorer. The game keeps the
+// chains every rule set needs (the combo, back to back) and tells the scorer
+// what each lock did; the scorer answers with awards, each with the sum that
+// explains it, so a score can be broken down after the fact. Scorers keep no
+// state of their own, which keeps saves and replays simple.
+
+// TSpin is how a T piece was spun into place.
+type TSpin int
+
+const (
+	NoTSpin   TSpin = iota
+	MiniTSpin       // Three corners taken, but not both in front of the T
+	FullTSpin       // Both front corners taken, or the last kick was the far one
+)
+
+// Lock is what a lock did, as the scorers see it.
+type Lock struct {
+	Lines      int
+	TSpin      TSpin
+	Perfect    bool // The board is empty afterwards
+	Level      int  // Level the piece locked on, counted from 0
+	Combo      int  // Locks in a row that cleared lines, this one included
+	ComboLines int  // Lines those locks cleared
+	B2B        bool // A difficult clear right after another one
+	SoftRows   int  // Rows the piece was soft dropped
+	HardRows   int  // Rows the piece was hard dropped
+}
+
+// Award is points earned for one thing, for the score breakdown.
+type Award struct {
+	Name   string // What the points are for, e.g. "T-spin double"
+	Points int
+	Sum    string // How they add up, e.g. "1200 × level 3"
+}
+
+// String reads like "T-spin double 1200 × level 3 = 3600".
+func (a Award) String() string {
+	if a.Sum == "" {
+		return fmt.Sprintf("%s %d", a.Name, a.Points)
+	}
+	return fmt.Sprintf("%s %s = %d", a.Name, a.Sum, a.Points)
+}
+
+// Scorer is a scoring rule set.
+type Scorer interface {
+	// Name is how the rule set is picked, e.g. "guideline".
+	Name() string
+	// Description says where the rules come from.
+	Description() string
+	// Drop is what dropping a piece rows rows is worth, paid as it drops.
+	Drop(kind DropKind, rows int) int
+	// Lock is what a piece that locked earns, with or without lines.
+	Lock(l Lock) []Award
+}
+
+// Scorers lists every built-in rule set.
+var Scorers = []Scorer{GuidelineScorer{}, NESScorer{}, TGMScorer{}}
+
+// ScorerByName looks up a built-in rule set, ignoring case.
+func ScorerByName(name string) (Scorer, error) {
+	for _, s := range Scorers {
+		if strings.EqualFold(s.Name(), name) {
+			return s, nil
+		}
+	}
+	names := make([]string, len(Scorers))
+	for i, s := range Scorers {
+		names[i] = s.Name()
+	}
+	return nil, fmt.Errorf("unknown scoring %q (available: %s)", name, strings.Join(names, ", "))
+}
+
+// clearName names a clear, e.g. "T-spin mini single" or "Tetris".
+func clearName(lines int, spin TSpin) string {
+	names := []string{"", "single", "double", "triple", "tetris"}
+	name := fmt.Sprintf("%d lines", lines)
+	if lines < len(names) {
+		name = names[lines]
+	}
+	switch spin {
+	case MiniTSpin:
+		name = strings.TrimSpace("T-spin mini " + name)
+	case FullTSpin:
+		name = strings.TrimSpace("T-spin " + name)
+	}
+	return capitalize(name)
+}
+
+// --- Guideline Scoring --------------------------------------------------------
+
+// GuidelineScorer scores like the current guideline games: clears and
+// T-spins times the level, half again for back to back, 50 a combo step,
+// perfect clear bonuses, and 1 a row soft dropped, 2 hard dropped.
+type GuidelineScorer struct{}
+
+// Points per clear by lines, level 1.
+var (
+	guidelineClears      = []int{0, 100, 300, 500, 800}
+	guidelineMiniTSpins  = []int{100, 200, 400}
+	guidelineTSpins      = []int{400, 800, 1200, 1600}
+	guidelinePerfects    = []int{0, 800, 1200, 1800, 2000}
+	guidelineB2BPerfect  = 3200 // Tetris perfect clear back to back
+	guidelineComboPoints = 50
+	guidelineSoftDropRow = 1
+	guidelineHardDropRow = 2
+)
+
+func (GuidelineScorer) Name() string { return "guideline" }
+func (GuidelineScorer) Description() string {
+	return "Modern guideline: T-spins, combos, back to back, drops"
+}
+
+func (GuidelineScorer) Drop(kind DropKind, rows int) int {
+	switch kind {
+	case SoftDrop:
+		return rows * guidelineSoftDropRow
+	case HardDrop:
+		return rows * guidelineHardDropRow
+	}
+	return 0
+}
+
+func (GuidelineScorer) Lock(l Lock) []Award {
+	var awards []Award
+	level := l.Level + 1
+	table := guidelineClears
+	switch l.TSpin {
+	case MiniTSpin:
+		table = guidelineMiniTSpins
+	case FullTSpin:
+		table = guidelineTSpins
+	}
+	base := 0
+	if l.Lines < len(table) {
+		base = table[l.Lines]
+	}
+	if base > 0 {
+		awards = append(awards, Award{clearName(l.Lines, l.TSpin), base * level, fmt.Sprintf("%d × level %d", base, level)})
+		if l.B2B {
+			awards = append(awards, Award{"Back to back", base * level / 2, fmt.Sprintf("half of %d", base*level)})
+		}
+	}
+	if l.Combo > 1 {
+		steps := l.Combo - 1
+		awards = append(awards, Award{fmt.Sprintf("Combo %d", steps), guidelineComboPoints * steps * level,
+			fmt.Sprintf("%d × %d × level %d", guidelineComboPoints, steps, level)})
+	}
+	if l.Perfect && l.Lines > 0 {
+		pc := guidelinePerfects[min(l.Lines, len(guidelinePerfects)-1)]
+		if l.Lines >= 4 && l.B2B {
+			pc = guidelineB2BPerfect
+		}
+		awards = append(awards, Award{"Perfect clear", pc * level, fmt.Sprintf("%d × level %d", pc, level)})
+	}
+	return awards
+}
+
+// --- NES Scoring --------------------------------------------------------------
+
+// NESScorer scores like the NES: 40, 100, 300 or 1200 times the level plus
+// one, and a point a row soft dropped. There are no T-spins, combos or hard
+// drops to reward.
+type NESScorer struct{}
+
+// Points per clear by lines, level 0.
+var nesClears = []int{0, 40, 100, 300, 1200}
+
+func (NESScorer) Name() string { return "nes" }
+func (NESScorer) Description() string {
+	return "Nintendo's 1989 Tetris: lines times the level, nothing else"
+}
+
+func (NESScorer) Drop(kind DropKind, rows int) int {
+	if kind == SoftDrop {
+		return rows
+	}
+	return 0
+}
+
+func (NESScorer) Lock(l Lock) []Award {
+	if l.Lines == 0 {
+		return nil
+	}
+	base := nesClears[min(l.Lines, len(nesClears)-1)]
+	return []Award{{clearName(l.Lines, NoTSpin), base * (l.Level + 1), fmt.Sprintf("%d × (level %d + 1)", base, l.Level)}}
+}
+
+// --- TGM Scoring --------------------------------------------------------------
+
+// TGMScorer scores like Tetris: The Grand Master: a quarter of the level
+// plus the lines, rounded up, plus the rows dropped, times the lines, times
+// the combo, times four for a perfect clear (a bravo). Drops only count
+// when the piece clears lines.
+type TGMScorer struct{}
+
+func (TGMScorer) Name() string { return "tgm" }
+func (TGMScorer) Description() string {
+	return "Tetris: The Grand Master: level, lines, drops and combo multiplied"
+}
+
+func (TGMScorer) Drop(DropKind, int) int { return 0 }
+
+func (TGMScorer) Lock(l Lock) []Award {
+	if l.Lines == 0 {
+		return nil
+	}
+	// The combo starts at 1 and grows by twice the lines, less two, each
+	// clear in a row
+	combo := 1 + 2*l.ComboLines - 2*l.Combo
+	drops := l.SoftRows + l.HardRows
+	pts := ((l.Level+l.Lines+3)/4 + drops) * l.Lines * combo
+	awards := []Award{{clearName(l.Lines, NoTSpin), pts,
+		fmt.Sprintf("(⌈(level %d + %d)/4⌉ + %d dropped) × %d lines × combo %d", l.Level, l.Lines, drops, l.Lines, combo)}}
+	if l.Perfect {
+		awards = append(awards, Award{"Bravo", 3 * pts, fmt.Sprintf("%d × 3 more", pts)})
+	}
+	return awards
+}
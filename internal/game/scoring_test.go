@@ -0,0 +1,193 @@
+package game
+
+import (
+	"slices"
+	"testing"
+)
+
+// scoringCase is a lock and the awards a scorer should give it.
+type scoringCase struct {
+	name string
+	lock Lock
+	want []Award
+}
+
+// checkScorer runs cases through s.
+func checkScorer(t *testing.T, s Scorer, cases []scoringCase) {
+	t.Helper()
+	for _, c := range cases {
+		if got := s.Lock(c.lock); !slices.Equal(got, c.want) {
+			t.Errorf("%s %s: got %v, want %v", s.Name(), c.name, got, c.want)
+		}
+	}
+}
+
+func TestGuidelineScorer(t *testing.T) {
+	checkScorer(t, GuidelineScorer{}, []scoringCase{
+		{"nothing", Lock{Level: 4}, nil},
+		{"single", Lock{Lines: 1, Combo: 1}, []Award{{"Single", 100, "100 × level 1"}}},
+		{"tetris", Lock{Lines: 4, Level: 2, Combo: 1}, []Award{{"Tetris", 2400, "800 × level 3"}}},
+		{"T-spin mini", Lock{TSpin: MiniTSpin}, []Award{{"T-spin mini", 100, "100 × level 1"}}},
+		{"T-spin", Lock{TSpin: FullTSpin, Level: 1}, []Award{{"T-spin", 800, "400 × level 2"}}},
+		{"T-spin mini single", Lock{Lines: 1, TSpin: MiniTSpin, Combo: 1}, []Award{{"T-spin mini single", 200, "200 × level 1"}}},
+		{"T-spin double", Lock{Lines: 2, TSpin: FullTSpin, Level: 2, Combo: 1}, []Award{{"T-spin double", 3600, "1200 × level 3"}}},
+		{"T-spin triple", Lock{Lines: 3, TSpin: FullTSpin, Combo: 1}, []Award{{"T-spin triple", 1600, "1600 × level 1"}}},
+
+		// Back to back is half again the clear, and the combo pays on top
+		// of both
+		{"back to back T-spin mini single", Lock{Lines: 1, TSpin: MiniTSpin, Combo: 1, B2B: true}, []Award{
+			{"T-spin mini single", 200, "200 × level 1"},
+			{"Back to back", 100, "half of 200"},
+		}},
+		{"combo", Lock{Lines: 1, Level: 2, Combo: 4}, []Award{
+			{"Single", 300, "100 × level 3"},
+			{"Combo 3", 450, "50 × 3 × level 3"},
+		}},
+		{"back to back tetris in a combo", Lock{Lines: 4, Level: 1, Combo: 3, B2B: true}, []Award{
+			{"Tetris", 1600, "800 × level 2"},
+			{"Back to back", 800, "half of 1600"},
+			{"Combo 2", 200, "50 × 2 × level 2"},
+		}},
+
+		{"perfect double", Lock{Lines: 2, Combo: 1, Perfect: true}, []Award{
+			{"Double", 300, "300 × level 1"},
+			{"Perfect clear", 1200, "1200 × level 1"},
+		}},
+		{"perfect tetris", Lock{Lines: 4, Level: 1, Combo: 1, Perfect: true}, []Award{
+			{"Tetris", 1600, "800 × level 2"},
+			{"Perfect clear", 4000, "2000 × level 2"},
+		}},
+		{"back to back perfect tetris", Lock{Lines: 4, Combo: 1, B2B: true, Perfect: true}, []Award{
+			{"Tetris", 800, "800 × level 1"},
+			{"Back to back", 400, "half of 800"},
+			{"Perfect clear", 3200, "3200 × level 1"},
+		}},
+	})
+
+	s := GuidelineScorer{}
+	for _, c := range []struct {
+		kind       DropKind
+		rows, want int
+	}{{SoftDrop, 5, 5}, {HardDrop, 10, 20}, {GravityDrop, 3, 0}} {
+		if got := s.Drop(c.kind, c.rows); got != c.want {
+			t.Errorf("guideline drop %v of %d rows: %d points, want %d", c.kind, c.rows, got, c.want)
+		}
+	}
+}
+
+func TestNESScorer(t *testing.T) {
+	checkScorer(t, NESScorer{}, []scoringCase{
+		{"nothing", Lock{Level: 9}, nil},
+		{"single", Lock{Lines: 1, Combo: 1}, []Award{{"Single", 40, "40 × (level 0 + 1)"}}},
+		{"double", Lock{Lines: 2, Level: 2, Combo: 1}, []Award{{"Double", 300, "100 × (level 2 + 1)"}}},
+		{"triple", Lock{Lines: 3, Level: 5, Combo: 1}, []Award{{"Triple", 1800, "300 × (level 5 + 1)"}}},
+		{"tetris", Lock{Lines: 4, Level: 9, Combo: 1}, []Award{{"Tetris", 12000, "1200 × (level 9 + 1)"}}},
+
+		// Spins, chains and perfect clears are worth nothing extra
+		{"T-spin", Lock{TSpin: FullTSpin}, nil},
+		{"T-spin double", Lock{Lines: 2, TSpin: FullTSpin, Combo: 1}, []Award{{"Double", 100, "100 × (level 0 + 1)"}}},
+		{"everything", Lock{Lines: 4, Combo: 3, B2B: true, Perfect: true}, []Award{{"Tetris", 1200, "1200 × (level 0 + 1)"}}},
+	})
+
+	s := NESScorer{}
+	if got := s.Drop(SoftDrop, 7); got != 7 {
+		t.Errorf("nes soft drop of 7 rows: %d points, want 7", got)
+	}
+	if got := s.Drop(HardDrop, 10); got != 0 {
+		t.Errorf("nes hard drop of 10 rows: %d points, want 0", got)
+	}
+}
+
+func TestTGMScorer(t *testing.T) {
+	checkScorer(t, TGMScorer{}, []scoringCase{
+		{"nothing", Lock{Level: 50, SoftRows: 10}, nil},
+		{"single", Lock{Lines: 1, Combo: 1, ComboLines: 1}, []Award{
+			{"Single", 1, "(⌈(level 0 + 1)/4⌉ + 0 dropped) × 1 lines × combo 1"},
+		}},
+		{"dropped tetris", Lock{Lines: 4, Level: 100, Combo: 1, ComboLines: 4, SoftRows: 5, HardRows: 10}, []Award{
+			{"Tetris", 1148, "(⌈(level 100 + 4)/4⌉ + 15 dropped) × 4 lines × combo 7"},
+		}},
+
+		// A double after a single is the second step of a combo
+		{"combo", Lock{Lines: 2, Level: 10, Combo: 2, ComboLines: 3}, []Award{
+			{"Double", 18, "(⌈(level 10 + 2)/4⌉ + 0 dropped) × 2 lines × combo 3"},
+		}},
+		{"bravo", Lock{Lines: 1, Combo: 1, ComboLines: 1, Perfect: true}, []Award{
+			{"Single", 1, "(⌈(level 0 + 1)/4⌉ + 0 dropped) × 1 lines × combo 1"},
+			{"Bravo", 3, "1 × 3 more"},
+		}},
+	})
+
+	for _, kind := range []DropKind{SoftDrop, HardDrop} {
+		if got := (TGMScorer{}).Drop(kind, 10); got != 0 {
+			t.Errorf("tgm drop %v: %d points, want 0", kind, got)
+		}
+	}
+}
+
+func TestScoringChains(t *testing.T) {
+	g := NewGame(nil, nil)
+	g.SetMode(Marathon)
+	g.SetSeed(1)
+	g.StartGame()
+	g.Level = 1
+	g.Playfield[0][0] = int(Garbage) // Nothing clears the board
+	var scored [][]Award
+	g.Subscribe(func(e Event) {
+		if ev, ok := e.(ScoredEvent); ok {
+			scored = append(scored, ev.Awards)
+		}
+	})
+
+	// Difficult clears keep back to back going through locks that clear
+	// nothing, which only break the combo; an easy clear breaks it
+	steps := []struct {
+		lines int
+		spin  TSpin
+		b2b   bool
+		combo int
+		want  []Award
+	}{
+		{4, NoTSpin, false, 1, []Award{{"Tetris", 800, "800 × level 1"}}},
+		{2, FullTSpin, true, 2, []Award{
+			{"T-spin double", 1200, "1200 × level 1"},
+			{"Back to back", 600, "half of 1200"},
+			{"Combo 1", 50, "50 × 1 × level 1"},
+		}},
+		{0, NoTSpin, false, 0, nil},
+		{4, NoTSpin, true, 1, []Award{
+			{"Tetris", 800, "800 × level 1"},
+			{"Back to back", 400, "half of 800"},
+		}},
+		{1, NoTSpin, false, 2, []Award{
+			{"Single", 100, "100 × level 1"},
+			{"Combo 1", 50, "50 × 1 × level 1"},
+		}},
+		{4, NoTSpin, false, 3, []Award{
+			{"Tetris", 800, "800 × level 1"},
+			{"Combo 2", 100, "50 × 2 × level 1"},
+		}},
+	}
+	for i, s := range steps {
+		scored = nil
+		score := g.Score
+		l := g.updateScore(s.lines, s.spin)
+		if l.B2B != s.b2b || l.Combo != s.combo {
+			t.Errorf("lock %d: back to back %v combo %d, want %v and %d", i+1, l.B2B, l.Combo, s.b2b, s.combo)
+		}
+		var got []Award
+		if len(scored) > 0 {
+			got = scored[0]
+		}
+		if !slices.Equal(got, s.want) {
+			t.Errorf("lock %d: awards %v, want %v", i+1, got, s.want)
+		}
+		total := 0
+		for _, a := range s.want {
+			total += a.Points
+		}
+		if g.Score-score != total {
+			t.Errorf("lock %d: scored %d, want %d", i+1, g.Score-score, total)
+		}
+	}
+}
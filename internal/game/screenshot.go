@@ -0,0 +1,34 @@
+package game
+
+// --- Screenshots --------------------------------------------------------------
+
+// Camera takes pictures of the game for the S key. Save is called on the
+// game's goroutine and returns where the picture went.
+type Camera interface {
+	Save(s *Snapshot) (string, error)
+}
+
+// SetCamera lets the player take screenshots with c.
+func (g *Game) SetCamera(c Camera) {
+	g.camera = c
+}
+
+// Capture takes a snapshot of a game without a screen, which publishes
+// none. Only the goroutine that runs the game may call it.
+func (g *Game) Capture() *Snapshot {
+	return g.snapshot()
+}
+
+// screenshot saves a picture of the position on screen.
+func (g *Game) screenshot() {
+	if g.camera == nil {
+		g.setNotice("Screenshots are off")
+		return
+	}
+	path, err := g.camera.Save(g.snapshot())
+	if err != nil {
+		g.setNotice("Screenshot failed: " + err.Error())
+		return
+	}
+	g.setNotice("Saved " + path)
+}
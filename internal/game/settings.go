@@ -0,0 +1,219 @@
+package game
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// --- Settings -----------------------------------------------------------------
+//
+// The Settings page changes how the game is controlled, heard and shown. The
+// settings are kept in a file of their own so they carry over to the next
+// launch. Handling only changes what a key press does, never the rules, so
+// replays play back the same whatever the settings.
+
+// Settings are the player's preferences.
+type Settings struct {
+	Controls Controls `json:"controls"`
+	Audio    Audio    `json:"audio"`
+	Visuals  Visuals  `json:"visuals"`
+	Access   Access   `json:"accessibility"`
+	Handling Handling `json:"handling"`
+	Versus   Match    `json:"versus"`
+}
+
+// Controls are the keys and the mouse.
+type Controls struct {
+	UpHardDrops bool `json:"up_hard_drops"` // ↑ hard drops instead of rotating
+	Mouse       bool `json:"mouse"`         // Clicks work: menus, the pause button
+	ClickToDrop bool `json:"click_to_drop"` // A click on a column drops the piece there
+}
+
+// Audio is what the game plays.
+type Audio struct {
+	Music bool `json:"music"`
+}
+
+// Visuals are extras on the board.
+type Visuals struct {
+	Ghost    bool   `json:"ghost"`    // Outline where the piece would land
+	Palette  string `json:"palette"`  // Name of the piece colors, see Palettes
+	Patterns bool   `json:"patterns"` // Each piece drawn with its own glyphs
+}
+
+// Access helps players who cannot see the board well.
+type Access struct {
+	Announce bool `json:"announce"` // Describe events on a line under the board
+	Cues     bool `json:"cues"`     // Tones panned to the piece's column
+}
+
+// Handling is how pieces respond to the keys.
+type Handling struct {
+	SoftDrop    int  `json:"soft_drop"`    // Rows per press of ↓, 0 for all the way down
+	BufferMoves bool `json:"buffer_moves"` // Keys pressed between pieces apply to the next one
+}
+
+// Match is how versus matches against the bot are played.
+type Match struct {
+	Difficulty string `json:"difficulty"` // Name of the bot's difficulty, see Difficulties
+	BestOf     int    `json:"best_of"`    // Rounds in a match, odd
+}
+
+// DefaultSettings are the settings before the player changes any.
+var DefaultSettings = Settings{
+	Controls: Controls{Mouse: true},
+	Audio:    Audio{Music: true},
+	Visuals:  Visuals{Ghost: true},
+	Handling: Handling{SoftDrop: 1, BufferMoves: true},
+	Versus:   Match{Difficulty: "normal", BestOf: 3},
+}
+
+// LoadSettings reads the settings kept in path, the defaults if there are
+// none yet.
+func LoadSettings(path string) (Settings, error) {
+	s := DefaultSettings
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return s, err
+	}
+	err = json.Unmarshal(data, &s)
+	return s, err
+}
+
+// SetSettings applies s. Call it before Run.
+func (g *Game) SetSettings(s Settings) {
+	g.settings = s
+}
+
+// SetSettingsFile keeps the settings changed on the Settings page in path.
+// An empty path keeps them for this run only.
+func (g *Game) SetSettingsFile(path string) {
+	g.settingsPath = path
+}
+
+// Settings returns the settings in effect. Like everything that reads the
+// game it must run on the game's goroutine, e.g. in a subscriber.
+func (g *Game) Settings() Settings {
+	return g.settings
+}
+
+// changeSettings applies settings changed on the Settings page and keeps
+// them for next time.
+func (g *Game) changeSettings(s Settings) {
+	g.settings = s
+	g.emit(SettingsChangedEvent{Settings: s})
+	if err := g.writeSettings(); err != nil {
+		g.setNotice("Settings not saved: " + err.Error())
+	}
+}
+
+// writeSettings writes the settings to the settings file, if there is one.
+func (g *Game) writeSettings() error {
+	if g.settingsPath == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(g.settings, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(g.settingsPath), 0o755); err != nil {
+		return err
+	}
+	tmp := g.settingsPath + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, g.settingsPath)
+}
+
+// softDropChoices are the Soft drop options, by rows per press.
+var softDropChoices = []struct {
+	label string
+	rows  int
+}{{"1 row", 1}, {"2 rows", 2}, {"5 rows", 5}, {"To the bottom", 0}}
+
+// addSettings adds the Settings page: one form, in sections.
+func (ui *screens) addSettings() {
+	g := ui.g
+	s := &ui.settings
+	ready := false // Building the drop-downs reports their first choice
+	changed := func() {
+		if !ready {
+			return
+		}
+		s := *s
+		g.app.EnableMouse(s.Controls.Mouse)
+		g.request(func() { g.changeSettings(s) })
+	}
+
+	form := tview.NewForm().SetItemPadding(0)
+	section := func(name string) {
+		form.AddTextView("[yellow]"+name, "", 0, 1, true, false)
+	}
+
+	section("Controls")
+	up := 0
+	if s.Controls.UpHardDrops {
+		up = 1
+	}
+	form.AddDropDown("Up arrow", []string{"Rotate", "Hard drop"}, up, func(_ string, i int) {
+		s.Controls.UpHardDrops = i == 1
+		changed()
+	})
+	form.AddCheckbox("Mouse", s.Controls.Mouse, func(on bool) { s.Controls.Mouse = on; changed() })
+	form.AddCheckbox("Click to drop", s.Controls.ClickToDrop, func(on bool) { s.Controls.ClickToDrop = on; changed() })
+
+	section("Audio")
+	form.AddCheckbox("Music", s.Audio.Music, func(on bool) { s.Audio.Music = on; changed() })
+
+	section("Visuals")
+	form.AddCheckbox("Ghost piece", s.Visuals.Ghost, func(on bool) { s.Visuals.Ghost = on; changed() })
+	var palettes []string
+	palette := 0
+	for i, p := range Palettes {
+		palettes = append(palettes, p.Name)
+		if strings.EqualFold(p.Name, s.Visuals.Palette) {
+			palette = i
+		}
+	}
+	form.AddDropDown("Colors", palettes, palette, func(_ string, i int) {
+		s.Visuals.Palette = Palettes[i].Name
+		changed()
+	})
+	form.AddCheckbox("Piece patterns", s.Visuals.Patterns, func(on bool) { s.Visuals.Patterns = on; changed() })
+
+	section("Accessibility")
+	form.AddCheckbox("Announce events", s.Access.Announce, func(on bool) { s.Access.Announce = on; changed() })
+	form.AddCheckbox("Position cues", s.Access.Cues, func(on bool) { s.Access.Cues = on; changed() })
+
+	section("Handling")
+	var labels []string
+	soft := 0
+	for i, c := range softDropChoices {
+		labels = append(labels, c.label)
+		if c.rows == s.Handling.SoftDrop {
+			soft = i
+		}
+	}
+	form.AddDropDown("Soft drop", labels, soft, func(_ string, i int) {
+		s.Handling.SoftDrop = softDropChoices[i].rows
+		changed()
+	})
+	form.AddCheckbox("Keep keys between pieces", s.Handling.BufferMoves, func(on bool) { s.Handling.BufferMoves = on; changed() })
+
+	form.AddButton("Back", ui.back)
+	form.SetCancelFunc(ui.back)
+	form.SetFieldBackgroundColor(tcell.ColorNavy).SetButtonBackgroundColor(tcell.ColorNavy)
+	ready = true
+	ui.add(pageSettings, "SETTINGS", form, form, "TAB move • ENTER/SPACE change • ESC back")
+}
@@ -0,0 +1,234 @@
+package game
+
+import (
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// --- Snapshots ----------------------------------------------------------------
+//
+// The loop owns the game; tview draws on a goroutine of its own. After every
+// step that changes what is on screen the loop publishes a Snapshot, a copy
+// that shares no memory with the game, and the primitives draw from the last
+// one published. Nothing on the drawing side ever reads the live game.
+
+// Snapshot is everything the screen shows at one moment.
+type Snapshot struct {
+	State     GameState
+	Phase     Phase
+	Frame     int // Loop ticks when the snapshot was taken
+	Playfield Field
+	LockedAt  Field      // Play frame each block locked at
+	Stack     Stack      // How long locked blocks stay on screen
+	Current   *Placement // Piece in play, nil between pieces
+	Ghost     *Placement // Where it would land, nil with the ghost turned off
+	Next      []PieceID
+	Hold      PieceID
+	HoldUsed  bool // The held piece cannot be swapped back in yet
+
+	Score   int
+	Level   int
+	Lines   int
+	Grade   string         // Master mode grade, "" in other modes
+	Section int            // Master mode level the current section ends at
+	Garbage *GarbageStatus // Nil in modes without garbage
+	Battle  *BattleStatus  // Nil outside a versus match
+	Mode    string
+	Elapsed time.Duration
+	Played  int // Frames of play, the clock LockedAt counts in
+	Stats   Stats
+
+	Clearing    []int // Rows waiting out the line clear delay
+	ClearingLit bool  // Whether they are lit in this frame of the flash
+	Notice      string
+	Scored      string // What the last lock earned, e.g. "+800 Tetris", for a moment
+
+	Continue string // Mode of the saved game the menu offers to continue, if any
+
+	Puzzle *PuzzleStatus // Puzzle being played, nil outside puzzles
+	Hints  HintStatus
+	Mouse  bool // Clicks work: menus, the pause button and the board
+	UpDrop bool // ↑ hard drops instead of rotating
+
+	Palette  *Palette // Piece colors, the standard ones if nil
+	Patterns bool     // Pieces are drawn with their glyphs from PieceGlyphs
+
+	Announce     bool   // Announcements are shown under the board
+	Announcement string // The latest one
+
+	Player string // Who is playing, set when watching someone else's game
+}
+
+// Source is anything the primitives can draw: a game, or a game watched
+// from elsewhere.
+type Source interface {
+	// Snapshot returns what to show, or nil while there is nothing yet.
+	Snapshot() *Snapshot
+}
+
+// Spectators are told about every snapshot the game publishes, so others
+// can watch. Show is called on the game's goroutine and must not block.
+type Spectators interface {
+	Show(s *Snapshot)
+}
+
+// PuzzleInfo is what a puzzle asks for.
+type PuzzleInfo struct {
+	Name        string
+	Goal        Goal
+	Description string
+}
+
+// PuzzleStatus is how the puzzle being played stands.
+type PuzzleStatus struct {
+	PuzzleInfo
+	PiecesLeft int // -1 without a limit
+	Solved     bool
+	Reason     string // Why it ended, once it has
+}
+
+// HintStatus is the practice hint readout.
+type HintStatus struct {
+	Available bool
+	Enabled   bool
+	Hint      *Hint // Nil while off or while the bot is thinking
+}
+
+// Snapshot returns the last published snapshot, or nil before the first.
+// It is safe to call from any goroutine.
+func (g *Game) Snapshot() *Snapshot {
+	return g.view.Load()
+}
+
+// publish takes a snapshot of the game for the screen. Only the goroutine
+// that runs the game may call it.
+func (g *Game) publish() {
+	s := g.snapshot()
+	g.view.Store(s)
+	if g.spectators != nil {
+		g.spectators.Show(s)
+	}
+}
+
+// SetSpectators streams the game to s as it is played. Call it before Run.
+func (g *Game) SetSpectators(s Spectators) {
+	g.spectators = s
+}
+
+// snapshot copies out everything the screen shows.
+func (g *Game) snapshot() *Snapshot {
+	s := &Snapshot{
+		State:     g.State,
+		Phase:     g.Phase,
+		Frame:     g.frame,
+		Playfield: g.Playfield,
+		LockedAt:  g.lockedAt,
+		Stack:     g.Mode.Stack,
+		Next:      append([]PieceID(nil), g.NextQueue...),
+		Hold:      g.Hold,
+		HoldUsed:  g.holdUsed,
+		Score:     g.Score,
+		Level:     g.Level,
+		Lines:     g.LinesCleared,
+		Mode:      g.Mode.Name,
+		Elapsed:   g.Elapsed(),
+		Played:    g.playFrames,
+		Stats:     g.Stats,
+		Grade:     g.Grade(),
+		Garbage:   g.garbageStatus(),
+		Battle:    g.battleStatus(),
+		Mouse:     g.settings.Controls.Mouse,
+		Patterns:  g.settings.Visuals.Patterns,
+		Announce:  g.settings.Access.Announce,
+	}
+	if s.Announce {
+		s.Announcement = g.announcement
+	}
+	if p, err := PaletteByName(g.settings.Visuals.Palette); err == nil {
+		s.Palette = &p
+	}
+	if g.saved != nil {
+		s.Continue = g.saved.Mode
+	}
+	if g.master != nil {
+		s.Section = min(masterStop(g.Level)+1, masterMaxLevel)
+	}
+	if g.Current != nil {
+		p := g.Current.placement()
+		s.Current = &p
+		if g.settings.Visuals.Ghost {
+			ghost := Landing(&g.Playfield, p)
+			s.Ghost = &ghost
+		}
+	}
+
+	rows, lit := g.ClearingRows()
+	s.Clearing, s.ClearingLit = append([]int(nil), rows...), lit
+	if g.notice != "" && g.frame < g.noticeUntil {
+		s.Notice = g.notice
+	}
+	if g.frame < g.scoredUntil {
+		s.Scored = g.scored
+	}
+
+	if run := g.puzzle; run != nil {
+		s.Puzzle = &PuzzleStatus{
+			PuzzleInfo: run.info(),
+			PiecesLeft: g.piecesLeft(),
+			Solved:     run.solved,
+			Reason:     run.reason,
+		}
+	}
+
+	if g.HintsAvailable() {
+		s.Hints = HintStatus{Available: true, Enabled: g.hints.enabled}
+		if hint, ok := g.CurrentHint(); ok {
+			s.Hints.Hint = &hint
+		}
+	}
+	return s
+}
+
+// fadeFrames is how long a block takes to fade out (1 second).
+const fadeFrames = 60
+
+// lockFlashFrames is how long a piece that just locked is outlined on a
+// hidden stack.
+const lockFlashFrames = 12
+
+// CellFade is how far the locked block at x, y has faded from view, from 0
+// (fully shown) to 1 (gone). Everything shows again once the game is over.
+func (s *Snapshot) CellFade(x, y int) float64 {
+	if s.Playfield[x][y] == 0 || s.State == GameOver {
+		return 0
+	}
+	switch {
+	case s.Stack.Invisible:
+		return 1
+	case s.Stack.FadeAfter > 0:
+		age := s.Played - s.LockedAt[x][y] - int(s.Stack.FadeAfter/TickRate)
+		return min(1, max(0, float64(age)/fadeFrames))
+	}
+	return 0
+}
+
+// JustLocked reports whether the block at x, y locked a moment ago on a
+// stack that hides its blocks, which outlines it briefly.
+func (s *Snapshot) JustLocked(x, y int) bool {
+	return s.Stack.Hidden() && s.Playfield[x][y] != 0 && s.State != GameOver &&
+		s.Played-s.LockedAt[x][y] < lockFlashFrames
+}
+
+// PieceColor is the color piece id is drawn in.
+func (s *Snapshot) PieceColor(id PieceID) tcell.Color {
+	if s.Palette == nil {
+		return StandardPalette.Color(id)
+	}
+	return s.Palette.Color(id)
+}
+
+// info is what the puzzle asks for.
+func (p *Puzzle) info() PuzzleInfo {
+	return PuzzleInfo{Name: p.Name, Goal: p.Goal, Description: p.Description}
+}
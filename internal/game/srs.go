@@ -0,0 +1,130 @@
+package game
+
+// --- Wall Kicks ---------------------------------------------------------------
+
+// Offsets tried in order when a piece rotates, indexed by the state it
+// rotates from and the direction (0 clockwise, 1 counter-clockwise). These
+// are the Super Rotation System tables with y pointing up; the first test is
+// always in place. O never needs to kick.
+var (
+	jlstzKicks = [4][2][5]Point{
+		{{{0, 0}, {-1, 0}, {-1, 1}, {0, -2}, {-1, -2}}, {{0, 0}, {1, 0}, {1, 1}, {0, -2}, {1, -2}}},  // 0→R, 0→L
+		{{{0, 0}, {1, 0}, {1, -1}, {0, 2}, {1, 2}}, {{0, 0}, {1, 0}, {1, -1}, {0, 2}, {1, 2}}},       // R→2, R→0
+		{{{0, 0}, {1, 0}, {1, 1}, {0, -2}, {1, -2}}, {{0, 0}, {-1, 0}, {-1, 1}, {0, -2}, {-1, -2}}},  // 2→L, 2→R
+		{{{0, 0}, {-1, 0}, {-1, -1}, {0, 2}, {-1, 2}}, {{0, 0}, {-1, 0}, {-1, -1}, {0, 2}, {-1, 2}}}, // L→0, L→2
+	}
+	iKicks = [4][2][5]Point{
+		{{{0, 0}, {-2, 0}, {1, 0}, {-2, -1}, {1, 2}}, {{0, 0}, {-1, 0}, {2, 0}, {-1, 2}, {2, -1}}}, // 0→R, 0→L
+		{{{0, 0}, {-1, 0}, {2, 0}, {-1, 2}, {2, -1}}, {{0, 0}, {2, 0}, {-1, 0}, {2, 1}, {-1, -2}}}, // R→2, R→0
+		{{{0, 0}, {2, 0}, {-1, 0}, {2, 1}, {-1, -2}}, {{0, 0}, {1, 0}, {-2, 0}, {1, -2}, {-2, 1}}}, // 2→L, 2→R
+		{{{0, 0}, {1, 0}, {-2, 0}, {1, -2}, {-2, 1}}, {{0, 0}, {-2, 0}, {1, 0}, {-2, -1}, {1, 2}}}, // L→0, L→2
+	}
+)
+
+// rotatePlacement turns p one step clockwise (dir 1) or counter-clockwise
+// (dir -1) and tries the wall kicks until one fits. kick is the index of the
+// test that worked.
+func rotatePlacement(f *Field, p Placement, dir int) (next Placement, kick int, ok bool) {
+	turn := 0
+	if dir < 0 {
+		turn = 1
+	}
+	next = p
+	next.Rotation = (p.Rotation + dir + 4) % 4
+
+	tests := jlstzKicks[p.Rotation][turn][:]
+	switch p.Piece {
+	case I:
+		tests = iKicks[p.Rotation][turn][:]
+	case O:
+		tests = tests[:1]
+	}
+	for i, t := range tests {
+		next.Position = Point{X: p.Position.X + t.X, Y: p.Position.Y + t.Y}
+		if Fits(f, next) {
+			return next, i, true
+		}
+	}
+	return p, 0, false
+}
+
+// --- Guideline Coordinates ----------------------------------------------------
+//
+// Community tools (TBP bots, fumen) describe a piece by its letter, its
+// orientation and the cell it rotates around. Our shape matrices are anchored
+// at the corner of their box instead, so these helpers translate between the
+// two through the cells a piece covers.
+
+// Orientations in guideline order, turning clockwise from spawn.
+const (
+	North = iota
+	East
+	South
+	West
+)
+
+// srsMinos are the cells of each piece facing north, relative to its
+// rotation center, with y pointing up.
+var srsMinos = map[PieceID][4]Point{
+	I: {{-1, 0}, {0, 0}, {1, 0}, {2, 0}},
+	O: {{0, 0}, {1, 0}, {0, 1}, {1, 1}},
+	T: {{-1, 0}, {0, 0}, {1, 0}, {0, 1}},
+	J: {{-1, 0}, {0, 0}, {1, 0}, {-1, 1}},
+	L: {{-1, 0}, {0, 0}, {1, 0}, {1, 1}},
+	S: {{-1, 0}, {0, 0}, {0, 1}, {1, 1}},
+	Z: {{-1, 1}, {0, 1}, {0, 0}, {1, 0}},
+}
+
+// SRSCells returns the playfield cells of a piece given in guideline terms.
+func SRSCells(id PieceID, orientation int, center Point) [4]Point {
+	var cells [4]Point
+	for i, m := range srsMinos[id] {
+		// Rotate clockwise orientation times: (x, y) -> (y, -x)
+		for r := 0; r < orientation%4; r++ {
+			m = Point{X: m.Y, Y: -m.X}
+		}
+		cells[i] = Point{X: center.X + m.X, Y: center.Y + m.Y}
+	}
+	return cells
+}
+
+// FromSRS converts a guideline location into one of our placements.
+func FromSRS(id PieceID, orientation int, center Point) (Placement, bool) {
+	return PlacementForCells(id, SRSCells(id, orientation, center))
+}
+
+// ToSRS converts a placement into a guideline orientation and center. Pieces
+// that look the same in several orientations report the first match.
+func ToSRS(p Placement) (orientation int, center Point, ok bool) {
+	want := cellsKey(p.Cells())
+	first := p.Cells()[0]
+	for o := North; o <= West; o++ {
+		// Try each mino as the one covering our first cell
+		for _, m := range SRSCells(p.Piece, o, Point{}) {
+			center := Point{X: first.X - m.X, Y: first.Y - m.Y}
+			if cellsKey(SRSCells(p.Piece, o, center)) == want {
+				return o, center, true
+			}
+		}
+	}
+	return 0, Point{}, false
+}
+
+// PieceLetter is the usual one-letter name of a piece, used by file formats
+// and protocols.
+func PieceLetter(id PieceID) string {
+	if id >= I && id <= Z {
+		return string("?IOTJLSZ"[id])
+	}
+	return ""
+}
+
+// PieceFromLetter parses a one-letter piece name.
+func PieceFromLetter(letter string) (PieceID, bool) {
+	for id := I; id <= Z; id++ {
+		if PieceLetter(id) == letter {
+			return id, true
+		}
+	}
+	return 0, false
+}
@@ -1,66 +1,70 @@
 package game
 
-import (
-	"math/rand/v2"
-	"time"
-)
+import "math/rand/v2"
 
 // refillBag shuffles all 7 pieces.
 // Updated to use math/rand/v2 because apparently rand.Seed() is so 2023
 func (g *Game) refillBag() {
+	if g.rng == nil {
+		g.SetSeed(rand.Uint64())
+	}
 	bag := []PieceID{I, O, T, J, L, S, Z}
-	rand.Shuffle(len(bag), func(i, j int) {
+	g.rng.Shuffle(len(bag), func(i, j int) {
 		bag[i], bag[j] = bag[j], bag[i]
 	})
 	g.NextQueue = append(g.NextQueue, bag...)
 }
 
+// SetSeed restarts the randomizer so the piece sequence can be reproduced.
+func (g *Game) SetSeed(seed uint64) {
+	g.seed = seed
+	g.pcg = rand.NewPCG(seed, seed^0x9e3779b97f4a7c15)
+	g.rng = rand.New(g.pcg)
+}
+
+// Seed returns the seed of the current piece sequence.
+func (g *Game) Seed() uint64 {
+	return g.seed
+}
+
 // spawnNext creates Current from NextQueue.
 func (g *Game) spawnNext() {
-	// Ensure we have at least 2 pieces in the queue (current + next preview)
-	if len(g.NextQueue) < 2 {
+	// Ensure we have at least 2 pieces in the queue (current + next preview),
+	// unless a puzzle hands out a fixed set
+	if len(g.NextQueue) < 2 && !g.fixedQueue() {
 		g.refillBag()
 	}
+	if len(g.NextQueue) == 0 {
+		g.Current = nil
+		g.endGame(ReasonOutOfPieces)
+		return
+	}
 
 	// Get the next piece ID and update the queue
 	pid := g.NextQueue[0]
 	g.NextQueue = g.NextQueue[1:]
 
 	// Ensure we still have pieces for the next preview after removing current piece
-	if len(g.NextQueue) < 1 {
+	if len(g.NextQueue) < 1 && !g.fixedQueue() {
 		g.refillBag()
 	}
 
-	// Build blocks from shape[0] (initial rotation state)
-	var blocks [4]Point
-	idx := 0
-	for y := 0; y < 4; y++ {
-		for x := 0; x < 4; x++ {
-			if PieceShapes[pid][0][y][x] {
-				blocks[idx] = Point{X: x, Y: y}
-				idx++
-			}
-		}
-	}
-
-	// Determine spawn position
-	// Center horizontally in the playfield
-	spawnX := PlayWidth/2 - 2
-	if spawnX < 0 {
-		spawnX = 0
-	}
+	g.spawnPiece(pid)
+}
 
-	// Spawn at the top of the visible playfield, but not outside bounds
-	// Leave some space from the very top to avoid immediate collision
-	spawnY := VisibleHeight + 2 // Start in hidden buffer area (Y=22)
+// spawnPiece puts a new piece of type pid at the spawn point.
+func (g *Game) spawnPiece(pid PieceID) {
+	// Build blocks from shape[0] (initial rotation state)
+	blocks := ShapeBlocks(pid, 0)
 
 	// Create the new piece
 	g.Current = &Piece{
 		ID:            pid,
 		RotationState: 0,
 		Color:         PieceColors[pid],
-		Position:      Point{X: spawnX, Y: spawnY},
+		Position:      SpawnPoint(),
 		Blocks:        blocks,
+		spawnedAt:     g.frame,
 	}
 
 	// Check if the spawn position is valid
@@ -75,7 +79,7 @@ func (g *Game) spawnNext() {
 
 		// If still colliding after attempts, trigger game over
 		if g.checkCollision() {
-			g.State = GameOver
+			g.endGame(ReasonBlockOut)
 			return
 		}
 	}
@@ -84,36 +88,77 @@ func (g *Game) spawnNext() {
 	// If it can't move down immediately (resting on locked pieces),
 	// it will be locked on the next gravity tick, which is correct behavior
 
-	// Reset rotation tracking for the new piece
+	// Reset rotation tracking and hold for the new piece
 	g.LastMoveWasRotation = false
+	g.holdUsed = false
+	g.lockFrames, g.lowestY = 0, g.Current.Position.Y
+	g.drops = dropTally{}
+	g.emit(PieceSpawnedEvent{Piece: pid, Position: g.Current.Position})
+}
+
+// endGame switches to GameOver and tells subscribers why the game ended.
+func (g *Game) endGame(reason string) {
+	if g.State == GameOver {
+		return
+	}
+	g.State = GameOver
+	mode := g.Mode.Name
+	if g.puzzle != nil {
+		mode = "puzzle"
+	}
+	g.emit(GameOverEvent{
+		Mode: mode, Score: g.Score, Lines: g.LinesCleared, Level: g.Level,
+		Grade: g.Grade(), Elapsed: g.Elapsed(), Reason: reason,
+	})
 }
 
 // Call this when transitioning into Playing state.
 func (g *Game) StartGame() {
 	// Reset the playfield
 	g.Playfield = [PlayWidth][TotalHeight]int{}
+	g.lockedAt = Field{}
+	g.Hold, g.holdUsed = 0, false
 
 	// Reset game state
 	g.Score, g.Level, g.LinesCleared = 0, 1, 0
+	g.master = nil
+	if g.Mode.Master && g.puzzle == nil {
+		// Master counts levels from 0
+		g.master, g.Level = &masterRun{}, 0
+	}
+	g.Combo, g.comboLines, g.B2B = 0, 0, false
+	g.playFrames, g.gravityProgress = 0, 0
+	g.setPhase(PhaseFalling, 0)
+	g.clearing, g.clearTSpin, g.buffered = nil, NoTSpin, nil
+	g.scored = ""
+	g.Stats = Stats{}
 	g.Current = nil // Clear any existing piece
 
 	// Reset the piece queue and ensure we have enough pieces
 	g.NextQueue = g.NextQueue[:0]
-	g.refillBag()
-	// Ensure we have enough pieces for current + next preview
-	if len(g.NextQueue) < 2 {
+	if g.puzzle != nil {
+		// Puzzles bring their own board and pieces
+		run := g.puzzle
+		run.clear, run.solved, run.reason = nil, false, ""
+		g.Playfield = run.Playfield
+		g.Hold = run.Hold
+		g.NextQueue = append(g.NextQueue, run.Queue...)
+	}
+	g.history, g.startField = g.history[:0], g.Playfield
+	g.startReplay()
+	g.startBattle()
+	g.startGarbage()
+	if !g.fixedQueue() {
 		g.refillBag()
+		// Ensure we have enough pieces for current + next preview
+		if len(g.NextQueue) < 2 {
+			g.refillBag()
+		}
 	}
 
 	// Set state to Playing first
 	g.State = Playing
 
-	// Reset and restart gravity timer for the new level
-	if g.gravityTicker != nil {
-		g.gravityTicker.Stop()
-	}
-	g.gravityTicker = time.NewTicker(gravityForLevel(g.Level))
-
 	// Now spawn the first piece
 	g.spawnNext()
 }
@@ -1,12 +1,14 @@
 package game
 
 import (
-	"time"
+	"math/rand/v2"
+	"sync/atomic"
 
 	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
 
 	"gotetris/internal/audio"
+	"gotetris/internal/scores"
 )
 
 // --- Game Constants -----------------------------------------------------------
@@ -28,7 +30,16 @@ const (
 	Playing
 	Paused
 	GameOver
-	Animating
+	PuzzleSelect
+)
+
+// Phase is where a game in Playing is between pieces.
+type Phase int
+
+const (
+	PhaseFalling   Phase = iota // A piece is in play
+	PhaseLineClear              // Full rows are flashing before they collapse
+	PhaseEntry                  // Waiting for the next piece to appear (ARE)
 )
 
 // --- Piece Types --------------------------------------------------------------
@@ -43,6 +54,9 @@ const (
 	L
 	S
 	Z
+
+	// Garbage marks locked cells that did not come from a tetromino
+	Garbage
 )
 
 // --- Point represents a coordinate on the grid -------------------------------
@@ -59,12 +73,14 @@ type Piece struct {
 	Color         tcell.Color
 	Position      Point
 	Blocks        [4]Point
+	spawnedAt     int // Frame it appeared on
 }
 
 // --- Game represents the complete game state -------------------------------
 
 type Game struct {
 	Playfield    [PlayWidth][TotalHeight]int
+	lockedAt     Field // Play frame each block of Playfield locked at
 	Current      *Piece
 	NextQueue    []PieceID
 	Score        int
@@ -73,19 +89,79 @@ type Game struct {
 	B2B          bool
 	Combo        int
 	State        GameState
+	Phase        Phase // Sub-state while Playing
+	Mode         Mode
+	Stats        Stats
+	Hold         PieceID // Held piece, 0 when the hold slot is empty
 
 	// Game mechanics state
-	LastMoveWasRotation bool // Tracks if the last move was a rotation (for T-spin detection)
+	LastMoveWasRotation bool      // Tracks if the last move was a rotation (for T-spin detection)
+	holdUsed            bool      // Hold can only be used once per piece
+	playFrames          int       // Frames spent in Playing, for timed modes
+	gravityProgress     float64   // Fraction of a row gravity has pulled so far
+	lockFrames          int       // Frames the current piece has rested on the stack
+	lowestY             int       // Lowest row the current piece has reached
+	phaseFrames         int       // Frames left in a line clear or entry delay
+	clearing            []int     // Full rows waiting for the line clear delay
+	clearTSpin          TSpin     // Whether those rows were cleared by a T-spin
+	lastKick            int       // Wall kick the last rotation needed, 0 for none
+	comboLines          int       // Lines cleared in the current combo
+	drops               dropTally // Drop points of the piece in play
+	buffered            []Action  // Input held back for the next piece
+
+	// Randomizer state, seeded per game so runs can be reproduced
+	seed uint64
+	pcg  *rand.PCG // Source behind rng, kept for saving its state
+	rng  *rand.Rand
 
 	// UI/app state
-	app           *tview.Application
-	gravityTicker *time.Ticker
-	audioManager  *audio.AudioManager
-	playfieldView *PlayfieldPrimitive // Reference to the playfield view
-	statusView    *StatusPrimitive    // Reference to the status view
-	nextPieceView *NextPiecePrimitive // Reference to the next piece view
-	quit          chan struct{}
-	input         chan *tcell.EventKey
+	app            *tview.Application
+	clock          Clock
+	audioManager   *audio.AudioManager
+	view           atomic.Pointer[Snapshot] // What the screen draws, see publish
+	drawQueued     atomic.Bool              // A redraw is waiting for tview
+	spectators     Spectators               // Viewers the game is streamed to, if any
+	quit           chan struct{}
+	input          chan *tcell.EventKey
+	clicks         chan Click
+	requests       chan func()          // Changes from the menus, see request
+	ui             *screens             // Menu pages, nil without a screen
+	statePage      string               // Page the state of the game was last shown on
+	settings       Settings             // Player preferences, see SetSettings
+	settingsPath   string               // Where changed settings are kept, if anywhere
+	events         eventBus             // Subscribers to engine events
+	frame          int                  // Ticks since the loop started
+	autoplay       *autoplayer          // Bot driving the pieces, nil for human play
+	hints          *hinter              // Practice hint overlay, nil without a hint bot
+	puzzle         *puzzleRun           // Puzzle being played, nil outside puzzles
+	master         *masterRun           // Master rules state, nil in other modes
+	garbage        *garbageRun          // Garbage state, nil in modes without garbage
+	battle         *battleRun           // Versus match in progress, nil outside one
+	battleBot      func(Difficulty) Bot // Makes the versus opponent, nil without Versus
+	puzzles        []Puzzle             // Puzzles offered in the browser
+	leaderboard    *scores.Table        // Where finished games are ranked, if anywhere
+	player         string               // Name games are ranked under
+	history        []historyStep        // Pieces locked this game, for exports
+	startField     Field                // Board the game started on
+	exportPath     string               // File exports are appended to, if any
+	replayPath     string               // File games are recorded to, if any
+	replayDir      string               // Directory finished games are kept in, if any
+	replay         *Replay              // Game being recorded, nil when not recording
+	camera         Camera               // Takes screenshots, nil when they are off
+	clipboard      []byte               // Export waiting for the next draw to copy it
+	notice         string               // Short message for the side panel
+	noticeUntil    int                  // Frame the notice disappears at
+	savePath       string               // Where games in progress are saved, empty for nowhere
+	saved          *savedGame           // Game in the save file, offered as Continue
+	autosaveFrames int                  // Frames of play between autosaves, 0 for off
+	saveErr        error                // Why saving on quit failed
+	scored         string               // What the last lock earned, for the side panel
+	scoredUntil    int                  // Frame it disappears at
+	announcement   string               // Latest announcement shown under the board
+	announceOut    chan string          // Announcements waiting to be written out, if anywhere
+	cues           *audio.Cues          // Where audio cues play, nil for nowhere
+	dangerous      bool                 // The stack was last announced as in danger
+	dangerHeight   int                  // Height it was announced at
 }
 
 // --- Helper Methods --------------------------------------------------------
@@ -93,39 +169,71 @@ type Game struct {
 // ApplyGravity moves the current piece down one row if possible.
 // If collision, locks the piece.
 func (g *Game) ApplyGravity() {
+	g.fall(1)
+}
+
+// fall moves the current piece down by up to rows rows, stopping where it
+// lands, so 20G drops it to the stack in a single frame. A piece that was
+// already resting locks instead.
+func (g *Game) fall(rows int) {
 	// Only apply gravity in Playing state and if we have a current piece
-	if g.State != Playing || g.Current == nil {
+	if g.State != Playing || g.Current == nil || rows <= 0 {
 		return
 	}
 
-	// Set LastMoveWasRotation to false since this is a vertical movement
-	g.LastMoveWasRotation = false
+	if g.sink(rows) == 0 {
+		g.settle()
+	}
+}
 
-	// Store original position
-	originalY := g.Current.Position.Y
+// sink moves the current piece down by up to rows rows, stopping where the
+// next row is taken, and returns how far it went.
+func (g *Game) sink(rows int) int {
+	// Move down (decrease Y since Y=0 is bottom)
+	startY := g.Current.Position.Y
+	for i := 0; i < rows; i++ {
+		g.Current.Position.Y--
+		if g.checkCollision() {
+			g.Current.Position.Y++
+			break
+		}
+	}
 
-	// Try to move the piece down one row (decrease Y since Y=0 is bottom)
-	g.Current.Position.Y--
+	fell := startY - g.Current.Position.Y
+	if fell > 0 {
+		// Falling means the last move was not a rotation any more
+		g.LastMoveWasRotation = false
+		g.stepReset()
+		g.emit(MovedEvent{Piece: g.Current.ID, DY: -fell, Drop: GravityDrop})
+	}
+	return fell
+}
 
-	// Check if this position is valid
-	if g.checkCollision() {
-		// If collision, restore original position
-		g.Current.Position.Y = originalY
+// resting reports whether the current piece sits on the stack or the floor.
+func (g *Game) resting() bool {
+	below := g.Current.Position
+	below.Y--
+	return !blocksFit(&g.Playfield, g.Current.Blocks, below)
+}
 
-		// Lock the piece only if it's at a valid position
-		if originalY >= 0 && originalY < TotalHeight {
-			g.lockPiece()
-		} else {
-			// If piece is outside valid area, trigger game over
-			g.State = GameOver
-		}
+// stepReset starts the lock delay over once the piece gets lower than it has
+// ever been. Kicking a piece up and letting it fall back buys no time.
+func (g *Game) stepReset() {
+	if y := g.Current.Position.Y; y < g.lowestY {
+		g.lowestY, g.lockFrames = y, 0
 	}
+}
 
-	// Additional safety check: if piece goes below playfield, lock it
-	if g.Current != nil && g.Current.Position.Y < 0 {
-		g.Current.Position.Y = 0
-		g.lockPiece()
+// settle locks the current piece where it rests.
+func (g *Game) settle() {
+	// Lock the piece only if it's inside the playfield
+	for _, c := range g.Current.placement().Cells() {
+		if c.Y < 0 || c.Y >= TotalHeight {
+			g.endGame(ReasonLockOut)
+			return
+		}
 	}
+	g.lockPiece()
 }
 
 // checkCollision checks if the current piece collides with boundaries or other blocks
@@ -134,153 +242,124 @@ func (g *Game) checkCollision() bool {
 		return false
 	}
 
-	for _, b := range g.Current.Blocks {
-		// Calculate the absolute coordinates of this block
-		x := g.Current.Position.X + b.X
-		y := g.Current.Position.Y + b.Y
-
-		// Check horizontal boundaries
-		if x < 0 || x >= PlayWidth {
-			return true
-		}
-
-		// Check bottom boundary (Y=0 is bottom)
-		if y < 0 {
-			return true
-		}
-
-		// Check top boundary (Y=TotalHeight-1 is top)
-		if y >= TotalHeight {
-			return true
-		}
-
-		// Check collision with existing blocks
-		if g.Playfield[x][y] != 0 {
-			return true
-		}
-	}
-
-	return false
+	return !blocksFit(&g.Playfield, g.Current.Blocks, g.Current.Position)
 }
 
 // initScreen sets up the UI layout and primitives
 func (g *Game) initScreen() error {
-	// Create playfield primitive
-	playfield := NewPlayfieldPrimitive(g, 0, 0, 0, 0)
-	g.playfieldView = playfield
-
-	// Create status/scoring box (blue box)
-	statusBox := NewStatusPrimitive(g, 0, 0, 0, 0)
-	g.statusView = statusBox
-
-	// Create next piece box (red box)
-	nextPieceBox := NewNextPiecePrimitive(g, 0, 0, 0, 0)
-	g.nextPieceView = nextPieceBox
+	g.ui = newScreens(g)
 
-	// Create main layout using a simple approach
-	// Use a horizontal flex to split screen into left and right sections
+	// Main layout: the pages with a margin on the left. The board sizes
+	// itself to the rest of the terminal, see NewBoard
 	mainContainer := tview.NewFlex().SetDirection(tview.FlexColumn)
-
-	// Left section: playfield with some padding
-	leftSection := tview.NewFlex().SetDirection(tview.FlexRow)
-	leftSection.AddItem(tview.NewBox(), 1, 0, false) // Top padding
-	leftSection.AddItem(playfield, 0, 1, true)       // Playfield takes remaining space
-	leftSection.AddItem(tview.NewBox(), 1, 0, false) // Bottom padding
-
-	// Right section: status and next piece
-	rightSection := tview.NewFlex().SetDirection(tview.FlexRow)
-	rightSection.AddItem(tview.NewBox(), 1, 0, false) // Top padding
-	rightSection.AddItem(statusBox, 8, 0, false)      // Status box (fixed height)
-	rightSection.AddItem(tview.NewBox(), 1, 0, false) // Gap
-	rightSection.AddItem(nextPieceBox, 6, 0, false)   // Next piece box (fixed height)
-	rightSection.AddItem(tview.NewBox(), 0, 1, false) // Bottom flexible space
-
-	// Add sections to main container
 	mainContainer.AddItem(tview.NewBox(), 2, 0, false) // Left margin
-	mainContainer.AddItem(leftSection, 26, 0, false)   // Playfield section (fixed width)
-	mainContainer.AddItem(tview.NewBox(), 2, 0, false) // Gap between sections
-	mainContainer.AddItem(rightSection, 32, 0, false)  // Right section (fixed width)
-	mainContainer.AddItem(tview.NewBox(), 0, 1, false) // Right margin (flexible)
+	mainContainer.AddItem(g.ui.pages, 0, 1, true)      // Menus, or the board
+
+	// Exports reach the clipboard through the screen, which only the
+	// drawing side may touch
+	g.app.SetAfterDrawFunc(func(screen tcell.Screen) {
+		if g.clipboard != nil {
+			screen.SetClipboard(g.clipboard)
+			g.clipboard = nil
+		}
+	})
 
 	// Set the main container as root
 	g.app.SetRoot(mainContainer, true)
+	g.statePage = pageFor(g.State)
+	g.ui.show(g.statePage)
 	return nil
 } // HandleInput processes a single input event
 func (g *Game) HandleInput(ev *tcell.EventKey) {
+	// Sharing the board works in every state that shows one
+	if g.handleExportInput(ev) {
+		return
+	}
+
+	// The menus are tview pages of their own, see pages.go
 	switch g.State {
-	case MainMenu:
-		// Any key to start in main menu
-		if ev.Key() == tcell.KeyEnter || (ev.Key() == tcell.KeyRune && ev.Rune() == ' ') {
-			g.StartGame()
-		}
 	case GameOver:
-		// Any key to restart after game over
-		if ev.Key() == tcell.KeyEnter || (ev.Key() == tcell.KeyRune && ev.Rune() == ' ') {
+		// Puzzles can be retried or left for the browser; other games
+		// restart on ENTER or go back to the menu on ESC
+		switch {
+		case g.puzzle != nil && isRetry(ev):
+			g.RetryPuzzle()
+		case g.puzzle != nil && (isConfirm(ev) || ev.Key() == tcell.KeyEscape):
+			g.State = PuzzleSelect
+		case isConfirm(ev):
 			g.StartGame()
+		case ev.Key() == tcell.KeyEscape:
+			g.State = MainMenu
 		}
 	case Playing:
 		// Handle pause first
 		if ev.Key() == tcell.KeyEscape || (ev.Key() == tcell.KeyRune && (ev.Rune() == 'p' || ev.Rune() == 'P')) {
-			g.State = Paused
+			g.setPaused(true)
+			return
+		}
+
+		// Hint overlay toggle (practice only)
+		if ev.Key() == tcell.KeyRune && (ev.Rune() == 'h' || ev.Rune() == 'H') {
+			g.ToggleHints()
+			return
+		}
+
+		// Start a puzzle over
+		if g.puzzle != nil && isRetry(ev) {
+			g.RetryPuzzle()
 			return
 		}
 
-		if g.Current != nil {
-			switch ev.Key() {
-			case tcell.KeyLeft:
-				g.moveLeft()
-			case tcell.KeyRight:
-				g.moveRight()
-			case tcell.KeyDown:
-				g.softDrop()
-			case tcell.KeyUp:
-				g.rotate()
-			case tcell.KeyRune:
-				if ev.Rune() == ' ' {
-					g.hardDrop()
-				}
-			}
+		// The autoplayer owns the piece while it is driving
+		if g.autoplay == nil {
+			g.playerAct(g.keyAction(ev))
 		}
 	case Paused:
 		// Resume from pause
 		if ev.Key() == tcell.KeyEscape ||
 			ev.Key() == tcell.KeyEnter ||
 			(ev.Key() == tcell.KeyRune && (ev.Rune() == ' ' || ev.Rune() == 'p' || ev.Rune() == 'P')) {
-			g.State = Playing
+			g.setPaused(false)
 		}
 	}
 }
 
-// render updates the display
-func (g *Game) render() {
-	// This is handled by PlayfieldPrimitive.Draw
+// setPaused pauses or resumes the game.
+func (g *Game) setPaused(paused bool) {
+	if paused {
+		g.State = Paused
+	} else {
+		g.State = Playing
+	}
+	g.emit(PausedEvent{Paused: paused})
 }
 
 // Helper move functions
 func (g *Game) moveLeft() {
-	// Set LastMoveWasRotation to false since this is a horizontal movement
-	g.LastMoveWasRotation = false
-
 	g.Current.Position.X--
 	if g.checkCollision() {
 		g.Current.Position.X++
+		return
 	}
-}
 
-func (g *Game) moveRight() {
 	// Set LastMoveWasRotation to false since this is a horizontal movement
 	g.LastMoveWasRotation = false
+	g.emit(MovedEvent{Piece: g.Current.ID, DX: -1})
+}
 
+func (g *Game) moveRight() {
 	g.Current.Position.X++
 	if g.checkCollision() {
 		g.Current.Position.X--
+		return
 	}
-}
 
-func (g *Game) softDrop() {
-	// Set LastMoveWasRotation to false since this is a vertical movement
+	// Set LastMoveWasRotation to false since this is a horizontal movement
 	g.LastMoveWasRotation = false
+	g.emit(MovedEvent{Piece: g.Current.ID, DX: 1})
+}
 
+func (g *Game) softDrop() {
 	// Store the original position in case we need to revert
 	originalY := g.Current.Position.Y
 
@@ -295,18 +374,26 @@ func (g *Game) softDrop() {
 		// If we can't move down due to collision with locked pieces or bottom,
 		// lock the current piece
 		g.lockPiece()
+		return
 	}
+
+	// Set LastMoveWasRotation to false since this is a vertical movement
+	g.LastMoveWasRotation = false
+	g.stepReset()
+	g.scoreDrop(SoftDrop, 1)
+	g.emit(MovedEvent{Piece: g.Current.ID, DY: -1, Drop: SoftDrop})
 }
 
+// hardDrop drops the piece as far as it goes and locks it there. A piece
+// already resting locks too, without moving or scoring: bots end every plan
+// with a hard drop, and a piece that spawns or slides right where they want
+// it must lock on that drop rather than sit out the lock delay.
 func (g *Game) hardDrop() {
 	// Safety check
 	if g.Current == nil {
 		return
 	}
 
-	// Set LastMoveWasRotation to false since this is a vertical movement
-	g.LastMoveWasRotation = false
-
 	// Track how many cells the piece drops for scoring
 	dropDistance := 0
 
@@ -333,41 +420,58 @@ func (g *Game) hardDrop() {
 		}
 	}
 
-	// Only lock if we actually moved
 	if startY != g.Current.Position.Y {
-		// Lock the piece in place
-		g.lockPiece()
+		// A drop that actually moves the piece is the last move, not the
+		// rotation before it
+		g.LastMoveWasRotation = false
+		g.scoreDrop(HardDrop, dropDistance)
+		g.emit(MovedEvent{Piece: g.Current.ID, DY: g.Current.Position.Y - startY, Drop: HardDrop})
 	}
+
+	// Lock the piece in place, even if it was already resting on the stack
+	g.lockPiece()
 }
 
-func (g *Game) rotate() {
+func (g *Game) rotate(dir int) {
 	oldState := g.Current.RotationState
-	g.Current.RotationState = (g.Current.RotationState + 1) % 4
-
-	// Apply rotation by rebuilding blocks
-	var newBlocks [4]Point
-	idx := 0
-	for y := 0; y < 4; y++ {
-		for x := 0; x < 4; x++ {
-			if PieceShapes[g.Current.ID][g.Current.RotationState][y][x] {
-				newBlocks[idx] = Point{X: x, Y: y}
-				idx++
-			}
-		}
-	}
 
-	// Save old blocks, try new blocks
-	oldBlocks := g.Current.Blocks
-	g.Current.Blocks = newBlocks
-
-	// If collision, restore old state
-	if g.checkCollision() {
-		g.Current.RotationState = oldState
-		g.Current.Blocks = oldBlocks
+	// Try the rotation in place first, then the wall kicks
+	next, kick, ok := rotatePlacement(&g.Playfield, g.Current.placement(), dir)
+	if !ok {
 		// Rotation failed, so don't set LastMoveWasRotation
 		return
 	}
+	g.Current.RotationState = next.Rotation
+	g.Current.Position = next.Position
+	g.Current.Blocks = ShapeBlocks(g.Current.ID, next.Rotation)
 
 	// Rotation succeeded, set LastMoveWasRotation to true
 	g.LastMoveWasRotation = true
+	g.lastKick = kick
+	g.emit(RotatedEvent{Piece: g.Current.ID, From: oldState, To: g.Current.RotationState, Kick: kick})
+}
+
+// hold swaps the current piece with the held one, or stashes it and takes
+// the next piece when the hold slot is empty. Once per piece.
+func (g *Game) hold() {
+	if !g.CanHold() {
+		return
+	}
+	held := g.Current.ID
+	if g.Hold != 0 {
+		g.spawnPiece(g.Hold)
+	} else {
+		g.spawnNext()
+	}
+	g.Hold, g.holdUsed = held, true
+	g.emit(HeldEvent{Piece: held})
+}
+
+// CanHold reports whether the current piece may go into the hold slot.
+func (g *Game) CanHold() bool {
+	if g.State != Playing || g.Current == nil || g.holdUsed {
+		return false
+	}
+	// Puzzles with a fixed queue can run out of pieces to swap in
+	return g.Hold != 0 || len(g.NextQueue) > 0 || !g.fixedQueue()
 }
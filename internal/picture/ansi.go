@@ -0,0 +1,61 @@
+package picture
+
+import (
+	"bufio"
+	"fmt"
+	"image/color"
+	"io"
+	"strings"
+)
+
+// writeANSI draws every block as two characters, colored with 24-bit escape
+// codes, and the board in a box-drawing frame that takes the gaps on either
+// side of it.
+func writeANSI(w io.Writer, sc scene) error {
+	var grid [rows][cols]*block
+	for i := range sc.blocks {
+		b := &sc.blocks[i]
+		grid[b.Y][b.X] = b
+	}
+
+	bw := bufio.NewWriter(w)
+	frame := fg(frameColor)
+	boardWidth := 2 * (nextX - 1 - boardX)
+	edge := func(left, right string) {
+		fmt.Fprintf(bw, "%s %s%s%s%s\x1b[0m\n", strings.Repeat("  ", boardX-1), frame, left, strings.Repeat("─", boardWidth), right)
+	}
+	cells := func(y, from, to int) {
+		for x := from; x < to; x++ {
+			switch b := grid[y][x]; {
+			case b == nil:
+				bw.WriteString("  ")
+			case b.Ghost:
+				fmt.Fprintf(bw, "%s░░\x1b[0m", fg(b.Color))
+			case b.Pattern != "":
+				fmt.Fprintf(bw, "%s%s%s\x1b[0m", fg(boardColor), bg(b.Color), b.Pattern)
+			default:
+				fmt.Fprintf(bw, "%s██\x1b[0m", fg(b.Color))
+			}
+		}
+	}
+
+	edge("┌", "┐")
+	for y := 0; y < rows; y++ {
+		cells(y, holdX, boardX-1)
+		fmt.Fprintf(bw, " %s│\x1b[0m", frame)
+		cells(y, boardX, nextX-1)
+		fmt.Fprintf(bw, "%s│\x1b[0m ", frame)
+		cells(y, nextX, cols)
+		bw.WriteString("\n")
+	}
+	edge("└", "┘")
+	return bw.Flush()
+}
+
+func fg(c color.RGBA) string {
+	return fmt.Sprintf("\x1b[38;2;%d;%d;%dm", c.R, c.G, c.B)
+}
+
+func bg(c color.RGBA) string {
+	return fmt.Sprintf("\x1b[48;2;%d;%d;%dm", c.R, c.G, c.B)
+}
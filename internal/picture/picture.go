@@ -0,0 +1,181 @@
+// Package picture draws a position, the board with the piece in play, its
+// ghost, the hold and the queue, as a PNG, an SVG or ANSI-colored text for
+// docs, puzzles and bug reports.
+//
+// Pictures show the whole board, also the blocks a fading or invisible stack
+// hides from the player.
+package picture
+
+import (
+	"fmt"
+	"image/color"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"gotetris/internal/game"
+)
+
+// Format is a kind of picture.
+type Format string
+
+// Formats pictures come in.
+const (
+	PNG  Format = "png"
+	SVG  Format = "svg"
+	ANSI Format = "ansi"
+)
+
+// ParseFormat looks up a format by name, ignoring case.
+func ParseFormat(name string) (Format, error) {
+	switch f := Format(strings.ToLower(name)); f {
+	case PNG, SVG, ANSI:
+		return f, nil
+	}
+	return "", fmt.Errorf("unknown format %q (available: png, svg, ansi)", name)
+}
+
+// FormatFor picks the format from a file name's extension; anything that
+// is not an image is taken for text.
+func FormatFor(path string) Format {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".png":
+		return PNG
+	case ".svg":
+		return SVG
+	}
+	return ANSI
+}
+
+// Ext is the file extension for pictures in the format.
+func (f Format) Ext() string {
+	if f == ANSI {
+		return ".ans"
+	}
+	return "." + string(f)
+}
+
+// Write draws the position in s.
+func Write(w io.Writer, s *game.Snapshot, f Format) error {
+	sc := layout(s)
+	switch f {
+	case PNG:
+		return writePNG(w, sc)
+	case SVG:
+		return writeSVG(w, sc)
+	case ANSI:
+		return writeANSI(w, sc)
+	}
+	return fmt.Errorf("unknown format %q", f)
+}
+
+// --- Layout -------------------------------------------------------------------
+//
+// Every format draws the same grid of blocks, counted from the top left: the
+// hold on the left, the board in the middle and the queue on the right.
+
+const (
+	holdX  = 0
+	boardX = 5
+	nextX  = boardX + game.PlayWidth + 1
+	cols   = nextX + 4
+	rows   = game.VisibleHeight
+
+	queueShown = 5 // Next pieces shown
+	previewGap = 3 // Rows from one next piece to the one after
+)
+
+// Colors of the picture around the blocks.
+var (
+	background = color.RGBA{0x1c, 0x1c, 0x1c, 0xff}
+	boardColor = color.RGBA{0x00, 0x00, 0x00, 0xff}
+	frameColor = color.RGBA{0x80, 0x80, 0x80, 0xff}
+)
+
+// block is one square of the picture.
+type block struct {
+	X, Y  int
+	Color color.RGBA
+	Ghost bool // Outline of where the piece in play lands
+
+	Pattern string // Glyphs drawn on it in ANSI pictures, "" for a solid block
+}
+
+// scene is a position ready to draw.
+type scene struct {
+	blocks []block
+}
+
+// layout places every block of the position.
+func layout(s *game.Snapshot) scene {
+	var sc scene
+	board := func(x, y int, id game.PieceID, ghost bool) {
+		if x >= 0 && x < game.PlayWidth && y >= 0 && y < rows {
+			b := block{X: boardX + x, Y: rows - 1 - y, Color: pieceColor(s, id), Ghost: ghost}
+			if !ghost {
+				b.Pattern = pattern(s, id)
+			}
+			sc.blocks = append(sc.blocks, b)
+		}
+	}
+
+	for x := 0; x < game.PlayWidth; x++ {
+		for y := 0; y < rows; y++ {
+			if id := s.Playfield[x][y]; id != 0 {
+				board(x, y, game.PieceID(id), false)
+			}
+		}
+	}
+	if cur := s.Current; cur != nil && (s.State == game.Playing || s.State == game.Paused) {
+		if ghost := s.Ghost; ghost != nil && *ghost != *cur {
+			for _, p := range ghost.Cells() {
+				board(p.X, p.Y, cur.Piece, true)
+			}
+		}
+		for _, p := range cur.Cells() {
+			board(p.X, p.Y, cur.Piece, false)
+		}
+	}
+
+	sc.preview(s, s.Hold, holdX, 0)
+	for i, id := range s.Next[:min(len(s.Next), queueShown)] {
+		sc.preview(s, id, nextX, i*previewGap)
+	}
+	return sc
+}
+
+// preview draws a piece in its spawn orientation with its top left at x, y.
+func (sc *scene) preview(s *game.Snapshot, id game.PieceID, x, y int) {
+	if id == 0 {
+		return
+	}
+	cells := game.ShapeBlocks(id, 0)
+	minX, maxY := cells[0].X, cells[0].Y
+	for _, c := range cells {
+		minX, maxY = min(minX, c.X), max(maxY, c.Y)
+	}
+	for _, c := range cells {
+		sc.blocks = append(sc.blocks, block{X: x + c.X - minX, Y: y + maxY - c.Y, Color: pieceColor(s, id), Pattern: pattern(s, id)})
+	}
+}
+
+// pieceColor is the color the game draws a piece in, in the palette of s.
+func pieceColor(s *game.Snapshot, id game.PieceID) color.RGBA {
+	r, g, b := s.PieceColor(id).RGB()
+	return color.RGBA{uint8(r), uint8(g), uint8(b), 0xff}
+}
+
+// pattern is the glyph pair of piece id, if s draws pieces with patterns.
+func pattern(s *game.Snapshot, id game.PieceID) string {
+	glyphs, ok := game.PieceGlyphs[id]
+	if !s.Patterns || !ok {
+		return ""
+	}
+	return string(glyphs[:])
+}
+
+// mix blends c into the background, keeping amount of it.
+func mix(c, bg color.RGBA, amount float64) color.RGBA {
+	blend := func(a, b uint8) uint8 { return uint8(float64(a)*amount + float64(b)*(1-amount)) }
+	return color.RGBA{blend(c.R, bg.R), blend(c.G, bg.G), blend(c.B, bg.B), 0xff}
+}
@@ -0,0 +1,48 @@
+package picture
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+
+	"gotetris/internal/game"
+)
+
+// Sizes in pixels.
+const (
+	blockPx  = 24
+	marginPx = 12
+	framePx  = 2
+)
+
+func writePNG(w io.Writer, sc scene) error {
+	img := image.NewRGBA(image.Rect(0, 0, cols*blockPx+2*marginPx, rows*blockPx+2*marginPx))
+	fill := func(r image.Rectangle, c color.RGBA) {
+		draw.Draw(img, r, &image.Uniform{c}, image.Point{}, draw.Src)
+	}
+	fill(img.Bounds(), background)
+
+	board := blockRect(boardX, 0, game.PlayWidth, rows)
+	fill(board.Inset(-framePx), frameColor)
+	fill(board, boardColor)
+
+	for _, b := range sc.blocks {
+		r := blockRect(b.X, b.Y, 1, 1)
+		if b.Ghost {
+			fill(r.Inset(1), mix(b.Color, boardColor, 0.8))
+			fill(r.Inset(3), boardColor)
+			continue
+		}
+		// A lighter rim so blocks of one piece stay apart
+		fill(r, mix(b.Color, color.RGBA{0xff, 0xff, 0xff, 0xff}, 0.7))
+		fill(r.Inset(2), b.Color)
+	}
+	return png.Encode(w, img)
+}
+
+// blockRect is the area of w by h blocks from block x, y.
+func blockRect(x, y, w, h int) image.Rectangle {
+	return image.Rect(marginPx+x*blockPx, marginPx+y*blockPx, marginPx+(x+w)*blockPx, marginPx+(y+h)*blockPx)
+}
@@ -0,0 +1,38 @@
+package picture
+
+import (
+	"bufio"
+	"fmt"
+	"image/color"
+	"io"
+
+	"gotetris/internal/game"
+)
+
+func writeSVG(w io.Writer, sc scene) error {
+	bw := bufio.NewWriter(w)
+	width, height := cols*blockPx+2*marginPx, rows*blockPx+2*marginPx
+	fmt.Fprintf(bw, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`+"\n", width, height, width, height)
+	fmt.Fprintf(bw, `<rect width="100%%" height="100%%" fill="%s"/>`+"\n", hex(background))
+
+	board := blockRect(boardX, 0, game.PlayWidth, rows)
+	fmt.Fprintf(bw, `<rect x="%d" y="%d" width="%d" height="%d" fill="%s" stroke="%s" stroke-width="%d"/>`+"\n",
+		board.Min.X, board.Min.Y, board.Dx(), board.Dy(), hex(boardColor), hex(frameColor), 2*framePx)
+
+	for _, b := range sc.blocks {
+		r := blockRect(b.X, b.Y, 1, 1)
+		if b.Ghost {
+			fmt.Fprintf(bw, `<rect x="%d" y="%d" width="%d" height="%d" fill="none" stroke="%s" stroke-width="2"/>`+"\n",
+				r.Min.X+2, r.Min.Y+2, r.Dx()-4, r.Dy()-4, hex(mix(b.Color, boardColor, 0.8)))
+			continue
+		}
+		fmt.Fprintf(bw, `<rect x="%d" y="%d" width="%d" height="%d" fill="%s" stroke="%s" stroke-width="2"/>`+"\n",
+			r.Min.X+1, r.Min.Y+1, r.Dx()-2, r.Dy()-2, hex(b.Color), hex(mix(b.Color, color.RGBA{0xff, 0xff, 0xff, 0xff}, 0.7)))
+	}
+	fmt.Fprintln(bw, "</svg>")
+	return bw.Flush()
+}
+
+func hex(c color.RGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
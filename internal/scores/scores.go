@@ -0,0 +1,155 @@
+// Package scores keeps the high score table: the best games of each mode,
+// in a JSON file that every player on the machine (or on its SSH server)
+// writes to.
+package scores
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Keep is how many games the table keeps per mode.
+const Keep = 10
+
+// Entry is one game in the table.
+type Entry struct {
+	Name     string        `json:"name"`
+	Mode     string        `json:"mode"`
+	Score    int           `json:"score"`
+	Lines    int           `json:"lines"`
+	Level    int           `json:"level"`
+	Grade    string        `json:"grade,omitempty"`
+	Time     time.Duration `json:"time"`
+	Finished bool          `json:"finished"` // The mode's goal was reached
+	Race     bool          `json:"race"`     // The goal was lines against the clock, so time ranks
+	Date     time.Time     `json:"date"`
+}
+
+// better reports whether a ranks above b. Games that reached their goal beat
+// those that did not; among those a race is won on time, anything else on
+// score, and the earlier game keeps a tie.
+func better(a, b Entry) bool {
+	if a.Finished != b.Finished {
+		return a.Finished
+	}
+	if a.Race && a.Finished && a.Time != b.Time {
+		return a.Time < b.Time
+	}
+	if a.Score != b.Score {
+		return a.Score > b.Score
+	}
+	return a.Date.Before(b.Date)
+}
+
+// Table is the high score file. It is safe to use from several goroutines;
+// every call reads the file afresh, so other processes writing it are
+// picked up too.
+type Table struct {
+	path string
+	mu   sync.Mutex
+}
+
+// Open returns the table kept in path. The file need not exist yet.
+func Open(path string) *Table {
+	return &Table{path: path}
+}
+
+// Add puts a game in the table and returns its rank in its mode from 1, or
+// 0 if it did not make the table.
+func (t *Table) Add(e Entry) (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	entries, err := t.load()
+	if err != nil {
+		return 0, err
+	}
+	entries = append(entries, e)
+	slices.SortStableFunc(entries, func(a, b Entry) int {
+		switch {
+		case better(a, b):
+			return -1
+		case better(b, a):
+			return 1
+		}
+		return 0
+	})
+
+	// Trim every mode to its best Keep, noting where the new game landed
+	rank, kept, perMode := 0, entries[:0], map[string]int{}
+	for _, entry := range entries {
+		if perMode[entry.Mode] == Keep {
+			continue
+		}
+		perMode[entry.Mode]++
+		if entry == e {
+			rank = perMode[entry.Mode]
+		}
+		kept = append(kept, entry)
+	}
+	if rank == 0 {
+		return 0, nil
+	}
+	return rank, t.store(kept)
+}
+
+// Top returns the table for a mode, best first.
+func (t *Table) Top(mode string) ([]Entry, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	entries, err := t.load()
+	if err != nil {
+		return nil, err
+	}
+	var top []Entry
+	for _, e := range entries {
+		if e.Mode == mode {
+			top = append(top, e)
+		}
+	}
+	return top, nil
+}
+
+// load reads every entry, best first in each mode.
+func (t *Table) load() ([]Entry, error) {
+	data, err := os.ReadFile(t.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var entries []Entry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// store writes the table next to the real file and swaps it in, so readers
+// never see half of it.
+func (t *Table) store(entries []Entry) error {
+	if err := os.MkdirAll(filepath.Dir(t.path), 0o755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := t.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, t.path); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,173 @@
+// Package sim runs headless games as fast as the CPU allows, for bot research
+// and for regression checks on the rules.
+package sim
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"gotetris/internal/game"
+)
+
+// ReasonPieceLimit is recorded for games cut short by Config.MaxPieces.
+const ReasonPieceLimit = "piece limit"
+
+// Config describes a batch of games.
+type Config struct {
+	Games     int
+	Workers   int                      // Goroutines running games in parallel
+	NewBot    func() (game.Bot, error) // Called once per game; closed after if it has Close
+	Seed      uint64                   // Game i uses Seed+i
+	Mode      game.Mode
+	PPS       float64 // Bot throttle in simulated time (0 = unlimited)
+	MaxPieces int     // Stop endless games after this many pieces (0 = never)
+}
+
+// Result is the outcome of one game.
+type Result struct {
+	Seed   uint64        `json:"seed"`
+	Lines  int           `json:"lines"`
+	Score  int           `json:"score"`
+	Level  int           `json:"level"`
+	Grade  string        `json:"grade,omitempty"`
+	Pieces int           `json:"pieces"`
+	Time   time.Duration `json:"time_ns"`
+	Reason string        `json:"reason"`
+}
+
+// PPS is the pieces placed per second of simulated play.
+func (r Result) PPS() float64 {
+	if r.Time <= 0 {
+		return 0
+	}
+	return float64(r.Pieces) / r.Time.Seconds()
+}
+
+// Report aggregates a batch.
+type Report struct {
+	Games       int            `json:"games"`
+	Mode        string         `json:"mode"`
+	MeanLines   float64        `json:"mean_lines"`
+	MedianLines float64        `json:"median_lines"`
+	MeanScore   float64        `json:"mean_score"`
+	MedianScore float64        `json:"median_score"`
+	MeanPPS     float64        `json:"mean_pps"`
+	Causes      map[string]int `json:"causes"`
+	WallTime    time.Duration  `json:"wall_time_ns"`
+	Results     []Result       `json:"results"`
+}
+
+// Run plays cfg.Games games spread over cfg.Workers goroutines.
+func Run(cfg Config) (Report, error) {
+	if cfg.Games <= 0 {
+		return Report{}, fmt.Errorf("need at least one game, got %d", cfg.Games)
+	}
+	if cfg.Workers <= 0 {
+		cfg.Workers = 1
+	}
+
+	start := time.Now()
+	results := make([]Result, cfg.Games)
+	errs := make([]error, cfg.Games) // One slot per game, so failing never blocks
+	jobs := make(chan int)
+
+	var wg sync.WaitGroup
+	for w := 0; w < cfg.Workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i], errs[i] = Play(cfg, cfg.Seed+uint64(i))
+			}
+		}()
+	}
+	for i := 0; i < cfg.Games; i++ {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return Report{}, err
+		}
+	}
+
+	rep := summarize(results)
+	rep.Mode = cfg.Mode.Name
+	rep.WallTime = time.Since(start)
+	return rep, nil
+}
+
+// Play runs a single game with the given seed until it ends.
+func Play(cfg Config, seed uint64) (Result, error) {
+	b, err := cfg.NewBot()
+	if err != nil {
+		return Result{}, err
+	}
+	if c, ok := b.(interface{ Close() error }); ok {
+		defer c.Close()
+	}
+
+	g := game.NewGame(nil, nil)
+	g.SetMode(cfg.Mode)
+	g.SetSeed(seed)
+	g.SetAutoplay(b, cfg.PPS)
+
+	res := Result{Seed: seed}
+	g.Subscribe(func(e game.Event) {
+		switch ev := e.(type) {
+		case game.LockedEvent:
+			res.Pieces++
+		case game.GameOverEvent:
+			res.Reason, res.Grade = ev.Reason, ev.Grade
+		}
+	})
+
+	g.StartGame()
+	for g.State != game.GameOver {
+		if cfg.MaxPieces > 0 && res.Pieces >= cfg.MaxPieces {
+			res.Reason = ReasonPieceLimit
+			break
+		}
+		g.Step()
+	}
+
+	res.Lines, res.Score, res.Level, res.Time = g.LinesCleared, g.Score, g.Level, g.Elapsed()
+	return res, nil
+}
+
+// summarize computes the aggregate numbers for a batch.
+func summarize(results []Result) Report {
+	rep := Report{Games: len(results), Causes: map[string]int{}, Results: results}
+
+	lines := make([]float64, len(results))
+	scores := make([]float64, len(results))
+	for i, r := range results {
+		lines[i], scores[i] = float64(r.Lines), float64(r.Score)
+		rep.MeanLines += lines[i]
+		rep.MeanScore += scores[i]
+		rep.MeanPPS += r.PPS()
+		rep.Causes[r.Reason]++
+	}
+
+	n := float64(len(results))
+	rep.MeanLines /= n
+	rep.MeanScore /= n
+	rep.MeanPPS /= n
+	rep.MedianLines = median(lines)
+	rep.MedianScore = median(scores)
+	return rep
+}
+
+func median(values []float64) float64 {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
@@ -0,0 +1,33 @@
+package sim
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"gotetris/internal/game"
+)
+
+func TestRunAllGamesFail(t *testing.T) {
+	failed := errors.New("no bot")
+	cfg := Config{
+		Games:   16,
+		Workers: 2,
+		NewBot:  func() (game.Bot, error) { return nil, failed },
+		Mode:    game.Marathon,
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := Run(cfg)
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if !errors.Is(err, failed) {
+			t.Fatalf("got %v, want %v", err, failed)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("Run deadlocked")
+	}
+}
@@ -0,0 +1,127 @@
+// Package spectate streams a game over TCP so others can watch it live,
+// read-only, from their own terminals.
+//
+// The stream is newline separated JSON from the game to the viewer. It opens
+// with hello (who is playing), then a snapshot with the whole board, then a
+// delta whenever something on screen changes: the cells that changed and the
+// status, if that changed too. Viewers that join late start from a snapshot
+// of the moment they joined; viewers that fall behind are sent one again.
+package spectate
+
+import (
+	"time"
+
+	"gotetris/internal/game"
+)
+
+// Version is the version of the stream, sent in hello.
+const Version = 1
+
+// Message types.
+const (
+	TypeHello    = "hello"
+	TypeSnapshot = "snapshot"
+	TypeDelta    = "delta"
+)
+
+// Message is any message of the stream; Type says which fields matter.
+type Message struct {
+	Type string `json:"type"`
+
+	// hello
+	Player  string `json:"player,omitempty"`
+	Version int    `json:"version,omitempty"`
+
+	// snapshot
+	Board    *game.Field `json:"board,omitempty"`
+	LockedAt *game.Field `json:"locked_at,omitempty"`
+
+	// delta
+	Cells []Cell `json:"cells,omitempty"`
+
+	// snapshot, and delta when it changed
+	Status *Status `json:"status,omitempty"`
+}
+
+// Cell is one playfield cell that changed.
+type Cell struct {
+	X        int `json:"x"`
+	Y        int `json:"y"`
+	Value    int `json:"v"` // Piece the block came from, 0 for empty
+	LockedAt int `json:"t"` // Play frame the block locked at
+}
+
+// Status is everything on screen apart from the locked blocks.
+type Status struct {
+	State       game.GameState      `json:"state"`
+	Phase       game.Phase          `json:"phase"`
+	Current     *game.Placement     `json:"current"`
+	Next        []game.PieceID      `json:"next"`
+	Hold        game.PieceID        `json:"hold"`
+	HoldUsed    bool                `json:"hold_used"`
+	Score       int                 `json:"score"`
+	Level       int                 `json:"level"`
+	Lines       int                 `json:"lines"`
+	Grade       string              `json:"grade,omitempty"`
+	Section     int                 `json:"section,omitempty"`
+	Garbage     *game.GarbageStatus `json:"garbage,omitempty"`
+	Battle      *game.BattleStatus  `json:"battle,omitempty"`
+	Mode        string              `json:"mode"`
+	Elapsed     time.Duration       `json:"elapsed"`
+	Played      int                 `json:"played"`
+	Stats       game.Stats          `json:"stats"`
+	Stack       game.Stack          `json:"stack"`
+	Clearing    []int               `json:"clearing,omitempty"`
+	ClearingLit bool                `json:"clearing_lit,omitempty"`
+	Puzzle      *game.PuzzleStatus  `json:"puzzle,omitempty"`
+}
+
+// statusOf picks the status out of a snapshot.
+func statusOf(s *game.Snapshot) Status {
+	return Status{
+		State:       s.State,
+		Phase:       s.Phase,
+		Current:     s.Current,
+		Next:        s.Next,
+		Hold:        s.Hold,
+		HoldUsed:    s.HoldUsed,
+		Score:       s.Score,
+		Level:       s.Level,
+		Lines:       s.Lines,
+		Grade:       s.Grade,
+		Section:     s.Section,
+		Garbage:     s.Garbage,
+		Battle:      s.Battle,
+		Mode:        s.Mode,
+		Elapsed:     s.Elapsed,
+		Played:      s.Played,
+		Stats:       s.Stats,
+		Stack:       s.Stack,
+		Clearing:    s.Clearing,
+		ClearingLit: s.ClearingLit,
+		Puzzle:      s.Puzzle,
+	}
+}
+
+// apply puts the status back into a snapshot.
+func (st Status) apply(s *game.Snapshot) {
+	s.State, s.Phase = st.State, st.Phase
+	s.Current, s.Next, s.Hold, s.HoldUsed = st.Current, st.Next, st.Hold, st.HoldUsed
+	s.Score, s.Level, s.Lines, s.Grade, s.Section = st.Score, st.Level, st.Lines, st.Grade, st.Section
+	s.Garbage, s.Battle = st.Garbage, st.Battle
+	s.Mode, s.Elapsed, s.Played, s.Stats, s.Stack = st.Mode, st.Elapsed, st.Played, st.Stats, st.Stack
+	s.Clearing, s.ClearingLit, s.Puzzle = st.Clearing, st.ClearingLit, st.Puzzle
+}
+
+// diffCells lists the cells that differ between two boards.
+func diffCells(board, lockedAt, newBoard, newLockedAt *game.Field) []Cell {
+	var cells []Cell
+	for x := range newBoard {
+		for y := range newBoard[x] {
+			if board[x][y] != newBoard[x][y] || lockedAt[x][y] != newLockedAt[x][y] {
+				cells = append(cells, Cell{X: x, Y: y, Value: newBoard[x][y], LockedAt: newLockedAt[x][y]})
+			}
+		}
+	}
+	return cells
+}
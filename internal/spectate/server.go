@@ -0,0 +1,221 @@
+package spectate
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"gotetris/internal/game"
+)
+
+// --- Game Side ----------------------------------------------------------------
+
+// viewerBuffer is how many messages a viewer may fall behind by before it is
+// sent a snapshot instead.
+const viewerBuffer = 64
+
+// writeTimeout drops viewers that stop reading altogether.
+const writeTimeout = 10 * time.Second
+
+// Server streams a game to every viewer connected to it. It is the game's
+// Spectators (see game.SetSpectators).
+type Server struct {
+	player string
+	ln     net.Listener
+	latest chan *game.Snapshot // Newest snapshot not sent yet
+	done   chan struct{}
+
+	closeOnce sync.Once
+	closeErr  error
+
+	mu       sync.Mutex
+	started  bool // Whether a snapshot was shown yet
+	board    game.Field
+	lockedAt game.Field
+	status   Status
+	encoded  []byte // status as sent, to tell when it changes
+	viewers  map[*viewer]struct{}
+}
+
+// viewer is one connection watching the game.
+type viewer struct {
+	conn   net.Conn
+	out    chan []byte
+	behind bool // Messages were dropped, a snapshot is due
+}
+
+// Listen starts streaming on addr (host:port, ":port" for every interface)
+// under the player's name.
+func Listen(addr, player string) (*Server, error) {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	s := &Server{
+		player:  player,
+		ln:      ln,
+		latest:  make(chan *game.Snapshot, 1),
+		done:    make(chan struct{}),
+		viewers: map[*viewer]struct{}{},
+	}
+	go s.accept()
+	go s.broadcast()
+	return s, nil
+}
+
+// Addr is the address viewers connect to.
+func (s *Server) Addr() net.Addr {
+	return s.ln.Addr()
+}
+
+// Show hands the server the game's newest snapshot. It never blocks: a
+// snapshot the server has not got round to yet is replaced.
+func (s *Server) Show(snap *game.Snapshot) {
+	select {
+	case <-s.latest:
+	default:
+	}
+	s.latest <- snap
+}
+
+// Close stops listening and disconnects every viewer. Closing again does
+// nothing and reports the first close's error.
+func (s *Server) Close() error {
+	s.closeOnce.Do(func() {
+		close(s.done)
+		s.closeErr = s.ln.Close()
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		for v := range s.viewers {
+			s.drop(v)
+		}
+	})
+	return s.closeErr
+}
+
+// accept lets viewers in until the server closes.
+func (s *Server) accept() {
+	for {
+		conn, err := s.ln.Accept()
+		if err != nil {
+			return
+		}
+		s.join(conn)
+	}
+}
+
+// join greets a viewer and catches it up with a snapshot of the game.
+func (s *Server) join(conn net.Conn) {
+	v := &viewer{conn: conn, out: make(chan []byte, viewerBuffer)}
+
+	s.mu.Lock()
+	v.out <- encode(Message{Type: TypeHello, Player: s.player, Version: Version})
+	if s.started {
+		v.out <- s.snapshot()
+	}
+	s.viewers[v] = struct{}{}
+	s.mu.Unlock()
+
+	go s.write(v)
+	// Viewers have nothing to say; reading only tells when they hang up
+	go func() {
+		io.Copy(io.Discard, conn)
+		s.mu.Lock()
+		s.drop(v)
+		s.mu.Unlock()
+	}()
+}
+
+// write sends a viewer its messages until it goes away.
+func (s *Server) write(v *viewer) {
+	for msg := range v.out {
+		v.conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+		if _, err := v.conn.Write(msg); err != nil {
+			s.mu.Lock()
+			s.drop(v)
+			s.mu.Unlock()
+			return
+		}
+	}
+}
+
+// drop disconnects a viewer. The caller holds s.mu.
+func (s *Server) drop(v *viewer) {
+	if _, ok := s.viewers[v]; !ok {
+		return
+	}
+	delete(s.viewers, v)
+	close(v.out)
+	v.conn.Close()
+}
+
+// broadcast turns every snapshot the game shows into a delta for the
+// viewers, skipping the ones where nothing changed.
+func (s *Server) broadcast() {
+	for {
+		var snap *game.Snapshot
+		select {
+		case snap = <-s.latest:
+		case <-s.done:
+			return
+		}
+
+		s.mu.Lock()
+		msg := s.update(snap)
+		if msg != nil {
+			var full []byte
+			for v := range s.viewers {
+				out := msg
+				if v.behind {
+					if full == nil {
+						full = s.snapshot()
+					}
+					out = full
+				}
+				select {
+				case v.out <- out:
+					v.behind = false
+				default:
+					v.behind = true
+				}
+			}
+		}
+		s.mu.Unlock()
+	}
+}
+
+// update takes in a snapshot and returns the delta from the last one, or
+// nil if nothing changed. The caller holds s.mu.
+func (s *Server) update(snap *game.Snapshot) []byte {
+	status := statusOf(snap)
+	encoded, _ := json.Marshal(status)
+	cells := diffCells(&s.board, &s.lockedAt, &snap.Playfield, &snap.LockedAt)
+
+	delta := Message{Type: TypeDelta, Cells: cells}
+	if !s.started || !bytes.Equal(encoded, s.encoded) {
+		delta.Status = &status
+	}
+	if s.started && delta.Status == nil && len(cells) == 0 {
+		return nil
+	}
+
+	s.started = true
+	s.board, s.lockedAt = snap.Playfield, snap.LockedAt
+	s.status, s.encoded = status, encoded
+	return encode(delta)
+}
+
+// snapshot is the whole game as last shown. The caller holds s.mu.
+func (s *Server) snapshot() []byte {
+	board, lockedAt, status := s.board, s.lockedAt, s.status
+	return encode(Message{Type: TypeSnapshot, Board: &board, LockedAt: &lockedAt, Status: &status})
+}
+
+// encode writes out one line of the stream.
+func encode(m Message) []byte {
+	b, _ := json.Marshal(m)
+	return append(b, '\n')
+}
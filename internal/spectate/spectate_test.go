@@ -0,0 +1,60 @@
+package spectate
+
+import (
+	"testing"
+	"time"
+
+	"gotetris/internal/game"
+)
+
+// waitFor waits until the feed shows a snapshot that passes ok.
+func waitFor(t *testing.T, f *Feed, what string, ok func(*game.Snapshot) bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if ok(f.Snapshot()) {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("timed out waiting for %s; the feed shows %+v", what, f.Snapshot())
+}
+
+func TestStream(t *testing.T) {
+	srv, err := Listen("127.0.0.1:0", "ann")
+	if err != nil {
+		t.Fatal(err)
+	}
+	snap := &game.Snapshot{State: game.Playing, Mode: "Versus", Played: 1}
+	srv.Show(snap)
+	f := Watch(srv.Addr().String())
+	defer f.Close()
+	waitFor(t, f, "the snapshot", func(v *game.Snapshot) bool { return v.Played == 1 && v.Player == "ann" })
+
+	// Garbage and the match come through in deltas like the rest
+	next := *snap
+	next.Played = 2
+	next.Playfield[0][0] = int(game.Garbage)
+	next.Garbage = &game.GarbageStatus{Sent: 4, Incoming: 3}
+	next.Battle = &game.BattleStatus{Difficulty: "hard", BestOf: 3, Round: 2, Wins: 1, Opponent: &game.Snapshot{Score: 900}}
+	srv.Show(&next)
+	waitFor(t, f, "the delta", func(v *game.Snapshot) bool { return v.Played == 2 })
+	v := f.Snapshot()
+	if v.Playfield[0][0] != int(game.Garbage) {
+		t.Error("the changed cell did not come through")
+	}
+	if v.Garbage == nil || *v.Garbage != *next.Garbage {
+		t.Errorf("garbage: got %+v, want %+v", v.Garbage, next.Garbage)
+	}
+	if b := v.Battle; b == nil || b.Round != 2 || b.Wins != 1 || b.Opponent == nil || b.Opponent.Score != 900 {
+		t.Errorf("battle: got %+v", b)
+	}
+
+	// Closing twice is harmless
+	if err := srv.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := srv.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,159 @@
+package spectate
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"gotetris/internal/game"
+)
+
+// --- Viewer Side --------------------------------------------------------------
+
+// retryDelay is how long a feed waits before it reconnects.
+const retryDelay = 2 * time.Second
+
+// Feed is a game watched over the network, rebuilt from the stream. It is a
+// game.Source, so the game's own primitives draw it.
+type Feed struct {
+	addr    string
+	view    atomic.Pointer[game.Snapshot]
+	updates chan struct{}
+	done    chan struct{}
+
+	mu   sync.Mutex
+	conn net.Conn // Current connection, nil between attempts
+}
+
+// Watch starts watching the game streamed at addr. It keeps trying until
+// the game is up and reconnects if the game goes away, so boards can be set
+// up before the players start.
+func Watch(addr string) *Feed {
+	f := &Feed{
+		addr:    addr,
+		updates: make(chan struct{}, 1),
+		done:    make(chan struct{}),
+	}
+	f.show(&game.Snapshot{State: game.MainMenu, Player: addr})
+	go f.run()
+	return f
+}
+
+// Snapshot returns what to show of the game.
+func (f *Feed) Snapshot() *game.Snapshot {
+	return f.view.Load()
+}
+
+// Updates receives when the snapshot changed since the last receive.
+func (f *Feed) Updates() <-chan struct{} {
+	return f.updates
+}
+
+// Close stops watching.
+func (f *Feed) Close() error {
+	close(f.done)
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.conn != nil {
+		return f.conn.Close()
+	}
+	return nil
+}
+
+// run connects, follows the stream and reconnects until closed.
+func (f *Feed) run() {
+	for {
+		f.follow()
+		select {
+		case <-f.done:
+			return
+		default:
+		}
+
+		// Keep the last board up, saying it is no longer live
+		view := *f.Snapshot()
+		view.Notice = "Offline, retrying"
+		f.show(&view)
+		select {
+		case <-time.After(retryDelay):
+		case <-f.done:
+			return
+		}
+	}
+}
+
+// follow reads one connection's stream to its end.
+func (f *Feed) follow() error {
+	conn, err := net.DialTimeout("tcp", f.addr, retryDelay)
+	if err != nil {
+		return err
+	}
+	f.mu.Lock()
+	select {
+	case <-f.done:
+		f.mu.Unlock()
+		conn.Close()
+		return nil
+	default:
+	}
+	f.conn = conn
+	f.mu.Unlock()
+	defer func() {
+		f.mu.Lock()
+		f.conn = nil
+		f.mu.Unlock()
+		conn.Close()
+	}()
+
+	// The stream starts over on every connection
+	view := game.Snapshot{Player: f.addr}
+	dec := json.NewDecoder(conn)
+	for {
+		var m Message
+		if err := dec.Decode(&m); err != nil {
+			return err
+		}
+
+		switch m.Type {
+		case TypeHello:
+			if m.Version != Version {
+				return fmt.Errorf("stream version %d, want %d", m.Version, Version)
+			}
+			if m.Player != "" {
+				view.Player = m.Player
+			}
+			continue
+		case TypeSnapshot:
+			if m.Board != nil && m.LockedAt != nil {
+				view.Playfield, view.LockedAt = *m.Board, *m.LockedAt
+			}
+		case TypeDelta:
+			for _, c := range m.Cells {
+				if c.X < 0 || c.X >= game.PlayWidth || c.Y < 0 || c.Y >= game.TotalHeight {
+					return fmt.Errorf("cell %d,%d off the board", c.X, c.Y)
+				}
+				view.Playfield[c.X][c.Y], view.LockedAt[c.X][c.Y] = c.Value, c.LockedAt
+			}
+		default:
+			continue
+		}
+		if m.Status != nil {
+			m.Status.apply(&view)
+		}
+
+		shown := view
+		f.show(&shown)
+	}
+}
+
+// show publishes a snapshot and flags the update.
+func (f *Feed) show(s *game.Snapshot) {
+	f.view.Store(s)
+	select {
+	case f.updates <- struct{}{}:
+	default:
+	}
+}
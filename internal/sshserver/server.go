@@ -0,0 +1,182 @@
+// Package sshserver lets people play over SSH with nothing to install. Every
+// session with a terminal gets a tcell screen of its own on the SSH channel,
+// which a game drives like a local terminal.
+package sshserver
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"net"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// Handler runs one session, returning when the player is done.
+type Handler func(s *Session) error
+
+// Server accepts SSH connections and hands each terminal session to the
+// handler. Anyone who can reach it may play: logins are not checked, the
+// user name is only used to tell players apart.
+type Server struct {
+	ln     net.Listener
+	config *ssh.ServerConfig
+	handle Handler
+}
+
+// Listen starts accepting connections on addr with the given host key.
+// Call Serve to let them in.
+func Listen(addr string, hostKey ssh.Signer, handle Handler) (*Server, error) {
+	config := &ssh.ServerConfig{NoClientAuth: true}
+	config.AddHostKey(hostKey)
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	return &Server{ln: ln, config: config, handle: handle}, nil
+}
+
+// Addr is the address players connect to.
+func (s *Server) Addr() net.Addr {
+	return s.ln.Addr()
+}
+
+// Serve handles connections until the server is closed.
+func (s *Server) Serve() error {
+	for {
+		conn, err := s.ln.Accept()
+		if errors.Is(err, net.ErrClosed) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		go s.serveConn(conn)
+	}
+}
+
+// Close stops accepting connections. Sessions already running go on.
+func (s *Server) Close() error {
+	return s.ln.Close()
+}
+
+// serveConn runs the sessions of one connection.
+func (s *Server) serveConn(nc net.Conn) {
+	conn, chans, reqs, err := ssh.NewServerConn(nc, s.config)
+	if err != nil {
+		nc.Close()
+		return
+	}
+	defer conn.Close()
+	go ssh.DiscardRequests(reqs)
+
+	for nch := range chans {
+		if nch.ChannelType() != "session" {
+			nch.Reject(ssh.UnknownChannelType, "only sessions are supported")
+			continue
+		}
+		ch, chReqs, err := nch.Accept()
+		if err != nil {
+			continue
+		}
+		go s.serveSession(conn.User(), ch, chReqs)
+	}
+}
+
+// serveSession waits for a terminal and a shell, then runs the handler.
+func (s *Server) serveSession(user string, ch ssh.Channel, reqs <-chan *ssh.Request) {
+	sess := &Session{User: user, ch: ch}
+	started := false
+	for req := range reqs {
+		ok := false
+		switch req.Type {
+		case "pty-req":
+			var pty ptyRequest
+			if ssh.Unmarshal(req.Payload, &pty) == nil && !started {
+				sess.Term = pty.Term
+				sess.tty = newTTY(ch, int(pty.Columns), int(pty.Rows))
+				ok = true
+			}
+		case "window-change":
+			var win windowChange
+			if ssh.Unmarshal(req.Payload, &win) == nil && sess.tty != nil {
+				sess.tty.resize(int(win.Columns), int(win.Rows))
+				ok = true
+			}
+		case "shell":
+			ok = !started
+			if ok {
+				started = true
+				go s.run(sess)
+			}
+		}
+		if req.WantReply {
+			req.Reply(ok, nil)
+		}
+	}
+}
+
+// run plays a session out and tells the client how it went.
+func (s *Server) run(sess *Session) {
+	defer sess.ch.Close()
+
+	status := uint32(0)
+	if sess.tty == nil {
+		fmt.Fprint(sess.ch, "gotetris needs a terminal, try ssh -t\r\n")
+		status = 1
+	} else if err := s.handle(sess); err != nil && !sess.tty.hungUp() {
+		log.Printf("ssh session of %s: %v", sess.User, err)
+		status = 1
+	}
+	sess.ch.SendRequest("exit-status", false, ssh.Marshal(struct{ Status uint32 }{status}))
+}
+
+// ptyRequest is the payload of pty-req (RFC 4254 6.2).
+type ptyRequest struct {
+	Term          string
+	Columns, Rows uint32
+	Width, Height uint32 // In pixels
+	Modes         string
+}
+
+// windowChange is the payload of window-change (RFC 4254 6.7).
+type windowChange struct {
+	Columns, Rows uint32
+	Width, Height uint32 // In pixels
+}
+
+// LoadHostKey reads the server's private key from path, making a new
+// ed25519 key there the first time.
+func LoadHostKey(path string) (ssh.Signer, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		data, err = newHostKey(path)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return ssh.ParsePrivateKey(data)
+}
+
+// newHostKey writes a new host key to path and returns it in PEM.
+func newHostKey(path string) ([]byte, error) {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	block, err := ssh.MarshalPrivateKey(key, "gotetris host key")
+	if err != nil {
+		return nil, err
+	}
+	data := pem.EncodeToMemory(block)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+	return data, os.WriteFile(path, data, 0o600)
+}
@@ -0,0 +1,166 @@
+package sshserver
+
+import (
+	"io"
+	"sync"
+
+	"github.com/gdamore/tcell/v2"
+	"golang.org/x/crypto/ssh"
+)
+
+// fallbackTerm is assumed when the client's terminal is not known.
+const fallbackTerm = "xterm-256color"
+
+// Session is one player's terminal.
+type Session struct {
+	User string // Name the player logged in with
+	Term string // Their $TERM
+
+	ch  ssh.Channel
+	tty *tty
+}
+
+// Screen returns a tcell screen drawing on the session's terminal.
+func (s *Session) Screen() (tcell.Screen, error) {
+	ti, err := tcell.LookupTerminfo(s.Term)
+	if err != nil {
+		if ti, err = tcell.LookupTerminfo(fallbackTerm); err != nil {
+			return nil, err
+		}
+	}
+	return tcell.NewTerminfoScreenFromTtyTerminfo(s.tty, ti)
+}
+
+// tty is a tcell.Tty on an SSH channel. The client's own terminal is in raw
+// mode already, so there is nothing to set up or restore here.
+type tty struct {
+	ch        ssh.Channel
+	input     chan []byte   // Chunks read from the channel
+	err       chan error    // Why reading stopped
+	done      chan struct{} // Closed by Close, once tcell is done with the tty
+	closeOnce sync.Once
+
+	mu            sync.Mutex
+	stop          chan struct{} // Closed by Drain to wake up Read
+	columns, rows int
+	onResize      func()
+}
+
+// newTTY starts reading the channel.
+func newTTY(ch ssh.Channel, columns, rows int) *tty {
+	t := &tty{
+		ch:      ch,
+		input:   make(chan []byte),
+		err:     make(chan error, 1),
+		done:    make(chan struct{}),
+		stop:    make(chan struct{}),
+		columns: columns,
+		rows:    rows,
+	}
+	go t.pump()
+	return t
+}
+
+// pump feeds what the client types to Read.
+func (t *tty) pump() {
+	for {
+		buf := make([]byte, 128)
+		n, err := t.ch.Read(buf)
+		if n > 0 {
+			select {
+			case t.input <- buf[:n]:
+			case <-t.done:
+				return
+			}
+		}
+		if err != nil {
+			t.err <- err
+			return
+		}
+	}
+}
+
+// hungUp reports whether the client closed the channel.
+func (t *tty) hungUp() bool {
+	select {
+	case err := <-t.err:
+		t.err <- err
+		return true
+	default:
+		return false
+	}
+}
+
+// resize takes the new window size and tells tcell.
+func (t *tty) resize(columns, rows int) {
+	t.mu.Lock()
+	t.columns, t.rows = columns, rows
+	cb := t.onResize
+	t.mu.Unlock()
+	if cb != nil {
+		cb()
+	}
+}
+
+func (t *tty) Read(p []byte) (int, error) {
+	t.mu.Lock()
+	stop := t.stop
+	t.mu.Unlock()
+
+	select {
+	case chunk := <-t.input:
+		// tcell reads 128 bytes at a time, as many as pump does
+		return copy(p, chunk), nil
+	case err := <-t.err:
+		t.err <- err // Every later Read fails the same way
+		return 0, err
+	case <-stop:
+		return 0, io.EOF
+	}
+}
+
+func (t *tty) Write(p []byte) (int, error) { return t.ch.Write(p) }
+
+// Close lets go of the channel; the session closes it.
+func (t *tty) Close() error {
+	t.closeOnce.Do(func() { close(t.done) })
+	return nil
+}
+
+// Start gets Read going again after a Drain.
+func (t *tty) Start() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	select {
+	case <-t.stop:
+		t.stop = make(chan struct{})
+	default:
+	}
+	return nil
+}
+
+// Drain wakes up a Read waiting for input.
+func (t *tty) Drain() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	select {
+	case <-t.stop:
+	default:
+		close(t.stop)
+	}
+	return nil
+}
+
+func (t *tty) Stop() error { return nil }
+
+func (t *tty) NotifyResize(cb func()) {
+	t.mu.Lock()
+	t.onResize = cb
+	t.mu.Unlock()
+}
+
+func (t *tty) WindowSize() (tcell.WindowSize, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return tcell.WindowSize{Width: t.columns, Height: t.rows}, nil
+}
@@ -0,0 +1,220 @@
+package tbp
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"slices"
+	"sync"
+
+	"gotetris/internal/game"
+)
+
+// --- Frontend Side ------------------------------------------------------------
+
+// Client talks to an external bot and implements game.Bot, so the bot can
+// drive the autoplayer or a headless simulation like any built-in one.
+type Client struct {
+	Info Info
+
+	mu  sync.Mutex
+	enc *json.Encoder
+	dec *json.Decoder
+	cmd *exec.Cmd // Nil when the pipes were handed to NewClient
+	in  io.Closer
+
+	// What the bot believes, so we can keep it in sync with small updates
+	running bool
+	field   game.Field     // Board after the last play
+	queue   []game.PieceID // Current piece first, then the known preview
+	hold    game.PieceID
+}
+
+// Start launches a bot process and performs the handshake.
+func Start(name string, args ...string) (*Client, error) {
+	cmd := exec.Command(name, args...)
+	cmd.Stderr = os.Stderr
+	in, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	out, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("start bot: %w", err)
+	}
+
+	c, err := NewClient(out, in)
+	if err != nil {
+		in.Close()
+		cmd.Wait()
+		return nil, err
+	}
+	c.cmd, c.in = cmd, in
+	return c, nil
+}
+
+// NewClient performs the handshake with a bot reachable through r and w.
+func NewClient(r io.Reader, w io.Writer) (*Client, error) {
+	c := &Client{enc: json.NewEncoder(w), dec: json.NewDecoder(r)}
+
+	info, err := c.expect(TypeInfo)
+	if err != nil {
+		return nil, fmt.Errorf("bot handshake: %w", err)
+	}
+	c.Info = Info{Name: info.Name, Version: info.Version, Author: info.Author}
+
+	if err := c.enc.Encode(plainMsg{Type: TypeRules}); err != nil {
+		return nil, err
+	}
+	if _, err := c.expect(TypeReady); err != nil {
+		return nil, fmt.Errorf("bot rejected rules: %w", err)
+	}
+	return c, nil
+}
+
+// expect reads the next message and checks its type.
+func (c *Client) expect(typ string) (Message, error) {
+	var m Message
+	if err := c.dec.Decode(&m); err != nil {
+		return m, err
+	}
+	if m.Type == TypeError {
+		return m, fmt.Errorf("bot error: %s", m.Reason)
+	}
+	if m.Type != typ {
+		return m, fmt.Errorf("expected %s, got %s", typ, m.Type)
+	}
+	return m, nil
+}
+
+// Suggest implements game.Bot. It asks the bot for moves, plays the first one
+// our piece can actually reach and tells the bot about it.
+func (c *Client) Suggest(pos game.Position) (game.Placement, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	p, err := c.suggest(pos)
+	if err != nil {
+		// Start from scratch next time; the game just hard drops this piece
+		c.running = false
+		return game.Placement{}, false
+	}
+	return p, true
+}
+
+func (c *Client) suggest(pos game.Position) (game.Placement, error) {
+	if err := c.sync(pos); err != nil {
+		return game.Placement{}, err
+	}
+
+	if err := c.enc.Encode(plainMsg{Type: TypeSuggest}); err != nil {
+		return game.Placement{}, err
+	}
+	m, err := c.expect(TypeSuggestion)
+	if err != nil {
+		return game.Placement{}, err
+	}
+
+	alt, canHold := pos.Alternative()
+	for _, mv := range m.Moves {
+		p, err := mv.Location.Placement()
+		if err != nil {
+			continue
+		}
+
+		// A move for the other piece means hold first, if we may
+		start, held := pos.Current, false
+		if p.Piece != pos.Current.Piece {
+			if !canHold || p.Piece != alt {
+				continue
+			}
+			start, held = game.Placement{Piece: alt, Position: game.SpawnPoint()}, true
+		}
+		if _, ok := game.PathTo(&pos.Playfield, start, p); !ok {
+			continue
+		}
+
+		if err := c.enc.Encode(playMsg{Type: TypePlay, Move: mv}); err != nil {
+			return game.Placement{}, err
+		}
+		c.field = pos.Playfield
+		game.PlacePiece(&c.field, p)
+		c.queue, c.hold = afterPlay(c.queue, c.hold, held)
+		return p, nil
+	}
+	return game.Placement{}, errors.New("no reachable move suggested")
+}
+
+// sync brings the bot up to date with pos: new_piece messages when the game
+// went as the bot expected, a fresh start otherwise.
+func (c *Client) sync(pos game.Position) error {
+	queue := append([]game.PieceID{pos.Current.Piece}, pos.Queue...)
+
+	if c.running && c.field == pos.Playfield && c.hold == pos.Hold && len(c.queue) <= len(queue) && slices.Equal(c.queue, queue[:len(c.queue)]) {
+		for _, id := range queue[len(c.queue):] {
+			if err := c.enc.Encode(newPieceMsg{Type: TypeNewPiece, Piece: game.PieceLetter(id)}); err != nil {
+				return err
+			}
+		}
+		c.queue = queue
+		return nil
+	}
+
+	if c.running {
+		if err := c.enc.Encode(plainMsg{Type: TypeStop}); err != nil {
+			return err
+		}
+	}
+	var hold *string
+	if pos.Hold != 0 {
+		letter := game.PieceLetter(pos.Hold)
+		hold = &letter
+	}
+	err := c.enc.Encode(startMsg{
+		Type:       TypeStart,
+		Hold:       hold,
+		Queue:      encodeQueue(queue),
+		Combo:      pos.Combo,
+		BackToBack: pos.B2B,
+		Board:      encodeBoard(&pos.Playfield),
+	})
+	if err != nil {
+		return err
+	}
+	c.running, c.queue, c.hold = true, queue, pos.Hold
+	return nil
+}
+
+// afterPlay updates a queue (current piece first) and hold slot for a played
+// move. Holding into an empty slot uses up the next piece as well.
+func afterPlay(queue []game.PieceID, hold game.PieceID, held bool) ([]game.PieceID, game.PieceID) {
+	switch {
+	case !held:
+		return queue[1:], hold
+	case hold == 0:
+		return queue[2:], queue[0]
+	default:
+		return queue[1:], queue[0]
+	}
+}
+
+// Close asks the bot to quit and waits for the process to exit.
+func (c *Client) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.enc.Encode(plainMsg{Type: TypeQuit})
+	if c.in != nil {
+		c.in.Close()
+	}
+	if c.cmd != nil {
+		return c.cmd.Wait()
+	}
+	return nil
+}
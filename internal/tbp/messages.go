@@ -0,0 +1,205 @@
+// Package tbp speaks the Tetris Bot Protocol: newline separated JSON messages
+// between a game (the frontend) and a bot engine over stdin/stdout.
+//
+// Flow: the bot announces itself with info, the frontend sends rules and the
+// bot answers ready. Then the frontend sends start (full game state), suggest
+// (bot answers suggestion), play (the move that was made) and new_piece (as
+// the queue grows), until stop. quit ends the process.
+package tbp
+
+import (
+	"fmt"
+
+	"gotetris/internal/game"
+)
+
+// Message types.
+const (
+	TypeInfo       = "info"
+	TypeRules      = "rules"
+	TypeReady      = "ready"
+	TypeError      = "error"
+	TypeStart      = "start"
+	TypeSuggest    = "suggest"
+	TypeSuggestion = "suggestion"
+	TypePlay       = "play"
+	TypeNewPiece   = "new_piece"
+	TypeStop       = "stop"
+	TypeQuit       = "quit"
+)
+
+// Message is what we decode incoming messages into: the union of every TBP
+// message, where Type says which fields matter. Outgoing messages use the
+// dedicated structs below so required fields are always written.
+type Message struct {
+	Type string `json:"type"`
+
+	// info
+	Name     string   `json:"name"`
+	Version  string   `json:"version"`
+	Author   string   `json:"author"`
+	Features []string `json:"features"`
+
+	// error
+	Reason string `json:"reason"`
+
+	// start
+	Hold       *string     `json:"hold"`
+	Queue      []string    `json:"queue"`
+	Combo      int         `json:"combo"`
+	BackToBack bool        `json:"back_to_back"`
+	Board      [][]*string `json:"board"`
+
+	// suggestion
+	Moves []Move `json:"moves"`
+
+	// play
+	Move *Move `json:"move"`
+
+	// new_piece
+	Piece string `json:"piece"`
+}
+
+type (
+	plainMsg struct {
+		Type string `json:"type"`
+	}
+	infoMsg struct {
+		Type     string   `json:"type"`
+		Name     string   `json:"name"`
+		Version  string   `json:"version"`
+		Author   string   `json:"author"`
+		Features []string `json:"features"`
+	}
+	errorMsg struct {
+		Type   string `json:"type"`
+		Reason string `json:"reason"`
+	}
+	startMsg struct {
+		Type       string      `json:"type"`
+		Hold       *string     `json:"hold"`
+		Queue      []string    `json:"queue"`
+		Combo      int         `json:"combo"`
+		BackToBack bool        `json:"back_to_back"`
+		Board      [][]*string `json:"board"`
+	}
+	suggestionMsg struct {
+		Type  string `json:"type"`
+		Moves []Move `json:"moves"`
+	}
+	playMsg struct {
+		Type string `json:"type"`
+		Move Move   `json:"move"`
+	}
+	newPieceMsg struct {
+		Type  string `json:"type"`
+		Piece string `json:"piece"`
+	}
+)
+
+// Move is a piece placement in guideline coordinates.
+type Move struct {
+	Location Location `json:"location"`
+	Spin     string   `json:"spin"` // none, mini or full
+}
+
+// Location is where a piece ends up: x, y of its rotation center counted from
+// the bottom left, and which way it faces.
+type Location struct {
+	Type        string `json:"type"`
+	Orientation string `json:"orientation"`
+	X           int    `json:"x"`
+	Y           int    `json:"y"`
+}
+
+// Info identifies a bot.
+type Info struct {
+	Name    string
+	Version string
+	Author  string
+}
+
+var orientations = []string{"north", "east", "south", "west"}
+
+// Placement converts the location into one of our placements.
+func (l Location) Placement() (game.Placement, error) {
+	id, ok := game.PieceFromLetter(l.Type)
+	if !ok {
+		return game.Placement{}, fmt.Errorf("unknown piece %q", l.Type)
+	}
+	for o, name := range orientations {
+		if name == l.Orientation {
+			if p, ok := game.FromSRS(id, o, game.Point{X: l.X, Y: l.Y}); ok {
+				return p, nil
+			}
+			break
+		}
+	}
+	return game.Placement{}, fmt.Errorf("invalid location %+v", l)
+}
+
+// LocationOf converts one of our placements into a TBP location.
+func LocationOf(p game.Placement) (Location, error) {
+	o, center, ok := game.ToSRS(p)
+	if !ok {
+		return Location{}, fmt.Errorf("placement %+v has no guideline location", p)
+	}
+	return Location{Type: game.PieceLetter(p.Piece), Orientation: orientations[o], X: center.X, Y: center.Y}, nil
+}
+
+// encodeBoard turns the playfield into TBP rows, bottom row first.
+func encodeBoard(f *game.Field) [][]*string {
+	board := make([][]*string, game.TotalHeight)
+	for y := range board {
+		board[y] = make([]*string, game.PlayWidth)
+		for x := range board[y] {
+			if id := f[x][y]; id != 0 {
+				letter := game.PieceLetter(game.PieceID(id))
+				if letter == "" {
+					letter = "G" // Anything that is not a tetromino
+				}
+				board[y][x] = &letter
+			}
+		}
+	}
+	return board
+}
+
+// decodeBoard turns TBP rows back into a playfield.
+func decodeBoard(board [][]*string) game.Field {
+	var f game.Field
+	for y, row := range board {
+		if y >= game.TotalHeight {
+			break
+		}
+		for x, cell := range row {
+			if x >= game.PlayWidth || cell == nil {
+				continue
+			}
+			id, ok := game.PieceFromLetter(*cell)
+			if !ok {
+				id = game.Garbage
+			}
+			f[x][y] = int(id)
+		}
+	}
+	return f
+}
+
+// encodeQueue turns piece IDs into letters.
+func encodeQueue(ids []game.PieceID) []string {
+	out := make([]string, len(ids))
+	for i, id := range ids {
+		out[i] = game.PieceLetter(id)
+	}
+	return out
+}
+
+// decodePiece parses a single piece letter.
+func decodePiece(letter string) (game.PieceID, error) {
+	id, ok := game.PieceFromLetter(letter)
+	if !ok {
+		return 0, fmt.Errorf("unknown piece %q", letter)
+	}
+	return id, nil
+}
@@ -0,0 +1,119 @@
+package tbp
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"gotetris/internal/game"
+)
+
+// --- Bot Side -----------------------------------------------------------------
+
+// Serve runs b as a TBP bot on r and w (usually stdin and stdout) until the
+// frontend sends quit or closes the stream.
+func Serve(r io.Reader, w io.Writer, info Info, b game.Bot) error {
+	enc, dec := json.NewEncoder(w), json.NewDecoder(r)
+
+	err := enc.Encode(infoMsg{Type: TypeInfo, Name: info.Name, Version: info.Version, Author: info.Author, Features: []string{}})
+	if err != nil {
+		return err
+	}
+
+	var (
+		field   game.Field
+		queue   []game.PieceID
+		hold    game.PieceID
+		running bool
+	)
+	for {
+		var m Message
+		if err := dec.Decode(&m); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+
+		switch m.Type {
+		case TypeRules:
+			err = enc.Encode(plainMsg{Type: TypeReady})
+
+		case TypeStart:
+			field, queue, hold, running = decodeBoard(m.Board), nil, 0, true
+			if m.Hold != nil {
+				if hold, err = decodePiece(*m.Hold); err != nil {
+					return err
+				}
+			}
+			for _, letter := range m.Queue {
+				id, perr := decodePiece(letter)
+				if perr != nil {
+					return perr
+				}
+				queue = append(queue, id)
+			}
+
+		case TypeSuggest:
+			if !running || len(queue) == 0 {
+				err = enc.Encode(suggestionMsg{Type: TypeSuggestion, Moves: []Move{}})
+				break
+			}
+			err = enc.Encode(suggestionMsg{Type: TypeSuggestion, Moves: suggest(b, field, queue, hold)})
+
+		case TypePlay:
+			if m.Move == nil || len(queue) == 0 {
+				return fmt.Errorf("unexpected play message")
+			}
+			p, perr := m.Move.Location.Placement()
+			if perr != nil {
+				return perr
+			}
+			held := p.Piece != queue[0]
+			alt, _ := game.Position{Queue: queue[1:], Hold: hold, CanHold: true}.Alternative()
+			if held && p.Piece != alt {
+				return fmt.Errorf("play message for a piece we do not have")
+			}
+			game.PlacePiece(&field, p)
+			queue, hold = afterPlay(queue, hold, held)
+
+		case TypeNewPiece:
+			id, perr := decodePiece(m.Piece)
+			if perr != nil {
+				return perr
+			}
+			queue = append(queue, id)
+
+		case TypeStop:
+			running = false
+
+		case TypeQuit:
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// suggest asks b for a placement of the current piece (or the one it could
+// hold), starting at the spawn point like the frontend will.
+func suggest(b game.Bot, field game.Field, queue []game.PieceID, hold game.PieceID) []Move {
+	pos := game.Position{
+		Playfield: field,
+		Current:   game.Placement{Piece: queue[0], Position: game.SpawnPoint()},
+		Queue:     queue[1:],
+		Hold:      hold,
+		CanHold:   true,
+	}
+	p, ok := b.Suggest(pos)
+	if !ok {
+		return []Move{}
+	}
+	loc, err := LocationOf(p)
+	if err != nil {
+		return []Move{}
+	}
+	return []Move{{Location: loc, Spin: "none"}}
+}
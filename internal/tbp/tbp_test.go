@@ -0,0 +1,109 @@
+package tbp
+
+import (
+	"io"
+	"testing"
+
+	"gotetris/internal/bot"
+	"gotetris/internal/game"
+	"gotetris/internal/sim"
+)
+
+// served is a Client talking to Serve over in-memory pipes, the way the stub
+// talks to the game over its stdin and stdout.
+type served struct {
+	*Client
+	t      *testing.T
+	toBot  *io.PipeWriter
+	served chan error // What Serve returned
+}
+
+// serve runs b behind Serve and connects a Client to it.
+func serve(t *testing.T, info Info, b game.Bot) *served {
+	t.Helper()
+	botIn, toBot := io.Pipe()
+	fromBot, botOut := io.Pipe()
+	s := &served{t: t, toBot: toBot, served: make(chan error, 1)}
+	go func() {
+		s.served <- Serve(botIn, botOut, info, b)
+		botOut.Close()
+	}()
+
+	c, err := NewClient(fromBot, toBot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Client = c
+	return s
+}
+
+// Close implements sim's bot closing: quit, then check Serve ended cleanly.
+func (s *served) Close() error {
+	err := s.Client.Close()
+	s.toBot.Close()
+	if serr := <-s.served; serr != nil {
+		s.t.Errorf("Serve: %v", serr)
+	}
+	return err
+}
+
+// misses counts the pieces a bot had no placement for.
+type misses struct {
+	game.Bot
+	n int
+}
+
+// Suggest implements game.Bot.
+func (m *misses) Suggest(pos game.Position) (game.Placement, bool) {
+	p, ok := m.Bot.Suggest(pos)
+	if !ok {
+		m.n++
+	}
+	return p, ok
+}
+
+func TestRoundTrip(t *testing.T) {
+	const pieces = 300
+	info := Info{Name: "roundtrip", Version: "1.0", Author: "gotetris"}
+	play := func(newBot func() (game.Bot, error)) sim.Result {
+		cfg := sim.Config{Mode: game.Marathon, MaxPieces: pieces, NewBot: newBot}
+		r, err := sim.Play(cfg, 11)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return r
+	}
+
+	// The built-in bot on its own
+	direct := play(func() (game.Bot, error) { return bot.New(bot.DefaultBot) })
+
+	// The same bot behind the protocol: every move makes it across and back,
+	// so the game goes exactly the same way
+	var remote *misses
+	overTBP := play(func() (game.Bot, error) {
+		b, err := bot.New(bot.DefaultBot)
+		if err != nil {
+			return nil, err
+		}
+		s := serve(t, info, b)
+		if s.Info != info {
+			t.Errorf("handshake: got %+v, want %+v", s.Info, info)
+		}
+		remote = &misses{Bot: s}
+		return struct {
+			*misses
+			io.Closer
+		}{remote, s}, nil
+	})
+
+	if remote.n > 0 {
+		t.Errorf("%d of %d pieces got no move over TBP", remote.n, overTBP.Pieces)
+	}
+	if overTBP.Pieces != pieces || overTBP.Reason != sim.ReasonPieceLimit {
+		t.Fatalf("over TBP: %d pieces, ended by %q", overTBP.Pieces, overTBP.Reason)
+	}
+	direct.Time, overTBP.Time = 0, 0
+	if overTBP != direct {
+		t.Errorf("over TBP: %+v\ndirect:   %+v", overTBP, direct)
+	}
+}
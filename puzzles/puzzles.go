@@ -0,0 +1,10 @@
+// Package puzzles holds the training puzzles that ship with the game. See
+// game.ParsePuzzle for the file format.
+package puzzles
+
+import "embed"
+
+// Files are the built-in puzzle files.
+//
+//go:embed *.txt
+var Files embed.FS